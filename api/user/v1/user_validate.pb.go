@@ -0,0 +1,407 @@
+// Code generated by protoc-gen-go-validate. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-validate v0.1.0
+// - protoc                 v3.19.4
+// source: user.proto
+
+package v1
+
+import (
+	validator "github.com/realHoangHai/awesome/pkg/utils/validator"
+	regexp "regexp"
+	strconv "strconv"
+	utf8 "unicode/utf8"
+)
+
+// Validate checks the constraints of the fields of GetUserReq, returning their violations as validator.FieldViolations.
+func (m *GetUserReq) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	if v := m.GetId(); v == 0 {
+		violations = append(violations, validator.FieldViolation{Field: "id", Description: "is required", Tag: "required"})
+	} else {
+		if v <= 0 {
+			violations = append(violations, validator.FieldViolation{Field: "id", Description: "must be greater than 0", Tag: "gt", Param: "0"})
+		}
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of GetUserReply, returning their violations as validator.FieldViolations.
+func (m *GetUserReply) Validate() error {
+	return nil
+}
+
+// Validate checks the constraints of the fields of GetUserByUsernameReq, returning their violations as validator.FieldViolations.
+func (m *GetUserByUsernameReq) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	if m.GetUsername() == "" {
+		violations = append(violations, validator.FieldViolation{Field: "username", Description: "is required", Tag: "required"})
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of GetUserByUsernameReply, returning their violations as validator.FieldViolations.
+func (m *GetUserByUsernameReply) Validate() error {
+	return nil
+}
+
+var _CreateUserReq_Username_Pattern = regexp.MustCompile("^[a-zA-Z](?:[._-]?[a-zA-Z0-9])*$")
+
+// Validate checks the constraints of the fields of CreateUserReq, returning their violations as validator.FieldViolations.
+func (m *CreateUserReq) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	if v := m.GetUsername(); v == "" {
+		violations = append(violations, validator.FieldViolation{Field: "username", Description: "is required", Tag: "required"})
+	} else {
+		if utf8.RuneCountInString(v) < 3 {
+			violations = append(violations, validator.FieldViolation{Field: "username", Description: "must be at least 3 characters", Tag: "min", Param: "3"})
+		}
+		if utf8.RuneCountInString(v) > 32 {
+			violations = append(violations, validator.FieldViolation{Field: "username", Description: "must be at most 32 characters", Tag: "max", Param: "32"})
+		}
+		if !_CreateUserReq_Username_Pattern.MatchString(v) {
+			violations = append(violations, validator.FieldViolation{Field: "username", Description: "must match the pattern ^[a-zA-Z](?:[._-]?[a-zA-Z0-9])*$", Tag: "pattern", Param: "^[a-zA-Z](?:[._-]?[a-zA-Z0-9])*$"})
+		}
+	}
+	if v := m.GetPassword(); v == "" {
+		violations = append(violations, validator.FieldViolation{Field: "password", Description: "is required", Tag: "required"})
+	} else {
+		if utf8.RuneCountInString(v) < 8 {
+			violations = append(violations, validator.FieldViolation{Field: "password", Description: "must be at least 8 characters", Tag: "min", Param: "8"})
+		}
+		if utf8.RuneCountInString(v) > 64 {
+			violations = append(violations, validator.FieldViolation{Field: "password", Description: "must be at most 64 characters", Tag: "max", Param: "64"})
+		}
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of CreateUserReply, returning their violations as validator.FieldViolations.
+func (m *CreateUserReply) Validate() error {
+	return nil
+}
+
+// Validate checks the constraints of the fields of ListUserReq, returning their violations as validator.FieldViolations.
+func (m *ListUserReq) Validate() error {
+	return nil
+}
+
+// Validate checks the constraints of the fields of ListUserReply, returning their violations as validator.FieldViolations.
+func (m *ListUserReply) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	for i, item := range m.GetResults() {
+		if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			violations = append(violations, validator.Nested("results["+strconv.Itoa(i)+"]", v.Validate())...)
+		}
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of ListUserReply_User, returning their violations as validator.FieldViolations.
+func (m *ListUserReply_User) Validate() error {
+	return nil
+}
+
+// Validate checks the constraints of the fields of VerifyPasswordReq, returning their violations as validator.FieldViolations.
+func (m *VerifyPasswordReq) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	if m.GetUsername() == "" {
+		violations = append(violations, validator.FieldViolation{Field: "username", Description: "is required", Tag: "required"})
+	}
+	if m.GetPassword() == "" {
+		violations = append(violations, validator.FieldViolation{Field: "password", Description: "is required", Tag: "required"})
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of VerifyPasswordReply, returning their violations as validator.FieldViolations.
+func (m *VerifyPasswordReply) Validate() error {
+	return nil
+}
+
+// Validate checks the constraints of the fields of ListAddressReq, returning their violations as validator.FieldViolations.
+func (m *ListAddressReq) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	if v := m.GetUid(); v == 0 {
+		violations = append(violations, validator.FieldViolation{Field: "uid", Description: "is required", Tag: "required"})
+	} else {
+		if v <= 0 {
+			violations = append(violations, validator.FieldViolation{Field: "uid", Description: "must be greater than 0", Tag: "gt", Param: "0"})
+		}
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of ListAddressReply, returning their violations as validator.FieldViolations.
+func (m *ListAddressReply) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	for i, item := range m.GetResults() {
+		if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			violations = append(violations, validator.Nested("results["+strconv.Itoa(i)+"]", v.Validate())...)
+		}
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of ListAddressReply_Address, returning their violations as validator.FieldViolations.
+func (m *ListAddressReply_Address) Validate() error {
+	return nil
+}
+
+var _CreateAddressReq_Mobile_Pattern = regexp.MustCompile("^\\+?[0-9]{7,15}$")
+
+// Validate checks the constraints of the fields of CreateAddressReq, returning their violations as validator.FieldViolations.
+func (m *CreateAddressReq) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	if v := m.GetUid(); v == 0 {
+		violations = append(violations, validator.FieldViolation{Field: "uid", Description: "is required", Tag: "required"})
+	} else {
+		if v <= 0 {
+			violations = append(violations, validator.FieldViolation{Field: "uid", Description: "must be greater than 0", Tag: "gt", Param: "0"})
+		}
+	}
+	if v := m.GetName(); v == "" {
+		violations = append(violations, validator.FieldViolation{Field: "name", Description: "is required", Tag: "required"})
+	} else {
+		if utf8.RuneCountInString(v) > 64 {
+			violations = append(violations, validator.FieldViolation{Field: "name", Description: "must be at most 64 characters", Tag: "max", Param: "64"})
+		}
+	}
+	if v := m.GetMobile(); v == "" {
+		violations = append(violations, validator.FieldViolation{Field: "mobile", Description: "is required", Tag: "required"})
+	} else {
+		if !_CreateAddressReq_Mobile_Pattern.MatchString(v) {
+			violations = append(violations, validator.FieldViolation{Field: "mobile", Description: "must match the pattern ^\\+?[0-9]{7,15}$", Tag: "pattern", Param: "^\\+?[0-9]{7,15}$"})
+		}
+	}
+	if v := m.GetAddress(); v == "" {
+		violations = append(violations, validator.FieldViolation{Field: "address", Description: "is required", Tag: "required"})
+	} else {
+		if utf8.RuneCountInString(v) > 255 {
+			violations = append(violations, validator.FieldViolation{Field: "address", Description: "must be at most 255 characters", Tag: "max", Param: "255"})
+		}
+	}
+	if v := m.GetPostCode(); !(v == "") {
+		if utf8.RuneCountInString(v) > 16 {
+			violations = append(violations, validator.FieldViolation{Field: "postCode", Description: "must be at most 16 characters", Tag: "max", Param: "16"})
+		}
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of CreateAddressReply, returning their violations as validator.FieldViolations.
+func (m *CreateAddressReply) Validate() error {
+	return nil
+}
+
+// Validate checks the constraints of the fields of GetAddressReq, returning their violations as validator.FieldViolations.
+func (m *GetAddressReq) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	if v := m.GetId(); v == 0 {
+		violations = append(violations, validator.FieldViolation{Field: "id", Description: "is required", Tag: "required"})
+	} else {
+		if v <= 0 {
+			violations = append(violations, validator.FieldViolation{Field: "id", Description: "must be greater than 0", Tag: "gt", Param: "0"})
+		}
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of GetAddressReply, returning their violations as validator.FieldViolations.
+func (m *GetAddressReply) Validate() error {
+	return nil
+}
+
+// Validate checks the constraints of the fields of ListCardReq, returning their violations as validator.FieldViolations.
+func (m *ListCardReq) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	if v := m.GetUid(); v == 0 {
+		violations = append(violations, validator.FieldViolation{Field: "uid", Description: "is required", Tag: "required"})
+	} else {
+		if v <= 0 {
+			violations = append(violations, validator.FieldViolation{Field: "uid", Description: "must be greater than 0", Tag: "gt", Param: "0"})
+		}
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of ListCardReply, returning their violations as validator.FieldViolations.
+func (m *ListCardReply) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	for i, item := range m.GetResults() {
+		if v, ok := interface{}(item).(interface{ Validate() error }); ok {
+			violations = append(violations, validator.Nested("results["+strconv.Itoa(i)+"]", v.Validate())...)
+		}
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of ListCardReply_Card, returning their violations as validator.FieldViolations.
+func (m *ListCardReply_Card) Validate() error {
+	return nil
+}
+
+var _CreateCardReq_CardNo_Pattern = regexp.MustCompile("^[0-9]{12,19}$")
+
+var _CreateCardReq_Ccv_Pattern = regexp.MustCompile("^[0-9]{3,4}$")
+
+var _CreateCardReq_Expires_Pattern = regexp.MustCompile("^(0[1-9]|1[0-2])/[0-9]{2}$")
+
+// Validate checks the constraints of the fields of CreateCardReq, returning their violations as validator.FieldViolations.
+func (m *CreateCardReq) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	if v := m.GetUid(); v == 0 {
+		violations = append(violations, validator.FieldViolation{Field: "uid", Description: "is required", Tag: "required"})
+	} else {
+		if v <= 0 {
+			violations = append(violations, validator.FieldViolation{Field: "uid", Description: "must be greater than 0", Tag: "gt", Param: "0"})
+		}
+	}
+	if v := m.GetCardNo(); v == "" {
+		violations = append(violations, validator.FieldViolation{Field: "cardNo", Description: "is required", Tag: "required"})
+	} else {
+		if !_CreateCardReq_CardNo_Pattern.MatchString(v) {
+			violations = append(violations, validator.FieldViolation{Field: "cardNo", Description: "must match the pattern ^[0-9]{12,19}$", Tag: "pattern", Param: "^[0-9]{12,19}$"})
+		}
+	}
+	if v := m.GetCcv(); v == "" {
+		violations = append(violations, validator.FieldViolation{Field: "ccv", Description: "is required", Tag: "required"})
+	} else {
+		if !_CreateCardReq_Ccv_Pattern.MatchString(v) {
+			violations = append(violations, validator.FieldViolation{Field: "ccv", Description: "must match the pattern ^[0-9]{3,4}$", Tag: "pattern", Param: "^[0-9]{3,4}$"})
+		}
+	}
+	if v := m.GetExpires(); v == "" {
+		violations = append(violations, validator.FieldViolation{Field: "expires", Description: "is required", Tag: "required"})
+	} else {
+		if !_CreateCardReq_Expires_Pattern.MatchString(v) {
+			violations = append(violations, validator.FieldViolation{Field: "expires", Description: "must match the pattern ^(0[1-9]|1[0-2])/[0-9]{2}$", Tag: "pattern", Param: "^(0[1-9]|1[0-2])/[0-9]{2}$"})
+		}
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of CreateCardReply, returning their violations as validator.FieldViolations.
+func (m *CreateCardReply) Validate() error {
+	return nil
+}
+
+// Validate checks the constraints of the fields of GetCardReq, returning their violations as validator.FieldViolations.
+func (m *GetCardReq) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	if v := m.GetId(); v == 0 {
+		violations = append(violations, validator.FieldViolation{Field: "id", Description: "is required", Tag: "required"})
+	} else {
+		if v <= 0 {
+			violations = append(violations, validator.FieldViolation{Field: "id", Description: "must be greater than 0", Tag: "gt", Param: "0"})
+		}
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of GetCardReply, returning their violations as validator.FieldViolations.
+func (m *GetCardReply) Validate() error {
+	return nil
+}
+
+// Validate checks the constraints of the fields of DeleteCardReq, returning their violations as validator.FieldViolations.
+func (m *DeleteCardReq) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	if v := m.GetUid(); v == 0 {
+		violations = append(violations, validator.FieldViolation{Field: "uid", Description: "is required", Tag: "required"})
+	} else {
+		if v <= 0 {
+			violations = append(violations, validator.FieldViolation{Field: "uid", Description: "must be greater than 0", Tag: "gt", Param: "0"})
+		}
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of DeleteCardReply, returning their violations as validator.FieldViolations.
+func (m *DeleteCardReply) Validate() error {
+	return nil
+}
+
+var _SaveUserReq_Username_Pattern = regexp.MustCompile("^[a-zA-Z](?:[._-]?[a-zA-Z0-9])*$")
+
+// Validate checks the constraints of the fields of SaveUserReq, returning their violations as validator.FieldViolations.
+func (m *SaveUserReq) Validate() error {
+	if m == nil {
+		return nil
+	}
+	var violations validator.FieldViolations
+	if v := m.GetId(); v == 0 {
+		violations = append(violations, validator.FieldViolation{Field: "id", Description: "is required", Tag: "required"})
+	} else {
+		if v <= 0 {
+			violations = append(violations, validator.FieldViolation{Field: "id", Description: "must be greater than 0", Tag: "gt", Param: "0"})
+		}
+	}
+	if v := m.GetUsername(); !(v == "") {
+		if utf8.RuneCountInString(v) < 3 {
+			violations = append(violations, validator.FieldViolation{Field: "username", Description: "must be at least 3 characters", Tag: "min", Param: "3"})
+		}
+		if utf8.RuneCountInString(v) > 32 {
+			violations = append(violations, validator.FieldViolation{Field: "username", Description: "must be at most 32 characters", Tag: "max", Param: "32"})
+		}
+		if !_SaveUserReq_Username_Pattern.MatchString(v) {
+			violations = append(violations, validator.FieldViolation{Field: "username", Description: "must match the pattern ^[a-zA-Z](?:[._-]?[a-zA-Z0-9])*$", Tag: "pattern", Param: "^[a-zA-Z](?:[._-]?[a-zA-Z0-9])*$"})
+		}
+	}
+	if v := m.GetPassword(); !(v == "") {
+		if utf8.RuneCountInString(v) < 8 {
+			violations = append(violations, validator.FieldViolation{Field: "password", Description: "must be at least 8 characters", Tag: "min", Param: "8"})
+		}
+		if utf8.RuneCountInString(v) > 64 {
+			violations = append(violations, validator.FieldViolation{Field: "password", Description: "must be at most 64 characters", Tag: "max", Param: "64"})
+		}
+	}
+	return violations.Err()
+}
+
+// Validate checks the constraints of the fields of SaveUserReply, returning their violations as validator.FieldViolations.
+func (m *SaveUserReply) Validate() error {
+	return nil
+}
@@ -13,15 +13,15 @@ func main() {
 	}
 
 	var services []server.Service
-	userService, closedb, err := wireApp(&cfg)
-	defer closedb()
+	userService, cleanup, err := wireApp(&cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	services = append(services, userService)
 
-	s := server.New(server.FromEnv(&cfg))
+	// release storage connections after the server is gracefully stopped.
+	s := server.New(server.FromEnv(&cfg), server.CleanupHooks(cleanup))
 	if err := s.Run(services...); err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"github.com/realHoangHai/awesome/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"strings"
+)
+
+const (
+	errorsPackage = protogen.GoImportPath("github.com/realHoangHai/awesome/pkg/errors")
+	codesPackage  = protogen.GoImportPath("google.golang.org/grpc/codes")
+	fmtPackage    = protogen.GoImportPath("fmt")
+)
+
+// generateFile generates a _errors.pb.go file containing the constructors and the checkers
+// of the reasons of the annotated enums, nothing if there is none.
+func generateFile(gen *protogen.Plugin, file *protogen.File) error {
+	var enums []*protogen.Enum
+	for _, enum := range file.Enums {
+		if isErrorEnum(enum) {
+			enums = append(enums, enum)
+		}
+	}
+	if len(enums) == 0 {
+		return nil
+	}
+	g := gen.NewGeneratedFile(file.GeneratedFilenamePrefix+"_errors.pb.go", file.GoImportPath)
+	g.P("// Code generated by protoc-gen-go-errors. DO NOT EDIT.")
+	g.P("// versions:")
+	g.P("// - protoc-gen-go-errors v", version)
+	g.P("// - protoc               ", protocVersion(gen))
+	g.P("// source: ", file.Desc.Path())
+	g.P()
+	g.P("package ", file.GoPackageName)
+	g.P()
+	for _, enum := range enums {
+		if err := generateEnum(g, file, enum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func generateEnum(g *protogen.GeneratedFile, file *protogen.File, enum *protogen.Enum) error {
+	defaultCode := codes.Unknown
+	if proto.HasExtension(enum.Desc.Options(), errors.E_DefaultCode) {
+		c, err := grpcCode(proto.GetExtension(enum.Desc.Options(), errors.E_DefaultCode).(int32))
+		if err != nil {
+			return fmt.Errorf("%s: %w", enum.Desc.FullName(), err)
+		}
+		defaultCode = c
+	}
+	domain := string(file.Desc.Package())
+	for _, v := range enum.Values {
+		code := defaultCode
+		if proto.HasExtension(v.Desc.Options(), errors.E_Code) {
+			c, err := grpcCode(proto.GetExtension(v.Desc.Options(), errors.E_Code).(int32))
+			if err != nil {
+				return fmt.Errorf("%s: %w", v.Desc.FullName(), err)
+			}
+			code = c
+		}
+		name := camelCase(string(v.Desc.Name()))
+		comment := strings.TrimSpace(string(v.Comments.Leading))
+
+		g.P("// Is", name, " reports whether the error is of the reason ", v.GoIdent, ".")
+		if comment != "" {
+			g.P("//")
+			g.P(strings.TrimSuffix(v.Comments.Leading.String(), "\n"))
+		}
+		g.P("func Is", name, "(err error) bool {")
+		g.P("e, ok := ", errorsPackage.Ident("FromError"), "(err)")
+		g.P("return ok && e.Reason() == ", v.GoIdent, ".String() && e.Domain() == ", fmt.Sprintf("%q", domain))
+		g.P("}")
+		g.P()
+		g.P("// Error", name, " returns a new error of the reason ", v.GoIdent, " with the code codes.", code, ".")
+		if comment != "" {
+			g.P("//")
+			g.P(strings.TrimSuffix(v.Comments.Leading.String(), "\n"))
+		}
+		g.P("func Error", name, "(format string, args ...interface{}) *", errorsPackage.Ident("Error"), " {")
+		g.P("return ", errorsPackage.Ident("New"), "(", codesPackage.Ident(code.String()), ", ", fmt.Sprintf("%q", domain), ", ",
+			v.GoIdent, ".String(), ", fmtPackage.Ident("Sprintf"), "(format, args...))")
+		g.P("}")
+		g.P()
+	}
+	return nil
+}
+
+// isErrorEnum reports whether the enum or one of its values is annotated.
+func isErrorEnum(enum *protogen.Enum) bool {
+	if proto.HasExtension(enum.Desc.Options(), errors.E_DefaultCode) {
+		return true
+	}
+	for _, v := range enum.Values {
+		if proto.HasExtension(v.Desc.Options(), errors.E_Code) {
+			return true
+		}
+	}
+	return false
+}
+
+// grpcCode returns the gRPC code of the annotation, OK is not an error.
+func grpcCode(v int32) (codes.Code, error) {
+	if v <= int32(codes.OK) || v > int32(codes.Unauthenticated) {
+		return 0, fmt.Errorf("invalid gRPC code %d", v)
+	}
+	return codes.Code(v), nil
+}
+
+// camelCase returns the camel case of an enum value, i.e... UserNotFound for USER_NOT_FOUND.
+func camelCase(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(strings.ToLower(s), "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	return b.String()
+}
+
+func protocVersion(gen *protogen.Plugin) string {
+	v := gen.Request.GetCompilerVersion()
+	if v == nil {
+		return "(unknown)"
+	}
+	var suffix string
+	if s := v.GetSuffix(); s != "" {
+		suffix = "-" + s
+	}
+	return fmt.Sprintf("v%d.%d.%d%s", v.GetMajor(), v.GetMinor(), v.GetPatch(), suffix)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	v1 "github.com/realHoangHai/awesome/api/user/v1"
+	"github.com/realHoangHai/awesome/pkg/errors"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFile(t *testing.T) {
+	gen, err := protogen.Options{}.New(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate:  []string{v1.File_error_reason_proto.Path()},
+		Parameter:       proto.String("paths=source_relative"),
+		CompilerVersion: &pluginpb.Version{Major: proto.Int32(3), Minor: proto.Int32(19), Patch: proto.Int32(4)},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(errors.File_errors_errors_proto),
+			protodesc.ToFileDescriptorProto(v1.File_error_reason_proto),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range gen.Files {
+		if f.Generate {
+			if err := generateFile(gen, f); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	resp := gen.Response()
+	if resp.Error != nil || len(resp.File) != 1 {
+		t.Fatalf("got response=%v, want the errors file", resp)
+	}
+	want, err := os.ReadFile("../../api/user/v1/error_reason_errors.pb.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the comments are not part of the compiled descriptor.
+	if got := resp.File[0].GetContent(); !sameCode(got, string(want)) {
+		t.Errorf("got generated file:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// sameCode reports whether the given files are the same, comments aside.
+func sameCode(a, b string) bool {
+	code := func(s string) string {
+		var lines []string
+		for _, l := range strings.Split(s, "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(l), "//") {
+				lines = append(lines, l)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	return code(a) == code(b)
+}
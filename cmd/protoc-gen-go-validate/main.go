@@ -0,0 +1,51 @@
+// protoc-gen-go-validate is a plugin for the Google protocol buffer compiler to generate the Validate methods
+// of the messages checking the constraints of their fields annotated with validator.rules,
+// see third_party/validator/validator.proto. Install it by building this program and making it accessible within
+// your PATH with the name:
+//
+//	protoc-gen-go-validate
+//
+// The 'go-validate' suffix becomes part of the argument for the protocol compiler,
+// such that it can be invoked as:
+//
+//	protoc --go-validate_out=. path/to/file.proto
+//
+// This generates the Validate methods of the messages defined by file.proto.
+// With that input, the output will be written to:
+//
+//	path/to/file_validate.pb.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+const version = "0.1.0"
+
+func main() {
+	showVersion := flag.Bool("version", false, "print the version and exit")
+	flag.Parse()
+	if *showVersion {
+		fmt.Printf("protoc-gen-go-validate %v\n", version)
+		return
+	}
+
+	var flags flag.FlagSet
+	protogen.Options{
+		ParamFunc: flags.Set,
+	}.Run(func(gen *protogen.Plugin) error {
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+		for _, f := range gen.Files {
+			if !f.Generate {
+				continue
+			}
+			if err := generateFile(gen, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"fmt"
+	"github.com/realHoangHai/awesome/pkg/utils/validator"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	validatorPackage = protogen.GoImportPath("github.com/realHoangHai/awesome/pkg/utils/validator")
+	fmtPackage       = protogen.GoImportPath("fmt")
+	regexpPackage    = protogen.GoImportPath("regexp")
+	strconvPackage   = protogen.GoImportPath("strconv")
+	utf8Package      = protogen.GoImportPath("unicode/utf8")
+)
+
+// generateFile generates a _validate.pb.go file containing the Validate methods of the messages of a file
+// having annotated fields, nothing if there is none.
+func generateFile(gen *protogen.Plugin, file *protogen.File) error {
+	messages := allMessages(file.Messages)
+	if !hasRules(messages) {
+		return nil
+	}
+	g := gen.NewGeneratedFile(file.GeneratedFilenamePrefix+"_validate.pb.go", file.GoImportPath)
+	g.P("// Code generated by protoc-gen-go-validate. DO NOT EDIT.")
+	g.P("// versions:")
+	g.P("// - protoc-gen-go-validate v", version)
+	g.P("// - protoc                 ", protocVersion(gen))
+	g.P("// source: ", file.Desc.Path())
+	g.P()
+	g.P("package ", file.GoPackageName)
+	g.P()
+	for _, m := range messages {
+		if err := generateMessage(g, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func generateMessage(g *protogen.GeneratedFile, m *protogen.Message) error {
+	// the patterns are compiled once.
+	for _, f := range m.Fields {
+		if p := rules(f).GetString_().GetPattern(); p != "" {
+			if _, err := regexp.Compile(p); err != nil {
+				return fmt.Errorf("%s: invalid pattern: %w", f.Desc.FullName(), err)
+			}
+			g.P("var ", patternVar(m, f), " = ", regexpPackage.Ident("MustCompile"), "(", strconv.Quote(p), ")")
+			g.P()
+		}
+	}
+	g.P("// Validate checks the constraints of the fields of ", m.GoIdent, ", returning their violations as validator.FieldViolations.")
+	g.P("func (m *", m.GoIdent, ") Validate() error {")
+	if !hasChecks(m) {
+		g.P("return nil")
+		g.P("}")
+		g.P()
+		return nil
+	}
+	g.P("if m == nil {")
+	g.P("return nil")
+	g.P("}")
+	g.P("var violations ", validatorPackage.Ident("FieldViolations"))
+	for _, f := range m.Fields {
+		if err := generateField(g, m, f); err != nil {
+			return err
+		}
+	}
+	g.P("return violations.Err()")
+	g.P("}")
+	g.P()
+	return nil
+}
+
+// generateField generates the checks of a field: required on the zero value, the rules of its type
+// on the values set only and the validation of the messages.
+func generateField(g *protogen.GeneratedFile, m *protogen.Message, f *protogen.Field) error {
+	r := rules(f)
+	// the violations are named after the json names, as the fields of the gateway requests.
+	name := f.Desc.JSONName()
+	checks, err := valueChecks(m, f, r)
+	if err != nil {
+		return err
+	}
+	getter := "m.Get" + f.GoName + "()"
+	switch {
+	case r.GetRequired() && len(checks) > 0:
+		g.P("if v := ", getter, "; ", isZero(f, "v"), " {")
+		g.P(violation(g, name, required))
+		g.P("} else {")
+		printChecks(g, name, checks)
+		g.P("}")
+	case r.GetRequired():
+		g.P("if ", isZero(f, getter), " {")
+		g.P(violation(g, name, required))
+		g.P("}")
+	case len(checks) > 0:
+		g.P("if v := ", getter, "; !(", isZero(f, "v"), ") {")
+		printChecks(g, name, checks)
+		g.P("}")
+	}
+	if f.Message == nil || f.Desc.IsMap() && f.Desc.MapValue().Message() == nil {
+		return nil
+	}
+	validatable := "interface{ Validate() error }"
+	switch {
+	case f.Desc.IsList():
+		g.P("for i, item := range ", getter, " {")
+		g.P("if v, ok := interface{}(item).(", validatable, "); ok {")
+		g.P("violations = append(violations, ", validatorPackage.Ident("Nested"), "(", strconv.Quote(name+"["), "+",
+			strconvPackage.Ident("Itoa"), "(i)+\"]\", v.Validate())...)")
+		g.P("}")
+		g.P("}")
+	case f.Desc.IsMap():
+		g.P("for k, item := range ", getter, " {")
+		g.P("if v, ok := interface{}(item).(", validatable, "); ok {")
+		g.P("violations = append(violations, ", validatorPackage.Ident("Nested"), "(", fmtPackage.Ident("Sprintf"), "(",
+			strconv.Quote(name+"[%v]"), ", k), v.Validate())...)")
+		g.P("}")
+		g.P("}")
+	default:
+		g.P("if v, ok := interface{}(", getter, ").(", validatable, "); ok {")
+		g.P("violations = append(violations, ", validatorPackage.Ident("Nested"), "(", strconv.Quote(name), ", v.Validate())...)")
+		g.P("}")
+	}
+	return nil
+}
+
+type check struct {
+	// cond is the condition of the violation on the value v.
+	cond        []interface{}
+	description string
+	// tag and param name the constraint as the tags of the validator, i.e... min and 3.
+	tag   string
+	param string
+}
+
+// required is the check of the required fields, its condition is the zero value of the field.
+var required = check{description: "is required", tag: "required"}
+
+// valueChecks returns the checks of the rules of the type of the field, an error if they do not apply to it.
+func valueChecks(m *protogen.Message, f *protogen.Field, r *validator.FieldRules) ([]check, error) {
+	kind := f.Desc.Kind()
+	invalid := func(rules string) error {
+		return fmt.Errorf("%s: %s rules on a field of type %s", f.Desc.FullName(), rules, fieldType(f))
+	}
+	var checks []check
+	switch {
+	case r.GetString_() != nil:
+		s := r.GetString_()
+		if kind != protoreflect.StringKind || f.Desc.IsList() || f.Desc.IsMap() {
+			return nil, invalid("string")
+		}
+		count := utf8Package.Ident("RuneCountInString")
+		if s.Len != nil {
+			checks = append(checks, check{cond: []interface{}{count, "(v) != ", s.GetLen()},
+				description: fmt.Sprintf("must be %d characters", s.GetLen()), tag: "len", param: fmt.Sprint(s.GetLen())})
+		}
+		if s.MinLen != nil {
+			checks = append(checks, check{cond: []interface{}{count, "(v) < ", s.GetMinLen()},
+				description: fmt.Sprintf("must be at least %d characters", s.GetMinLen()), tag: "min", param: fmt.Sprint(s.GetMinLen())})
+		}
+		if s.MaxLen != nil {
+			checks = append(checks, check{cond: []interface{}{count, "(v) > ", s.GetMaxLen()},
+				description: fmt.Sprintf("must be at most %d characters", s.GetMaxLen()), tag: "max", param: fmt.Sprint(s.GetMaxLen())})
+		}
+		if s.Pattern != nil {
+			checks = append(checks, check{cond: []interface{}{"!", patternVar(m, f), ".MatchString(v)"},
+				description: "must match the pattern " + s.GetPattern(), tag: "pattern", param: s.GetPattern()})
+		}
+		if s.GetEmail() {
+			checks = append(checks, check{cond: []interface{}{"!", validatorPackage.Ident("IsEmail"), "(v)"},
+				description: "must be a valid email address", tag: "email"})
+		}
+		if len(s.GetIn()) > 0 {
+			quoted := make([]string, 0, len(s.GetIn()))
+			for _, v := range s.GetIn() {
+				quoted = append(quoted, strconv.Quote(v))
+			}
+			checks = append(checks, check{cond: notIn(quoted), description: "must be one of [" + strings.Join(s.GetIn(), ", ") + "]",
+				tag: "oneof", param: strings.Join(s.GetIn(), " ")})
+		}
+	case r.GetInt() != nil:
+		n := r.GetInt()
+		if !isInt(kind) || f.Desc.IsList() || f.Desc.IsMap() {
+			return nil, invalid("int")
+		}
+		bounds := append([]int64{n.GetGt(), n.GetGte(), n.GetLt(), n.GetLte()}, n.GetIn()...)
+		for _, b := range bounds {
+			if b < 0 && isUnsigned(kind) {
+				return nil, fmt.Errorf("%s: negative bound %d of an unsigned field", f.Desc.FullName(), b)
+			}
+		}
+		format := func(v *int64) string {
+			if v == nil {
+				return ""
+			}
+			return strconv.FormatInt(*v, 10)
+		}
+		checks = append(checks, rangeChecks(format(n.Gt), format(n.Gte), format(n.Lt), format(n.Lte))...)
+		if len(n.GetIn()) > 0 {
+			values := make([]string, 0, len(n.GetIn()))
+			for _, v := range n.GetIn() {
+				values = append(values, strconv.FormatInt(v, 10))
+			}
+			checks = append(checks, check{cond: notIn(values), description: "must be one of [" + strings.Join(values, ", ") + "]",
+				tag: "oneof", param: strings.Join(values, " ")})
+		}
+	case r.GetDouble() != nil:
+		d := r.GetDouble()
+		if kind != protoreflect.FloatKind && kind != protoreflect.DoubleKind || f.Desc.IsList() || f.Desc.IsMap() {
+			return nil, invalid("double")
+		}
+		format := func(v *float64) string {
+			if v == nil {
+				return ""
+			}
+			return strconv.FormatFloat(*v, 'g', -1, 64)
+		}
+		checks = append(checks, rangeChecks(format(d.Gt), format(d.Gte), format(d.Lt), format(d.Lte))...)
+	case r.GetRepeated() != nil:
+		rep := r.GetRepeated()
+		if !f.Desc.IsList() && !f.Desc.IsMap() {
+			return nil, invalid("repeated")
+		}
+		if rep.MinItems != nil {
+			checks = append(checks, check{cond: []interface{}{"len(v) < ", rep.GetMinItems()},
+				description: fmt.Sprintf("must have at least %d items", rep.GetMinItems()), tag: "min", param: fmt.Sprint(rep.GetMinItems())})
+		}
+		if rep.MaxItems != nil {
+			checks = append(checks, check{cond: []interface{}{"len(v) > ", rep.GetMaxItems()},
+				description: fmt.Sprintf("must have at most %d items", rep.GetMaxItems()), tag: "max", param: fmt.Sprint(rep.GetMaxItems())})
+		}
+	}
+	return checks, nil
+}
+
+// rangeChecks returns the checks of the bounds of a number formatted as Go constants, empty if not set.
+func rangeChecks(gt, gte, lt, lte string) []check {
+	var checks []check
+	if gt != "" {
+		checks = append(checks, check{cond: []interface{}{"v <= ", gt}, description: "must be greater than " + gt, tag: "gt", param: gt})
+	}
+	if gte != "" {
+		checks = append(checks, check{cond: []interface{}{"v < ", gte}, description: "must be greater than or equal to " + gte, tag: "gte", param: gte})
+	}
+	if lt != "" {
+		checks = append(checks, check{cond: []interface{}{"v >= ", lt}, description: "must be less than " + lt, tag: "lt", param: lt})
+	}
+	if lte != "" {
+		checks = append(checks, check{cond: []interface{}{"v > ", lte}, description: "must be less than or equal to " + lte, tag: "lte", param: lte})
+	}
+	return checks
+}
+
+// notIn returns the condition of a value not in the given ones.
+func notIn(values []string) []interface{} {
+	cond := []interface{}{"!("}
+	for i, v := range values {
+		if i > 0 {
+			cond = append(cond, " || ")
+		}
+		cond = append(cond, "v == ", v)
+	}
+	return append(cond, ")")
+}
+
+func printChecks(g *protogen.GeneratedFile, field string, checks []check) {
+	for _, c := range checks {
+		g.P(append(append([]interface{}{"if "}, c.cond...), " {")...)
+		g.P(violation(g, field, c))
+		g.P("}")
+	}
+}
+
+// violation returns the statement appending a violation of the check of the field.
+func violation(g *protogen.GeneratedFile, field string, c check) string {
+	fields := "Field: " + strconv.Quote(field) + ", Description: " + strconv.Quote(c.description) + ", Tag: " + strconv.Quote(c.tag)
+	if c.param != "" {
+		fields += ", Param: " + strconv.Quote(c.param)
+	}
+	return "violations = append(violations, " + g.QualifiedGoIdent(validatorPackage.Ident("FieldViolation")) + "{" + fields + "})"
+}
+
+// isZero returns the condition of the zero value of the field of the given expression.
+func isZero(f *protogen.Field, v string) string {
+	switch {
+	case f.Desc.IsList() || f.Desc.IsMap():
+		return "len(" + v + ") == 0"
+	case f.Desc.Kind() == protoreflect.MessageKind || f.Desc.Kind() == protoreflect.GroupKind:
+		return v + " == nil"
+	case f.Desc.Kind() == protoreflect.StringKind:
+		return v + ` == ""`
+	case f.Desc.Kind() == protoreflect.BytesKind:
+		return "len(" + v + ") == 0"
+	case f.Desc.Kind() == protoreflect.BoolKind:
+		return "!" + v
+	}
+	return v + " == 0"
+}
+
+// rules returns the rules of the field, empty if it is not annotated.
+func rules(f *protogen.Field) *validator.FieldRules {
+	if !proto.HasExtension(f.Desc.Options(), validator.E_Rules) {
+		return &validator.FieldRules{}
+	}
+	return proto.GetExtension(f.Desc.Options(), validator.E_Rules).(*validator.FieldRules)
+}
+
+// hasRules reports whether a field of the messages is annotated.
+func hasRules(messages []*protogen.Message) bool {
+	for _, m := range messages {
+		for _, f := range m.Fields {
+			if proto.HasExtension(f.Desc.Options(), validator.E_Rules) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasChecks reports whether a field of the message is annotated or a message to validate.
+func hasChecks(m *protogen.Message) bool {
+	for _, f := range m.Fields {
+		if proto.HasExtension(f.Desc.Options(), validator.E_Rules) || f.Message != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// allMessages returns the messages and their nested messages, the map entries excluded.
+func allMessages(messages []*protogen.Message) []*protogen.Message {
+	var all []*protogen.Message
+	for _, m := range messages {
+		if m.Desc.IsMapEntry() {
+			continue
+		}
+		all = append(all, m)
+		all = append(all, allMessages(m.Messages)...)
+	}
+	return all
+}
+
+// patternVar returns the name of the variable of the compiled pattern of the field.
+func patternVar(m *protogen.Message, f *protogen.Field) string {
+	return "_" + m.GoIdent.GoName + "_" + f.GoName + "_Pattern"
+}
+
+func isInt(k protoreflect.Kind) bool {
+	switch k {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return true
+	}
+	return isUnsigned(k)
+}
+
+func isUnsigned(k protoreflect.Kind) bool {
+	switch k {
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return true
+	}
+	return false
+}
+
+// fieldType returns the type of the field for the errors, i.e... repeated string.
+func fieldType(f *protogen.Field) string {
+	switch {
+	case f.Desc.IsMap():
+		return "map"
+	case f.Desc.IsList():
+		return "repeated " + f.Desc.Kind().String()
+	}
+	return f.Desc.Kind().String()
+}
+
+func protocVersion(gen *protogen.Plugin) string {
+	v := gen.Request.GetCompilerVersion()
+	if v == nil {
+		return "(unknown)"
+	}
+	var suffix string
+	if s := v.GetSuffix(); s != "" {
+		suffix = "-" + s
+	}
+	return fmt.Sprintf("v%d.%d.%d%s", v.GetMajor(), v.GetMinor(), v.GetPatch(), suffix)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	v1 "github.com/realHoangHai/awesome/api/user/v1"
+	"github.com/realHoangHai/awesome/pkg/utils/validator"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFile(t *testing.T) {
+	resp := generate(t, protodesc.ToFileDescriptorProto(annotations.File_google_api_http_proto),
+		protodesc.ToFileDescriptorProto(annotations.File_google_api_annotations_proto),
+		protodesc.ToFileDescriptorProto(v1.File_user_proto))
+	if resp.Error != nil || len(resp.File) != 1 {
+		t.Fatalf("got response=%v, want the validate file", resp)
+	}
+	want, err := os.ReadFile("../../api/user/v1/user_validate.pb.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.File[0].GetContent(); got != string(want) {
+		t.Errorf("got generated file:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGenerateFile_Rules(t *testing.T) {
+	cases := []struct {
+		name    string
+		typ     descriptorpb.FieldDescriptorProto_Type
+		label   descriptorpb.FieldDescriptorProto_Label
+		rules   *validator.FieldRules
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "email and in",
+			typ:   descriptorpb.FieldDescriptorProto_TYPE_STRING,
+			rules: &validator.FieldRules{Type: &validator.FieldRules_String_{String_: &validator.StringRules{Email: proto.Bool(true), In: []string{"a", "b"}}}},
+			want:  []string{"!validator.IsEmail(v)", `!(v == "a" || v == "b")`, "must be one of [a, b]", `Tag: "oneof", Param: "a b"`},
+		},
+		{
+			name:  "double range",
+			typ:   descriptorpb.FieldDescriptorProto_TYPE_FLOAT,
+			rules: &validator.FieldRules{Type: &validator.FieldRules_Double{Double: &validator.DoubleRules{Gte: proto.Float64(0.5), Lt: proto.Float64(100)}}},
+			want:  []string{"v < 0.5", "v >= 100", "must be greater than or equal to 0.5", `Tag: "gte", Param: "0.5"`},
+		},
+		{
+			name:  "repeated items",
+			typ:   descriptorpb.FieldDescriptorProto_TYPE_STRING,
+			label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED,
+			rules: &validator.FieldRules{Required: proto.Bool(true), Type: &validator.FieldRules_Repeated{Repeated: &validator.RepeatedRules{MaxItems: proto.Uint64(3)}}},
+			want:  []string{"len(v) == 0", "len(v) > 3", "must have at most 3 items", `Field: "itemValue", Description: "is required", Tag: "required"})`, `Tag: "max", Param: "3"`},
+		},
+		{
+			name:    "string rules on an integer",
+			typ:     descriptorpb.FieldDescriptorProto_TYPE_INT64,
+			rules:   &validator.FieldRules{Type: &validator.FieldRules_String_{String_: &validator.StringRules{MinLen: proto.Uint64(1)}}},
+			wantErr: "string rules on a field of type int64",
+		},
+		{
+			name:    "negative bound of an unsigned integer",
+			typ:     descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+			rules:   &validator.FieldRules{Type: &validator.FieldRules_Int{Int: &validator.IntRules{Gt: proto.Int64(-1)}}},
+			wantErr: "negative bound -1",
+		},
+		{
+			name:    "invalid pattern",
+			typ:     descriptorpb.FieldDescriptorProto_TYPE_STRING,
+			rules:   &validator.FieldRules{Type: &validator.FieldRules_String_{String_: &validator.StringRules{Pattern: proto.String("(")}}},
+			wantErr: "invalid pattern",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := &descriptorpb.FieldOptions{}
+			proto.SetExtension(opts, validator.E_Rules, c.rules)
+			label := c.label
+			if label == 0 {
+				label = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+			}
+			resp := generate(t, &descriptorpb.FileDescriptorProto{
+				Name:       proto.String("test.proto"),
+				Package:    proto.String("test"),
+				Syntax:     proto.String("proto3"),
+				Dependency: []string{"validator/validator.proto"},
+				Options:    &descriptorpb.FileOptions{GoPackage: proto.String("example.com/test;test")},
+				MessageType: []*descriptorpb.DescriptorProto{{
+					Name: proto.String("Msg"),
+					Field: []*descriptorpb.FieldDescriptorProto{{
+						Name:     proto.String("item_value"),
+						JsonName: proto.String("itemValue"),
+						Number:   proto.Int32(1),
+						Label:    label.Enum(),
+						Type:     c.typ.Enum(),
+						Options:  opts,
+					}},
+				}},
+			})
+			if c.wantErr != "" {
+				if !strings.Contains(resp.GetError(), c.wantErr) {
+					t.Errorf("got error=%q, want %q", resp.GetError(), c.wantErr)
+				}
+				return
+			}
+			if resp.Error != nil || len(resp.File) != 1 {
+				t.Fatalf("got response=%v, want the validate file", resp)
+			}
+			for _, w := range c.want {
+				if !strings.Contains(resp.File[0].GetContent(), w) {
+					t.Errorf("got generated file:\n%s\nwant it to contain %q", resp.File[0].GetContent(), w)
+				}
+			}
+		})
+	}
+}
+
+// generate runs the plugin on the last of the given files, along with descriptor.proto and validator.proto.
+func generate(t *testing.T, files ...*descriptorpb.FileDescriptorProto) *pluginpb.CodeGeneratorResponse {
+	gen, err := protogen.Options{}.New(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate:  []string{files[len(files)-1].GetName()},
+		Parameter:       proto.String("paths=source_relative"),
+		CompilerVersion: &pluginpb.Version{Major: proto.Int32(3), Minor: proto.Int32(19), Patch: proto.Int32(4)},
+		ProtoFile: append([]*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(validator.File_validator_validator_proto),
+		}, files...),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range gen.Files {
+		if f.Generate {
+			if err := generateFile(gen, f); err != nil {
+				gen.Error(err)
+			}
+		}
+	}
+	return gen.Response()
+}
@@ -12,6 +12,15 @@ type Config struct {
 	Log    SectionLog    `mapstructure:"log"`
 	Redis  SectionRedis  `mapstructure:"redis"`
 	Health SectionHealth `mapstructure:"health"`
+
+	AutoTLS   SectionAutoTLS   `mapstructure:"auto_tls"`
+	RateLimit SectionRateLimit `mapstructure:"rate_limit"`
+	Access    SectionAccess    `mapstructure:"access"`
+	I18n      SectionI18n      `mapstructure:"i18n"`
+
+	Concurrency SectionConcurrency `mapstructure:"concurrency"`
+
+	Admin SectionAdmin `mapstructure:"admin"`
 }
 
 func LoadConfig(path string) (cfg Config, err error) {
@@ -29,12 +38,42 @@ func LoadConfig(path string) (cfg Config, err error) {
 	return
 }
 
+// Reload reads again the config file loaded by LoadConfig.
+func Reload() (cfg Config, err error) {
+	if err = viper.ReadInConfig(); err != nil {
+		return
+	}
+
+	err = viper.Unmarshal(&cfg)
+	return
+}
+
 type SectionCore struct {
 	Name        string `mapstructure:"name"`
 	Address     string `mapstructure:"address"`
 	TLSCertFile string `mapstructure:"tls_cert_file"`
 	TLSKeyFile  string `mapstructure:"tls_key_file"`
 
+	// TLSClientCAFile is the CA bundle for verifying client certificates, enables mutual TLS if set.
+	TLSClientCAFile string `mapstructure:"tls_client_ca_file"`
+	// TLSClientAuth is one of: verify_if_given, require_and_verify (default).
+	// request and require do not verify the client certificates, the server refuses to start with them.
+	TLSClientAuth string `mapstructure:"tls_client_auth"`
+
+	GRPCAddress           string        `mapstructure:"grpc_address"`
+	GRPCTLSCertFile       string        `mapstructure:"grpc_tls_cert_file"`
+	GRPCTLSKeyFile        string        `mapstructure:"grpc_tls_key_file"`
+	GRPCConnectionTimeout time.Duration `mapstructure:"grpc_connection_timeout"`
+
+	HTTPAddress      string        `mapstructure:"http_address"`
+	HTTPTLSCertFile  string        `mapstructure:"http_tls_cert_file"`
+	HTTPTLSKeyFile   string        `mapstructure:"http_tls_key_file"`
+	HTTPReadTimeout  time.Duration `mapstructure:"http_read_timeout"`
+	HTTPWriteTimeout time.Duration `mapstructure:"http_write_timeout"`
+
+	// UnixSocketMode is file mode of unix domain sockets, i.e... 0o660.
+	UnixSocketMode uint32 `mapstructure:"unix_socket_mode"`
+
 	HealthCheckPath string `mapstructure:"health_check_path"`
 
 	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
@@ -42,6 +81,11 @@ type SectionCore struct {
 	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 	APIPrefix       string        `mapstructure:"api_prefix"`
 
+	ShutdownDrainPeriod time.Duration `mapstructure:"shutdown_drain_period"`
+
+	GracefulUpgrade bool          `mapstructure:"graceful_upgrade"`
+	UpgradeTimeout  time.Duration `mapstructure:"upgrade_timeout"`
+
 	WebDir    string `mapstructure:"web_dir"`
 	WebIndex  string `mapstructure:"web_index"`
 	WebPrefix string `mapstructure:"web_prefix"`
@@ -50,26 +94,138 @@ type SectionCore struct {
 	ContextLogger bool   `mapstructure:"context_logger"`
 
 	Recovery bool `mapstructure:"recovery"`
+	// Validation rejects the invalid gRPC requests, checked by their Validate method or their validate tags.
+	Validation bool `mapstructure:"validation"`
 
 	CORSAllowedHeaders    []string `mapstructure:"cors_allowed_headers"`
 	CORSAllowedMethods    []string `mapstructure:"cor_allowed_methods"`
 	CORSAllowedOrigins    []string `mapstructure:"cors_allowed_origins"`
 	CORSAllowedCredential bool     `mapstructure:"cors_allowed_credential"`
 
+	// GRPCWeb and Connect serve the gRPC-Web and Connect protocols along with gRPC and the gateway.
+	GRPCWeb bool `mapstructure:"grpc_web"`
+	Connect bool `mapstructure:"connect"`
+	// StreamBridge serves the streaming RPCs of the gateway as Server-Sent Events and over WebSocket,
+	// WebSocket requests are accepted from cors_allowed_origins.
+	StreamBridge bool `mapstructure:"stream_bridge"`
+
+	// ProxyProtocol accepts the PROXY protocol v1 and v2 headers on the public listeners,
+	// from the trusted proxies, which are required.
+	ProxyProtocol bool `mapstructure:"proxy_protocol"`
+	// TrustedProxies are the IPs or CIDRs of the proxies trusted to forward the client IP,
+	// i.e... ["10.0.0.0/8"].
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+
+	// Compression are the encodings of the HTTP responses in order of preference, i.e... ["br", "gzip"],
+	// negotiated from Accept-Encoding. gzip, br and zstd are built in, the others require an encoder.
+	Compression        []string `mapstructure:"compression"`
+	CompressionMinSize int      `mapstructure:"compression_min_size"`
+	// CompressionTypes are the content types compressed, i.e... text/ or +json, default are the text types.
+	CompressionTypes []string `mapstructure:"compression_types"`
+	// GRPCCompressors are the compressors of gRPC, i.e... gzip or zstd.
+	GRPCCompressors []string `mapstructure:"grpc_compressors"`
+
 	PProf       bool   `mapstructure:"pprof"`
 	PProfPrefix string `mapstructure:"pprof_prefix"`
 
 	Metrics     bool   `mapstructure:"metrics"`
 	MetricsPath string `mapstructure:"metrics_path" `
 
-	RoutesPrioritization bool   `mapstructure:"routes_prioritization" `
-	ShutdownHook         string `mapstructure:"shutdown_hook"`
+	RoutesPrioritization bool `mapstructure:"routes_prioritization" `
+	// Deprecated: use Admin.Control instead.
+	ShutdownHook string `mapstructure:"shutdown_hook"`
 }
 
 type SectionAutoTLS struct {
 	Enabled bool   `mapstructure:"enabled"`
 	Folder  string `mapstructure:"folder"`
-	Host    string `mapstructure:"host"`
+	// Host is a comma separated list of the hosts of the certificates.
+	Host string `mapstructure:"host"`
+	// Mode is self_signed (default) for development or acme.
+	Mode string `mapstructure:"mode"`
+
+	// ACME mode only.
+	Email string `mapstructure:"email"`
+	// DirectoryURL is the ACME directory, default is Let's Encrypt production directory.
+	DirectoryURL string `mapstructure:"directory_url"`
+	// DirectoryCAFile is the CA bundle trusted when connecting to the ACME directory, i.e... Pebble's root.
+	DirectoryCAFile string        `mapstructure:"directory_ca_file"`
+	RenewBefore     time.Duration `mapstructure:"renew_before"`
+}
+
+type SectionRateLimit struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Store is memory (default) or redis, using the redis section.
+	Store string          `mapstructure:"store"`
+	Rules []RateLimitRule `mapstructure:"rules"`
+}
+
+// RateLimitRule limits the requests of the methods matching Method.
+type RateLimitRule struct {
+	// Method is a gRPC full method, an HTTP path or a prefix ending with *, i.e... /helloworld.Greeter/*.
+	Method string `mapstructure:"method"`
+	// Algorithm is token_bucket (default) or sliding_window.
+	Algorithm string `mapstructure:"algorithm"`
+	// Key is ip (default), method, api_key or subject, subject only for the gRPC methods as HTTP paths are not
+	// authenticated.
+	Key    string        `mapstructure:"key"`
+	Limit  int           `mapstructure:"limit"`
+	Period time.Duration `mapstructure:"period"`
+	Burst  int           `mapstructure:"burst"`
+}
+
+// SectionAccess restricts the methods to networks, the rules are reloaded on SIGHUP.
+type SectionAccess struct {
+	Enabled bool         `mapstructure:"enabled"`
+	Rules   []AccessRule `mapstructure:"rules"`
+}
+
+// AccessRule restricts the methods matching one of Methods, the first rule matching a method applies.
+type AccessRule struct {
+	// Methods are regular expressions of gRPC full methods or HTTP paths, i.e... ^/internal/.
+	Methods []string `mapstructure:"methods"`
+	// Allow are the IPs, CIDRs or named networks (loopback, private) allowed, all if empty.
+	Allow []string `mapstructure:"allow"`
+	// Deny are the IPs, CIDRs or named networks denied, they take precedence over Allow.
+	Deny []string `mapstructure:"deny"`
+}
+
+// SectionI18n localizes the messages of the errors from the Accept-Language header.
+type SectionI18n struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Dir is the directory of the TOML or JSON files of the messages, named after their locale, i.e... vi.toml.
+	Dir string `mapstructure:"dir"`
+	// DefaultLocale is the locale of the languages not in the catalog, not localized if empty.
+	DefaultLocale string `mapstructure:"default_locale"`
+}
+
+type SectionConcurrency struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Algorithm is aimd (default) or gradient.
+	Algorithm    string `mapstructure:"algorithm"`
+	InitialLimit int    `mapstructure:"initial_limit"`
+	MinLimit     int    `mapstructure:"min_limit"`
+	MaxLimit     int    `mapstructure:"max_limit"`
+	// Timeout is the latency considered as overloaded by aimd.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// SectionAdmin configures the admin services, protected by a JWT with the admin scope.
+type SectionAdmin struct {
+	// Address is the address of the admin listener serving metrics, pprof, health check
+	// and the other internal HTTP handlers, they are served on the public address if empty.
+	Address    string `mapstructure:"address"`
+	Reflection bool   `mapstructure:"reflection"`
+	Channelz   bool   `mapstructure:"channelz"`
+	// Control enables the admin control service, its HTTP API is served under ControlPrefix,
+	// default is /internal/admin.
+	Control       bool   `mapstructure:"control"`
+	ControlPrefix string `mapstructure:"control_prefix"`
+	// JWTSecret verifies the admin JWT, the admin services and routes are protected by it if set.
+	// Reflection and Channelz require it, the jwt_secret of the core section is not used for them.
+	JWTSecret string `mapstructure:"jwt_secret"`
+	// Scope is the scope required in the admin JWT, default is admin.
+	Scope string `mapstructure:"scope"`
 }
 
 type SectionAPI struct {
@@ -0,0 +1,147 @@
+// Package access restricts the methods to networks by CIDR allow and deny rules,
+// i.e... admin and internal service to service methods to the private networks.
+package access
+
+import (
+	"errors"
+	"fmt"
+	"github.com/realHoangHai/awesome/internal/auth"
+	"github.com/realHoangHai/awesome/pkg/clientip"
+	"github.com/realHoangHai/awesome/pkg/log"
+	"net"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+var (
+	// ErrInvalidRule reports that a rule can not be used for restricting requests.
+	ErrInvalidRule = errors.New("access: invalid rule")
+
+	// networks are the named networks accepted by ParseNetworks.
+	networks = map[string][]string{
+		"loopback": {"127.0.0.0/8", "::1/128"},
+		"private":  {"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"},
+	}
+)
+
+type (
+	// Rule restricts the methods it matches to networks.
+	Rule struct {
+		// Methods matches the methods of the rule, i.e... auth.WhiteListRegexp("^/internal/").
+		Methods auth.WhiteListFunc
+		// Allow are the networks allowed, all networks are allowed if empty.
+		Allow []*net.IPNet
+		// Deny are the networks denied, they take precedence over Allow.
+		Deny []*net.IPNet
+	}
+
+	// Controller allows or denies the requests by their client IP, using the first rule matching their method.
+	// For gRPC requests the method is the full method, i.e... /helloworld.Greeter/SayHello,
+	// for HTTP requests it is URL.Path. Requests of methods matching no rule are allowed.
+	Controller struct {
+		rules atomic.Value // []Rule
+		log   log.Logger
+	}
+
+	// Option is a configuration option of Controller.
+	Option func(*Controller)
+)
+
+// New returns a new Controller using the given rules.
+func New(rules []Rule, opts ...Option) *Controller {
+	c := &Controller{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.log == nil {
+		c.log = log.Root()
+	}
+	c.SetRules(rules)
+	return c
+}
+
+// Logger is an option to set logger of Controller.
+func Logger(logger log.Logger) Option {
+	return func(c *Controller) {
+		c.log = logger
+	}
+}
+
+// SetRules replaces the rules, it is safe to call while serving requests.
+func (c *Controller) SetRules(rules []Rule) {
+	c.rules.Store(append([]Rule(nil), rules...))
+}
+
+// Rules returns the rules in use.
+func (c *Controller) Rules() []Rule {
+	rules, _ := c.rules.Load().([]Rule)
+	return rules
+}
+
+// Allowed reports whether the client of the given IP is allowed to call the method.
+// A nil IP is unknown, i.e... a peer of unix domain socket, it is only allowed by rules without Allow networks.
+func (c *Controller) Allowed(ip net.IP, method string) bool {
+	for _, r := range c.Rules() {
+		if r.Methods == nil || !r.Methods(method) {
+			continue
+		}
+		return r.allowed(ip)
+	}
+	return true
+}
+
+func (r Rule) allowed(ip net.IP) bool {
+	if ip == nil {
+		return len(r.Allow) == 0
+	}
+	if contains(r.Deny, ip) {
+		return false
+	}
+	return len(r.Allow) == 0 || contains(r.Allow, ip)
+}
+
+func contains(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseRule returns a new Rule restricting the methods matching one of the regular expressions
+// to the allowed and not denied networks, see ParseNetworks.
+func ParseRule(methods, allow, deny []string) (Rule, error) {
+	if len(methods) == 0 {
+		return Rule{}, fmt.Errorf("%w: no methods", ErrInvalidRule)
+	}
+	for _, p := range methods {
+		if _, err := regexp.Compile(p); err != nil {
+			return Rule{}, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+	}
+	r := Rule{Methods: auth.WhiteListRegexp(methods...)}
+	var err error
+	if r.Allow, err = ParseNetworks(allow...); err != nil {
+		return Rule{}, fmt.Errorf("%w: allow: %v", ErrInvalidRule, err)
+	}
+	if r.Deny, err = ParseNetworks(deny...); err != nil {
+		return Rule{}, fmt.Errorf("%w: deny: %v", ErrInvalidRule, err)
+	}
+	return r, nil
+}
+
+// ParseNetworks parses the given IPs, CIDRs or named networks: loopback and private,
+// i.e... ["private", "192.0.2.1", "198.51.100.0/24"].
+func ParseNetworks(values ...string) ([]*net.IPNet, error) {
+	var cidrs []string
+	for _, v := range values {
+		if named, ok := networks[strings.ToLower(strings.TrimSpace(v))]; ok {
+			cidrs = append(cidrs, named...)
+			continue
+		}
+		cidrs = append(cidrs, v)
+	}
+	return clientip.ParseNetworks(cidrs...)
+}
@@ -0,0 +1,138 @@
+package access_test
+
+import (
+	"context"
+	"errors"
+	"github.com/realHoangHai/awesome/internal/access"
+	"github.com/realHoangHai/awesome/internal/dispatch"
+	"github.com/realHoangHai/awesome/pkg/clientip"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func mustRule(t *testing.T, methods, allow, deny []string) access.Rule {
+	t.Helper()
+	r, err := access.ParseRule(methods, allow, deny)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestAllowed(t *testing.T) {
+	ctrl := access.New([]access.Rule{
+		mustRule(t, []string{"^/internal/"}, []string{"private", "loopback"}, []string{"10.1.0.0/16"}),
+		mustRule(t, []string{"^/admin"}, []string{"192.0.2.1"}, nil),
+		mustRule(t, []string{"^/public/"}, nil, []string{"203.0.113.0/24"}),
+	})
+	cases := []struct {
+		name   string
+		ip     string
+		method string
+		want   bool
+	}{
+		{name: "private", ip: "10.0.0.1", method: "/internal/users", want: true},
+		{name: "loopback v6", ip: "::1", method: "/internal/users", want: true},
+		{name: "denied private", ip: "10.1.2.3", method: "/internal/users", want: false},
+		{name: "public", ip: "198.51.100.1", method: "/internal/users", want: false},
+		{name: "single ip", ip: "192.0.2.1", method: "/admin.Control/Shutdown", want: true},
+		{name: "other ip", ip: "192.0.2.2", method: "/admin.Control/Shutdown", want: false},
+		{name: "deny only", ip: "203.0.113.9", method: "/public/users", want: false},
+		{name: "not denied", ip: "198.51.100.1", method: "/public/users", want: true},
+		{name: "unknown ip", method: "/internal/users", want: false},
+		{name: "unknown ip deny only", method: "/public/users", want: true},
+		{name: "no rule", ip: "198.51.100.1", method: "/v1/users", want: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ctrl.Allowed(net.ParseIP(c.ip), c.method); got != c.want {
+				t.Errorf("got allowed=%v, want allowed=%v", got, c.want)
+			}
+		})
+	}
+
+	ctrl.SetRules(nil)
+	if !ctrl.Allowed(net.ParseIP("198.51.100.1"), "/internal/users") {
+		t.Error("got denied, want allowed after the rules are removed")
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	cases := []struct {
+		name                 string
+		methods, allow, deny []string
+	}{
+		{name: "no methods", allow: []string{"private"}},
+		{name: "invalid regexp", methods: []string{"^/internal/("}},
+		{name: "invalid allow", methods: []string{"^/internal/"}, allow: []string{"10.0.0.0/33"}},
+		{name: "invalid deny", methods: []string{"^/internal/"}, deny: []string{"public"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := access.ParseRule(c.methods, c.allow, c.deny); !errors.Is(err, access.ErrInvalidRule) {
+				t.Errorf("got err=%v, want err=%v", err, access.ErrInvalidRule)
+			}
+		})
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	ctrl := access.New([]access.Rule{mustRule(t, []string{"^/internal/", "^/test.Internal/"}, []string{"private"}, nil)})
+
+	h := ctrl.HTTPInterceptor()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	cases := []struct {
+		name       string
+		remoteAddr string
+		clientIP   string
+		header     http.Header
+		grpc       bool
+		want       int
+	}{
+		{name: "private peer", remoteAddr: "10.0.0.1:1234", want: http.StatusOK},
+		{name: "public peer", remoteAddr: "198.51.100.1:1234", want: http.StatusForbidden},
+		{name: "resolved client ip", remoteAddr: "10.0.0.1:1234", clientIP: "198.51.100.1", want: http.StatusForbidden},
+		{
+			name:       "forged gRPC headers",
+			remoteAddr: "198.51.100.1:1234",
+			header:     http.Header{"Content-Type": {"application/grpc"}, "Connect-Protocol-Version": {"1"}},
+			want:       http.StatusForbidden,
+		},
+		// restricted by the gRPC interceptors.
+		{name: "dispatched to gRPC", remoteAddr: "198.51.100.1:1234", grpc: true, want: http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/internal/users", nil)
+			req.RemoteAddr = c.remoteAddr
+			for k, v := range c.header {
+				req.Header[k] = v
+			}
+			if c.clientIP != "" {
+				req = req.WithContext(clientip.NewContext(req.Context(), net.ParseIP(c.clientIP)))
+			}
+			if c.grpc {
+				req = dispatch.WithGRPC(req)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != c.want {
+				t.Errorf("got status=%d, want status=%d", rec.Code, c.want)
+			}
+		})
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	ctx := clientip.NewContext(context.Background(), net.ParseIP("198.51.100.1"))
+	_, err := ctrl.UnaryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Internal/Get"}, handler)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("got code=%v, want code=%v", status.Code(err), codes.PermissionDenied)
+	}
+	if _, err := ctrl.UnaryInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Public/Get"}, handler); err != nil {
+		t.Errorf("got err=%v, want no error", err)
+	}
+}
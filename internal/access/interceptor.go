@@ -0,0 +1,81 @@
+package access
+
+import (
+	"context"
+	"github.com/realHoangHai/awesome/internal/dispatch"
+	"github.com/realHoangHai/awesome/pkg/clientip"
+	"github.com/realHoangHai/awesome/pkg/utils/response"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"net/http"
+)
+
+// UnaryInterceptor returns a grpc.UnaryServerInterceptor that restricts the requests,
+// denied requests get codes.PermissionDenied.
+func (c *Controller) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := c.allowGRPC(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor returns a grpc.StreamServerInterceptor that restricts the streams when they are opened.
+func (c *Controller) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := c.allowGRPC(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// HTTPInterceptor returns a HTTP interceptor that restricts the requests by URL.Path,
+// denied requests get 403 Forbidden.
+// Requests dispatched to the gRPC server are left to the gRPC interceptors, see dispatch.IsGRPC.
+func (c *Controller) HTTPInterceptor() func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if dispatch.IsGRPC(r) {
+				h.ServeHTTP(w, r)
+				return
+			}
+			ip, ok := clientip.FromContext(r.Context())
+			if !ok {
+				ip = hostIP(r.RemoteAddr)
+			}
+			if !c.Allowed(ip, r.URL.Path) {
+				c.log.Context(r.Context()).Warnf("access: deny %s from %s", r.URL.Path, ip)
+				response.Error(w, r, status.Error(codes.PermissionDenied, "access denied"))
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (c *Controller) allowGRPC(ctx context.Context, method string) error {
+	ip, ok := clientip.FromContext(ctx)
+	if !ok {
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			ip = hostIP(p.Addr.String())
+		}
+	}
+	if !c.Allowed(ip, method) {
+		c.log.Context(ctx).Warnf("access: deny %s from %s", method, ip)
+		return status.Error(codes.PermissionDenied, "access denied")
+	}
+	return nil
+}
+
+// hostIP returns the IP of the given address, nil if it is not an IP address, i.e... of unix domain socket.
+func hostIP(addr string) net.IP {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(addr)
+}
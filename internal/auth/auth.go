@@ -22,6 +22,8 @@ var (
 	ErrInvalidToken = errors.New("auth: invalid token")
 	// ErrMultipleAuthFound reports that too many authorization entries were found.
 	ErrMultipleAuthFound = errors.New("auth: too many authorization entries")
+	// ErrPermissionDenied reports that the authenticated caller lacks permission.
+	ErrPermissionDenied = errors.New("auth: permission denied")
 )
 
 // Authenticator defines the interface to perform the actual authentication of the request.
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"net/http"
+	"strings"
+)
+
+const (
+	// IdentityMD is the metadata name of the client identity forwarded by the gRPC gateway.
+	IdentityMD = "x-client-identity"
+)
+
+var (
+	// ErrInvalidIdentity reports that the forwarded identity is not signed by the gateway.
+	ErrInvalidIdentity = errors.New("auth: invalid forwarded identity")
+)
+
+// IdentityForwarder forwards the identity of the client certificates verified by the HTTP server
+// to the gRPC server through the gRPC gateway. The identity is signed with a key shared by
+// both sides so that it can not be forged by the clients.
+type IdentityForwarder struct {
+	key []byte
+}
+
+// forwarded is the payload of the forwarded identity.
+type forwarded struct {
+	// Identity is nil if the client did not present a certificate.
+	Identity *Identity `json:"identity,omitempty"`
+}
+
+// NewIdentityForwarder returns a new IdentityForwarder signing the identities with the given key.
+func NewIdentityForwarder(key []byte) *IdentityForwarder {
+	return &IdentityForwarder{key: key}
+}
+
+// Metadata returns the signed identity of the verified client certificate of the given request as metadata.
+// It is used as the gRPC gateway metadata annotator, see runtime.WithMetadata.
+func (f *IdentityForwarder) Metadata(ctx context.Context, r *http.Request) metadata.MD {
+	fw := forwarded{}
+	if r.TLS != nil {
+		// the certificates not verified by the TLS handshake are forwarded as no certificate.
+		fw.Identity, _ = VerifiedIdentity(*r.TLS)
+	}
+	// the value is always set, so that the gateway's own certificate is never used as the client identity.
+	v, err := f.sign(fw)
+	if err != nil {
+		return nil
+	}
+	return metadata.Pairs(IdentityMD, v)
+}
+
+// Resolve attaches the forwarded identity to the context if any.
+// A forwarded request without client certificate gets a nil identity attached.
+func (f *IdentityForwarder) Resolve(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md[IdentityMD]) == 0 {
+		return ctx, nil
+	}
+	// clients may send forged values along with the one added by the gateway.
+	for _, v := range md[IdentityMD] {
+		if fw, err := f.verify(v); err == nil {
+			return NewIdentityContext(ctx, fw.Identity), nil
+		}
+	}
+	return nil, ErrInvalidIdentity
+}
+
+// UnaryInterceptor returns a grpc.UnaryServerInterceptor that resolves the forwarded identity.
+func (f *IdentityForwarder) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		newCtx, err := f.Resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(newCtx, req)
+	}
+}
+
+// StreamInterceptor returns a grpc.StreamServerInterceptor that resolves the forwarded identity.
+func (f *IdentityForwarder) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		newCtx, err := f.Resolve(ss.Context())
+		if err != nil {
+			return err
+		}
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = newCtx
+		return handler(srv, wrapped)
+	}
+}
+
+func (f *IdentityForwarder) sign(fw forwarded) (string, error) {
+	b, err := json.Marshal(fw)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(f.mac(payload)), nil
+}
+
+func (f *IdentityForwarder) verify(v string) (*forwarded, error) {
+	i := strings.LastIndexByte(v, '.')
+	if i < 0 {
+		return nil, ErrInvalidIdentity
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(v[i+1:])
+	if err != nil || !hmac.Equal(sig, f.mac(v[:i])) {
+		return nil, ErrInvalidIdentity
+	}
+	b, err := base64.RawURLEncoding.DecodeString(v[:i])
+	if err != nil {
+		return nil, ErrInvalidIdentity
+	}
+	fw := &forwarded{}
+	if err := json.Unmarshal(b, fw); err != nil {
+		return nil, ErrInvalidIdentity
+	}
+	return fw, nil
+}
+
+func (f *IdentityForwarder) mac(payload string) []byte {
+	h := hmac.New(sha256.New, f.key)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
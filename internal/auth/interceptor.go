@@ -1,11 +1,11 @@
 package auth
 
 import (
-	"fmt"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/realHoangHai/awesome/pkg/status"
+	"github.com/realHoangHai/awesome/pkg/utils/response"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"net/http"
 )
@@ -80,7 +80,7 @@ func HTTPInterceptor(auth Authenticator) func(h http.Handler) http.Handler {
 			md.Set(AuthorizationMD, token)
 			newCtx, err := auth.Authenticate(metadata.NewIncomingContext(r.Context(), md))
 			if err != nil {
-				http.Error(w, fmt.Sprintf(`{"code":%d,"message":"%s"}`, codes.Unauthenticated, err), http.StatusUnauthorized)
+				response.Error(w, r, status.Unauthenticated("%v", err))
 				return
 			}
 			h.ServeHTTP(w, r.WithContext(newCtx))
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"strings"
+)
+
+const (
+	// spiffeScheme is the URI scheme of SPIFFE IDs, see https://github.com/spiffe/spiffe/blob/main/standards/SPIFFE-ID.md.
+	spiffeScheme = "spiffe"
+)
+
+var (
+	// ErrCertificateMissing reports that no verified client certificate is found in the incoming context.
+	ErrCertificateMissing = errors.New("auth: could not locate client certificate")
+)
+
+// Identity is the identity of a client authenticated by its TLS certificate.
+type Identity struct {
+	Subject        string   `json:"subject,omitempty"`
+	CommonName     string   `json:"common_name,omitempty"`
+	SerialNumber   string   `json:"serial_number,omitempty"`
+	DNSNames       []string `json:"dns_names,omitempty"`
+	EmailAddresses []string `json:"email_addresses,omitempty"`
+	IPAddresses    []string `json:"ip_addresses,omitempty"`
+	URIs           []string `json:"uris,omitempty"`
+	// SPIFFEID is the first URI SAN using spiffe scheme, if any.
+	SPIFFEID string `json:"spiffe_id,omitempty"`
+}
+
+// NewIdentity returns the identity of the given client certificate.
+func NewIdentity(cert *x509.Certificate) *Identity {
+	id := &Identity{
+		Subject:        cert.Subject.String(),
+		CommonName:     cert.Subject.CommonName,
+		SerialNumber:   cert.SerialNumber.String(),
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+	}
+	for _, ip := range cert.IPAddresses {
+		id.IPAddresses = append(id.IPAddresses, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		id.URIs = append(id.URIs, uri.String())
+		if id.SPIFFEID == "" && strings.EqualFold(uri.Scheme, spiffeScheme) {
+			id.SPIFFEID = uri.String()
+		}
+	}
+	return id
+}
+
+// The context key
+type identityKey struct{}
+
+// NewIdentityContext creates a new context with the identity attached.
+func NewIdentityContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext fetches the identity attached to the ctx.
+func IdentityFromContext(ctx context.Context) (id *Identity, ok bool) {
+	id, ok = ctx.Value(identityKey{}).(*Identity)
+	return
+}
+
+// PeerIdentity returns the identity of the client certificate presented by the peer of the gRPC connection,
+// only if it has been verified by the TLS handshake.
+func PeerIdentity(ctx context.Context) (*Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, false
+	}
+	return VerifiedIdentity(info.State)
+}
+
+// VerifiedIdentity returns the identity of the client certificate of the given TLS connection state,
+// only if it has been verified by the TLS handshake, i.e... the leaf of the first verified chain.
+// The certificates presented with tls.RequestClientCert or tls.RequireAnyClientCert are never verified.
+func VerifiedIdentity(state tls.ConnectionState) (*Identity, bool) {
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	return NewIdentity(state.VerifiedChains[0][0]), true
+}
+
+// MTLS returns an AuthenticatorFunc that authenticates clients by their TLS certificates.
+// The identity is looked up in the context first, which is the one forwarded by the gRPC gateway,
+// then in the peer of the gRPC connection. The identity is attached to the context,
+// use IdentityFromContext to fetch it. If verify is not nil, it is called to authorize the identity.
+//
+// Only the certificates verified by the TLS handshake are used, hence the server must be configured
+// to verify client certificates.
+func MTLS(verify func(ctx context.Context, id *Identity) error) AuthenticatorFunc {
+	return func(ctx context.Context) (context.Context, error) {
+		id, ok := IdentityFromContext(ctx)
+		if !ok {
+			id, _ = PeerIdentity(ctx)
+		}
+		// a nil identity in the context means the request is forwarded without client certificate.
+		if id == nil {
+			return nil, ErrCertificateMissing
+		}
+		if verify != nil {
+			if err := verify(ctx, id); err != nil {
+				return nil, err
+			}
+		}
+		return NewIdentityContext(ctx, id), nil
+	}
+}
@@ -0,0 +1,141 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"github.com/realHoangHai/awesome/internal/auth"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestMTLS(t *testing.T) {
+	cert := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "alice"},
+		URIs:         []*url.URL{{Scheme: "https", Host: "example.org"}, {Scheme: "spiffe", Host: "example.org", Path: "/alice"}},
+	}
+	peerCtx := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:   [][]*x509.Certificate{{cert}},
+		}},
+	})
+	// presented with tls.RequestClientCert or tls.RequireAnyClientCert.
+	unverifiedCtx := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}},
+	})
+	errDenied := errors.New("denied")
+	jwtLike := auth.AuthenticatorFunc(func(ctx context.Context) (context.Context, error) {
+		return nil, auth.ErrAuthorizationMissing
+	})
+	cases := []struct {
+		name string
+		auth auth.Authenticator
+		ctx  context.Context
+		id   string
+		err  error
+	}{
+		{
+			name: "peer certificate",
+			auth: auth.MTLS(nil),
+			ctx:  peerCtx,
+			id:   "spiffe://example.org/alice",
+		},
+		{
+			name: "no peer certificate",
+			auth: auth.MTLS(nil),
+			ctx:  context.Background(),
+			err:  auth.ErrCertificateMissing,
+		},
+		{
+			name: "unverified peer certificate",
+			auth: auth.MTLS(nil),
+			ctx:  unverifiedCtx,
+			err:  auth.ErrCertificateMissing,
+		},
+		{
+			name: "forwarded without certificate, peer certificate is not used",
+			auth: auth.MTLS(nil),
+			ctx:  auth.NewIdentityContext(peerCtx, nil),
+			err:  auth.ErrCertificateMissing,
+		},
+		{
+			name: "denied by verify function",
+			auth: auth.MTLS(func(ctx context.Context, id *auth.Identity) error {
+				if id.CommonName != "bob" {
+					return errDenied
+				}
+				return nil
+			}),
+			ctx: peerCtx,
+			err: errDenied,
+		},
+		{
+			name: "composed with multi authenticator",
+			auth: auth.MultiAuthenticator{jwtLike, auth.MTLS(nil)},
+			ctx:  peerCtx,
+			id:   "spiffe://example.org/alice",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx, err := c.auth.Authenticate(c.ctx)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("got err=%v, want err=%v", err, c.err)
+			}
+			if err != nil {
+				return
+			}
+			id, ok := auth.IdentityFromContext(ctx)
+			if !ok || id.SPIFFEID != c.id {
+				t.Fatalf("got id=%+v, want spiffe_id=%s", id, c.id)
+			}
+		})
+	}
+}
+
+func TestIdentityForwarder(t *testing.T) {
+	f := auth.NewIdentityForwarder([]byte("secret"))
+	cert := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "alice"},
+	}
+	r, _ := http.NewRequest(http.MethodGet, "/", nil)
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+	md := f.Metadata(context.Background(), r)
+
+	ctx, err := f.Resolve(metadata.NewIncomingContext(context.Background(), md))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := auth.IdentityFromContext(ctx); !ok || id.CommonName != "alice" {
+		t.Fatalf("got id=%+v, want common_name=alice", id)
+	}
+
+	// signed by another key.
+	forged := auth.NewIdentityForwarder([]byte("forged")).Metadata(context.Background(), r)
+	if _, err := f.Resolve(metadata.NewIncomingContext(context.Background(), forged)); !errors.Is(err, auth.ErrInvalidIdentity) {
+		t.Fatalf("got err=%v, want err=%v", err, auth.ErrInvalidIdentity)
+	}
+
+	// no client certificate or an unverified one, a nil identity is attached.
+	unverified := &http.Request{TLS: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}}
+	for _, r := range []*http.Request{{}, unverified} {
+		md = f.Metadata(context.Background(), r)
+		ctx, err = f.Resolve(metadata.NewIncomingContext(context.Background(), md))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id, ok := auth.IdentityFromContext(ctx); !ok || id != nil {
+			t.Fatalf("got id=%+v, ok=%v, want nil identity attached", id, ok)
+		}
+	}
+}
@@ -2,13 +2,12 @@ package biz
 
 import (
 	"context"
-	"errors"
 	v1 "github.com/realHoangHai/awesome/api/user/v1"
 	"github.com/realHoangHai/awesome/pkg/log"
 )
 
 var (
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound = v1.ErrorUserNotFound("user not found")
 )
 
 type User struct {
@@ -0,0 +1,103 @@
+package concurrency
+
+import (
+	"math"
+	"time"
+)
+
+type (
+	// Algorithm adapts the concurrency limit of a method from the samples of its finished requests.
+	// An Algorithm is used by a single method and is not called concurrently.
+	Algorithm interface {
+		// Update returns the new limit from the current limit and a sample.
+		Update(limit float64, s Sample) float64
+	}
+
+	// Sample is the observation of a finished request.
+	Sample struct {
+		RTT time.Duration
+		// InFlight is the number of in-flight requests when the request started, including itself.
+		InFlight int
+		// Dropped reports whether the request failed because of the load, i.e... timed out.
+		Dropped bool
+	}
+
+	// AIMD increases the limit by 1 when the requests succeed, and decreases it multiplicatively
+	// when a request is dropped or slower than Timeout.
+	AIMD struct {
+		// Timeout is the latency considered as overloaded, zero to only back off on drops.
+		Timeout time.Duration
+		// BackoffRatio is the ratio the limit is multiplied by when backing off, default is 0.9.
+		BackoffRatio float64
+	}
+
+	// Gradient adjusts the limit by the gradient between the long term average latency
+	// and the latency of the samples: the limit decreases when the latency increases
+	// as requests start queueing, and grows by a queue of sqrt(limit) otherwise.
+	Gradient struct {
+		// Tolerance is the ratio of latency increase tolerated before decreasing the limit, default is 1.5.
+		Tolerance float64
+		// Smoothing is the weight of the new limit, default is 0.2.
+		Smoothing float64
+
+		longRTT float64
+		samples int
+	}
+)
+
+const (
+	defaultBackoffRatio = 0.9
+	defaultTolerance    = 1.5
+	defaultSmoothing    = 0.2
+	// longWindow is the number of samples of the long term average latency.
+	longWindow = 600
+)
+
+// Update implements Algorithm.
+func (a *AIMD) Update(limit float64, s Sample) float64 {
+	if s.Dropped || (a.Timeout > 0 && s.RTT > a.Timeout) {
+		ratio := a.BackoffRatio
+		if ratio <= 0 || ratio >= 1 {
+			ratio = defaultBackoffRatio
+		}
+		return limit * ratio
+	}
+	// only grow when the limit is actually used.
+	if float64(s.InFlight)*2 >= limit {
+		return limit + 1
+	}
+	return limit
+}
+
+// Update implements Algorithm.
+func (g *Gradient) Update(limit float64, s Sample) float64 {
+	if s.Dropped {
+		return limit / 2
+	}
+	rtt := float64(s.RTT)
+	if rtt <= 0 {
+		return limit
+	}
+	// exponential moving average, bootstrapped by a simple average.
+	if g.samples < longWindow {
+		g.samples++
+		g.longRTT += (rtt - g.longRTT) / float64(g.samples)
+	} else {
+		g.longRTT += (rtt - g.longRTT) * 2 / (longWindow + 1)
+	}
+	// do not grow when the limit is not used, it would grow unbounded.
+	if float64(s.InFlight)*2 < limit {
+		return limit
+	}
+	tolerance := g.Tolerance
+	if tolerance < 1 {
+		tolerance = defaultTolerance
+	}
+	smoothing := g.Smoothing
+	if smoothing <= 0 || smoothing > 1 {
+		smoothing = defaultSmoothing
+	}
+	gradient := math.Max(0.5, math.Min(1, tolerance*g.longRTT/rtt))
+	next := limit*gradient + math.Sqrt(limit)
+	return limit*(1-smoothing) + next*smoothing
+}
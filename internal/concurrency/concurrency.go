@@ -0,0 +1,227 @@
+package concurrency
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// CriticalityMD is the metadata name of the criticality of a request,
+	// forwarded by the gateway from Grpc-Metadata-X-Criticality header.
+	CriticalityMD = "x-criticality"
+
+	defaultInitialLimit = 20
+	defaultMinLimit     = 1
+	defaultMaxLimit     = 1000
+)
+
+// criticalities of requests, the less critical requests are shed first.
+const (
+	Sheddable Criticality = iota
+	SheddablePlus
+	Critical
+	CriticalPlus
+)
+
+var (
+	// criticalityNames are the names of the criticalities in metadata and metrics.
+	criticalityNames = []string{"sheddable", "sheddable_plus", "critical", "critical_plus"}
+	// criticalityShares are the shares of the limit a request of a criticality can use.
+	criticalityShares = []float64{0.5, 0.7, 0.9, 1}
+
+	limitGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grpc",
+		Name:      "concurrency_limit",
+		Help:      "Adaptive concurrency limit of the gRPC method.",
+	}, []string{"grpc_method"})
+	inFlightGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grpc",
+		Name:      "concurrency_in_flight",
+		Help:      "Number of in-flight requests of the gRPC method.",
+	}, []string{"grpc_method"})
+	rejectedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grpc",
+		Name:      "concurrency_rejected_total",
+		Help:      "Total number of requests rejected by the concurrency limit by criticality.",
+	}, []string{"grpc_method", "criticality"})
+)
+
+func init() {
+	prometheus.MustRegister(limitGauge, inFlightGauge, rejectedCounter)
+}
+
+type (
+	// Criticality is the criticality of a request.
+	Criticality int
+
+	// Limiter limits the in-flight requests of each method by an adaptive limit.
+	// A request is rejected when the in-flight requests exceed the share of the limit
+	// of its criticality, so that the less critical requests are shed first.
+	Limiter struct {
+		newAlgorithm func() Algorithm
+		initial      int
+		min          int
+		max          int
+
+		mu      sync.Mutex
+		methods map[string]*methodLimit
+	}
+
+	// Option is a configuration option of Limiter.
+	Option func(*Limiter)
+
+	methodLimit struct {
+		name     string
+		mu       sync.Mutex
+		alg      Algorithm
+		limit    float64
+		inFlight int
+	}
+)
+
+// New returns a new Limiter, the limits are adapted by AIMD by default.
+func New(opts ...Option) *Limiter {
+	l := &Limiter{
+		newAlgorithm: func() Algorithm { return &AIMD{} },
+		initial:      defaultInitialLimit,
+		min:          defaultMinLimit,
+		max:          defaultMaxLimit,
+		methods:      make(map[string]*methodLimit),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// WithAlgorithm is an option to set the algorithm, a new Algorithm is created for each method.
+func WithAlgorithm(f func() Algorithm) Option {
+	return func(l *Limiter) {
+		l.newAlgorithm = f
+	}
+}
+
+// Limits is an option to set the initial, minimum and maximum limits, zero values are ignored.
+func Limits(initial, min, max int) Option {
+	return func(l *Limiter) {
+		if initial > 0 {
+			l.initial = initial
+		}
+		if min > 0 {
+			l.min = min
+		}
+		if max > 0 {
+			l.max = max
+		}
+	}
+}
+
+// Acquire reserves a slot of the method for a request of the given criticality.
+// If it is not rejected, release must be called when the request finishes,
+// reporting whether it was dropped because of the load.
+func (l *Limiter) Acquire(method string, c Criticality) (release func(dropped bool), ok bool) {
+	m, inFlight, ok := l.acquire(method, c)
+	if !ok {
+		return nil, false
+	}
+	start := time.Now()
+	var once sync.Once
+	return func(dropped bool) {
+		once.Do(func() {
+			m.release(l, &Sample{RTT: time.Since(start), InFlight: inFlight, Dropped: dropped})
+		})
+	}, true
+}
+
+// AcquireStream reserves a slot of the method for a stream of the given criticality.
+// If it is not rejected, release must be called when the stream finishes. The streams count as in-flight
+// requests but do not adapt the limit, their lifetime is not the latency of the server.
+func (l *Limiter) AcquireStream(method string, c Criticality) (release func(), ok bool) {
+	m, _, ok := l.acquire(method, c)
+	if !ok {
+		return nil, false
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.release(l, nil)
+		})
+	}, true
+}
+
+func (l *Limiter) acquire(method string, c Criticality) (*methodLimit, int, bool) {
+	m := l.method(method)
+	m.mu.Lock()
+	if float64(m.inFlight) >= math.Ceil(m.limit*c.share()) {
+		m.mu.Unlock()
+		rejectedCounter.WithLabelValues(method, c.String()).Inc()
+		return nil, 0, false
+	}
+	m.inFlight++
+	inFlight := m.inFlight
+	m.mu.Unlock()
+	inFlightGauge.WithLabelValues(method).Inc()
+	return m, inFlight, true
+}
+
+// Limit returns the current limit of the method.
+func (l *Limiter) Limit(method string) int {
+	m := l.method(method)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return int(m.limit)
+}
+
+func (l *Limiter) method(name string) *methodLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m, ok := l.methods[name]
+	if !ok {
+		m = &methodLimit{name: name, alg: l.newAlgorithm(), limit: float64(l.initial)}
+		l.methods[name] = m
+		limitGauge.WithLabelValues(name).Set(m.limit)
+	}
+	return m
+}
+
+// release releases a slot of the method, the limit is updated by the sample if any.
+func (m *methodLimit) release(l *Limiter, s *Sample) {
+	m.mu.Lock()
+	m.inFlight--
+	if s != nil {
+		m.limit = math.Max(float64(l.min), math.Min(float64(l.max), m.alg.Update(m.limit, *s)))
+	}
+	limit := m.limit
+	m.mu.Unlock()
+	inFlightGauge.WithLabelValues(m.name).Dec()
+	limitGauge.WithLabelValues(m.name).Set(math.Floor(limit))
+}
+
+// ParseCriticality returns the criticality of the given name, unknown names are Critical.
+func ParseCriticality(name string) Criticality {
+	for i, n := range criticalityNames {
+		if strings.EqualFold(n, name) {
+			return Criticality(i)
+		}
+	}
+	return Critical
+}
+
+// String returns the name of the criticality.
+func (c Criticality) String() string {
+	if c < Sheddable || c > CriticalPlus {
+		return fmt.Sprintf("criticality(%d)", int(c))
+	}
+	return criticalityNames[c]
+}
+
+func (c Criticality) share() float64 {
+	if c < Sheddable || c > CriticalPlus {
+		return criticalityShares[Critical]
+	}
+	return criticalityShares[c]
+}
@@ -0,0 +1,173 @@
+package concurrency_test
+
+import (
+	"context"
+	"github.com/realHoangHai/awesome/internal/concurrency"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func TestAIMD(t *testing.T) {
+	a := &concurrency.AIMD{Timeout: 100 * time.Millisecond, BackoffRatio: 0.5}
+	cases := []struct {
+		name   string
+		sample concurrency.Sample
+		want   float64
+	}{
+		{name: "increase when used", sample: concurrency.Sample{RTT: time.Millisecond, InFlight: 5}, want: 11},
+		{name: "unchanged when not used", sample: concurrency.Sample{RTT: time.Millisecond, InFlight: 1}, want: 10},
+		{name: "back off when slow", sample: concurrency.Sample{RTT: time.Second, InFlight: 5}, want: 5},
+		{name: "back off when dropped", sample: concurrency.Sample{RTT: time.Millisecond, InFlight: 5, Dropped: true}, want: 5},
+	}
+	for _, c := range cases {
+		if got := a.Update(10, c.sample); got != c.want {
+			t.Errorf("%s: got limit=%v, want limit=%v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestGradient(t *testing.T) {
+	g := &concurrency.Gradient{}
+	limit := 10.0
+	for i := 0; i < 100; i++ {
+		limit = g.Update(limit, concurrency.Sample{RTT: 10 * time.Millisecond, InFlight: int(limit)})
+	}
+	grown := limit
+	if grown <= 10 {
+		t.Fatalf("got limit=%v, want limit grown under stable latency", grown)
+	}
+	for i := 0; i < 20; i++ {
+		limit = g.Update(limit, concurrency.Sample{RTT: 100 * time.Millisecond, InFlight: int(limit)})
+	}
+	if limit >= grown {
+		t.Fatalf("got limit=%v, want limit decreased from %v as latency increases", limit, grown)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := concurrency.New(concurrency.Limits(10, 1, 10))
+	var releases []func(bool)
+	for i := 0; i < 5; i++ {
+		release, ok := l.Acquire("/test.Service/Get", concurrency.Sheddable)
+		if !ok {
+			t.Fatalf("request %d: got rejected, want sheddable allowed under half of the limit", i)
+		}
+		releases = append(releases, release)
+	}
+	if _, ok := l.Acquire("/test.Service/Get", concurrency.Sheddable); ok {
+		t.Fatal("got allowed, want sheddable rejected over half of the limit")
+	}
+	for i := 0; i < 5; i++ {
+		release, ok := l.Acquire("/test.Service/Get", concurrency.CriticalPlus)
+		if !ok {
+			t.Fatalf("request %d: got rejected, want critical_plus allowed up to the limit", i)
+		}
+		releases = append(releases, release)
+	}
+	if _, ok := l.Acquire("/test.Service/Get", concurrency.CriticalPlus); ok {
+		t.Fatal("got allowed, want rejected over the limit")
+	}
+	// other methods have their own limits.
+	if _, ok := l.Acquire("/test.Service/List", concurrency.Critical); !ok {
+		t.Fatal("got rejected, want allowed by the limit of another method")
+	}
+	for _, release := range releases {
+		release(true)
+	}
+	if got := l.Limit("/test.Service/Get"); got >= 10 {
+		t.Fatalf("got limit=%d, want limit decreased after drops", got)
+	}
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	l := concurrency.New(concurrency.Limits(2, 2, 2))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}
+	block := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_, _ = l.UnaryInterceptor()(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			close(started)
+			<-block
+			return nil, nil
+		})
+	}()
+	<-started
+	defer close(block)
+
+	noop := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	sheddable := metadata.NewIncomingContext(context.Background(), metadata.Pairs(concurrency.CriticalityMD, "sheddable"))
+	if _, err := l.UnaryInterceptor()(sheddable, nil, info, noop); status.Code(err) != codes.Unavailable {
+		t.Fatalf("got err=%v, want code=%s for sheddable request", err, codes.Unavailable)
+	}
+	if _, err := l.UnaryInterceptor()(context.Background(), nil, info, noop); err != nil {
+		t.Fatalf("got err=%v, want critical request allowed", err)
+	}
+}
+
+func TestUnaryInterceptorPanic(t *testing.T) {
+	l := concurrency.New(concurrency.Limits(1, 1, 1))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}
+	func() {
+		defer func() { _ = recover() }()
+		_, _ = l.UnaryInterceptor()(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("handler panic")
+		})
+	}()
+	// the slot of the panicking request is released.
+	if _, err := l.UnaryInterceptor()(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}); err != nil {
+		t.Fatalf("got err=%v, want request allowed after a panic", err)
+	}
+}
+
+// stream is a grpc.ServerStream of a context.
+type stream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *stream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamInterceptor(t *testing.T) {
+	l := concurrency.New(concurrency.Limits(2, 1, 2))
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch"}
+	block := make(chan struct{})
+	started := make(chan struct{}, 2)
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			errs <- l.StreamInterceptor()(nil, &stream{ctx: context.Background()}, info, func(srv interface{}, ss grpc.ServerStream) error {
+				started <- struct{}{}
+				<-block
+				return status.Error(codes.DeadlineExceeded, "stream deadline exceeded")
+			})
+		}()
+		<-started
+	}
+	noop := func(srv interface{}, ss grpc.ServerStream) error {
+		return nil
+	}
+	if err := l.StreamInterceptor()(nil, &stream{ctx: context.Background()}, info, noop); status.Code(err) != codes.Unavailable {
+		t.Fatalf("got err=%v, want code=%s over the limit of in-flight streams", err, codes.Unavailable)
+	}
+	close(block)
+	for i := 0; i < 2; i++ {
+		<-errs
+	}
+	// the lifetime and the errors of the streams do not adapt the limit.
+	if got := l.Limit(info.FullMethod); got != 2 {
+		t.Fatalf("got limit=%d, want limit=2", got)
+	}
+	if err := l.StreamInterceptor()(nil, &stream{ctx: context.Background()}, info, noop); err != nil {
+		t.Fatalf("got err=%v, want stream allowed after the others finished", err)
+	}
+}
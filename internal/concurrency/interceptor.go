@@ -0,0 +1,57 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryInterceptor returns a grpc.UnaryServerInterceptor that rejects the requests exceeding the limit
+// with codes.Unavailable. Requests timing out are reported as dropped.
+func (l *Limiter) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		release, ok := l.Acquire(info.FullMethod, criticality(ctx))
+		if !ok {
+			return nil, errOverloaded
+		}
+		// released on panics too, the slot would be leaked otherwise.
+		defer func() { release(isDropped(ctx, err)) }()
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor returns a grpc.StreamServerInterceptor that rejects the streams exceeding the limit
+// with codes.Unavailable. The streams only count as in-flight requests, see AcquireStream.
+func (l *Limiter) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		release, ok := l.AcquireStream(info.FullMethod, criticality(ss.Context()))
+		if !ok {
+			return errOverloaded
+		}
+		defer release()
+		return handler(srv, ss)
+	}
+}
+
+var errOverloaded = status.Error(codes.Unavailable, "server is overloaded, retry later")
+
+func criticality(ctx context.Context) Criticality {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return Critical
+	}
+	if v := md.Get(CriticalityMD); len(v) > 0 {
+		return ParseCriticality(v[0])
+	}
+	return Critical
+}
+
+func isDropped(ctx context.Context, err error) bool {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return status.Code(err) == codes.DeadlineExceeded
+}
@@ -0,0 +1,22 @@
+// Package dispatch marks the HTTP requests dispatched to the gRPC server: gRPC on the HTTP port,
+// gRPC-Web and Connect, so that the HTTP interceptors leave them to the gRPC interceptors.
+package dispatch
+
+import (
+	"context"
+	"net/http"
+)
+
+type grpcKey struct{}
+
+// WithGRPC returns a copy of the request marked as dispatched to the gRPC server.
+// The requests are marked by the server before the HTTP interceptors, the mark can not be set by the clients.
+func WithGRPC(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), grpcKey{}, true))
+}
+
+// IsGRPC reports whether the request is marked as dispatched to the gRPC server.
+func IsGRPC(r *http.Request) bool {
+	v, _ := r.Context().Value(grpcKey{}).(bool)
+	return v
+}
@@ -56,6 +56,12 @@ type (
 		SetStatus(service string, status Status)
 	}
 
+	// CheckerAdder is an interface to add checkers of dependent services to a Server.
+	CheckerAdder interface {
+		// AddChecker adds a checker for the given service, it must be called before Init.
+		AddChecker(service string, checker Checker)
+	}
+
 	// Status is an alias of grpc_health_v1.HealthCheckResponse_ServingStatus
 	Status = grpc_health_v1.HealthCheckResponse_ServingStatus
 	// CheckRequest is an alias of grpc_health_v1.HealthCheckRequest
@@ -83,6 +89,7 @@ var (
 	// force HServer implements required interfaces.
 	_ Server       = &HServer{}
 	_ StatusSetter = &HServer{}
+	_ CheckerAdder = &HServer{}
 )
 
 func NewServer(m map[string]Checker, opts ...Option) *HServer {
@@ -113,6 +120,15 @@ func (hs *HServer) Register(srv *grpc.Server) {
 	grpc_health_v1.RegisterHealthServer(srv, hs)
 }
 
+// AddChecker adds a checker for the given service.
+// It must be called before Init.
+func (hs *HServer) AddChecker(service string, checker Checker) {
+	if hs.checkers == nil {
+		hs.checkers = make(map[string]Checker)
+	}
+	hs.checkers[service] = checker
+}
+
 // Init implements health.Server.
 func (hs *HServer) Init(status Status) error {
 	hs.server.SetServingStatus(OverallServiceName, status)
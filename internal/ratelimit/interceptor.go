@@ -0,0 +1,180 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"github.com/realHoangHai/awesome/internal/dispatch"
+	"github.com/realHoangHai/awesome/pkg/utils/response"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// headers of the rate limit, see https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers.
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+)
+
+const (
+	// LimitedMD is the metadata name of the token marking the gateway calls of the HTTP requests already limited,
+	// it names the rule applied to the request and only that rule is not applied again.
+	LimitedMD = "x-ratelimited"
+)
+
+type limitedKey struct{}
+
+// UnaryInterceptor returns a grpc.UnaryServerInterceptor that limits the requests,
+// rejected requests get codes.ResourceExhausted. The rate limit headers are sent as metadata.
+func (l *Limiter) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := l.allowGRPC(ctx, info.FullMethod, grpc.SetHeader); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor returns a grpc.StreamServerInterceptor that limits the streams when they are opened.
+func (l *Limiter) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := l.allowGRPC(ss.Context(), info.FullMethod, func(_ context.Context, md metadata.MD) error {
+			return ss.SetHeader(md)
+		}); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// HTTPInterceptor returns a HTTP interceptor that limits the requests by URL.Path,
+// rejected requests get 429 Too Many Requests.
+// Requests dispatched to the gRPC server are left to the gRPC interceptors, see dispatch.IsGRPC, and the gateway calls
+// of the requests limited here are not limited again by the same rule if Metadata is used as the gateway annotator.
+func (l *Limiter) HTTPInterceptor() func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if dispatch.IsGRPC(r) {
+				h.ServeHTTP(w, r)
+				return
+			}
+			p, ok := l.match(r.URL.Path)
+			if !ok {
+				h.ServeHTTP(w, r)
+				return
+			}
+			res, ok := l.allow(httpContext(r), p, r.URL.Path)
+			if !ok {
+				h.ServeHTTP(w, r)
+				return
+			}
+			for k, v := range headers(res) {
+				w.Header().Set(k, v)
+			}
+			if !res.Allowed {
+				response.Error(w, r, status.Error(codes.ResourceExhausted, "rate limit exceeded"))
+				return
+			}
+			h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), limitedKey{}, p.p)))
+		})
+	}
+}
+
+// Metadata returns the token of the given request as metadata if it has been limited by the HTTP interceptor,
+// so that its gateway call is not limited twice by the same rule. It is used as the gRPC gateway metadata annotator,
+// see runtime.WithMetadata.
+func (l *Limiter) Metadata(_ context.Context, r *http.Request) metadata.MD {
+	p, ok := r.Context().Value(limitedKey{}).(string)
+	if !ok || l.token == nil {
+		return nil
+	}
+	return metadata.Pairs(LimitedMD, l.sign(p))
+}
+
+// sign returns the token of the rule of the given pattern, i.e... the pattern and its HMAC.
+func (l *Limiter) sign(p string) string {
+	mac := hmac.New(sha256.New, l.token)
+	mac.Write([]byte(p))
+	return p + " " + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// limited reports whether the call is the gateway call of a HTTP request already limited by the rule of the given pattern.
+func (l *Limiter) limited(ctx context.Context, p string) bool {
+	if l.token == nil {
+		return false
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	token := []byte(l.sign(p))
+	for _, v := range md.Get(LimitedMD) {
+		if hmac.Equal([]byte(v), token) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Limiter) allowGRPC(ctx context.Context, method string, setHeader func(context.Context, metadata.MD) error) error {
+	p, ok := l.match(method)
+	if !ok || l.limited(ctx, p.p) {
+		return nil
+	}
+	res, ok := l.allow(ctx, p, method)
+	if !ok {
+		return nil
+	}
+	md := metadata.MD{}
+	for k, v := range headers(res) {
+		md.Set(k, v)
+	}
+	_ = setHeader(ctx, md)
+	if !res.Allowed {
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry after %s", res.RetryAfter)
+	}
+	return nil
+}
+
+// httpContext returns the context of the request with the peer and the headers as metadata,
+// so that the same KeyFunc applies to both gRPC and HTTP requests.
+func httpContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if host, port, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		p, _ := strconv.Atoi(port)
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(host), Port: p}})
+	}
+	md := metadata.MD{}
+	if v, ok := metadata.FromIncomingContext(ctx); ok {
+		md = v.Copy()
+	}
+	for k, v := range r.Header {
+		md.Append(k, v...)
+	}
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+func headers(res Result) map[string]string {
+	h := map[string]string{
+		HeaderLimit:     strconv.Itoa(res.Limit),
+		HeaderRemaining: strconv.Itoa(res.Remaining),
+		HeaderReset:     strconv.Itoa(seconds(res.Reset)),
+	}
+	if !res.Allowed {
+		h[HeaderRetryAfter] = strconv.Itoa(seconds(res.RetryAfter))
+	}
+	return h
+}
+
+// seconds rounds up the duration to seconds, so that clients retrying after it are allowed.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"github.com/realHoangHai/awesome/pkg/clientip"
+	"github.com/realHoangHai/awesome/pkg/jwt"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net"
+	"strings"
+)
+
+const (
+	// APIKeyMD is the metadata name of API keys, forwarded by the gateway from Api-Key header.
+	APIKeyMD = "api-key"
+)
+
+// KeyFunc returns the key of the client of a request, requests of the same key share the same limit.
+type KeyFunc func(ctx context.Context, method string) string
+
+// KeyByIP returns the IP address of the client, resolved behind the trusted proxies if any, or of the peer.
+func KeyByIP(ctx context.Context, _ string) string {
+	if ip, ok := clientip.FromContext(ctx); ok {
+		return ip.String()
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// KeyByMethod returns the method, all clients share the limit of the method.
+func KeyByMethod(_ context.Context, method string) string {
+	return method
+}
+
+// KeyByAPIKey returns the hash of the API key in the metadata, or the IP address of the peer if there is none.
+// The keys are hashed so that they are not stored as is in the names of the limits.
+func KeyByAPIKey(ctx context.Context, method string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(APIKeyMD); len(v) > 0 && v[0] != "" {
+			sum := sha256.Sum256([]byte(v[0]))
+			return "key:" + base64.RawURLEncoding.EncodeToString(sum[:16])
+		}
+	}
+	return KeyByIP(ctx, method)
+}
+
+// KeyBySubject returns the subject of the JWT claims authenticated by the server,
+// or the IP address of the peer if the request is not authenticated. The subject is only known to the gRPC
+// interceptors, HTTP requests are limited by IP address.
+func KeyBySubject(ctx context.Context, method string) string {
+	if c, ok := jwt.FromContext(ctx); ok && c.Subject != "" {
+		return "sub:" + c.Subject
+	}
+	return KeyByIP(ctx, method)
+}
+
+// ParseKey returns the KeyFunc of the given name for the rule of the given pattern: ip (default), method, api_key
+// or subject. Subjects are only authenticated by the gRPC interceptors, so subject keys are rejected unless
+// the pattern only matches gRPC methods, i.e... /helloworld.Greeter/*.
+func ParseKey(p, name string) (KeyFunc, error) {
+	switch strings.ToLower(name) {
+	case "", "ip":
+		return KeyByIP, nil
+	case "method":
+		return KeyByMethod, nil
+	case "api_key":
+		return KeyByAPIKey, nil
+	case "subject":
+		if !isMethodPattern(p) {
+			return nil, fmt.Errorf("%w: subject key of %q, HTTP paths are not authenticated", ErrInvalidRule, p)
+		}
+		return KeyBySubject, nil
+	}
+	return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidRule, name)
+}
+
+// isMethodPattern reports whether the pattern names a gRPC service, i.e... /helloworld.Greeter/SayHello.
+func isMethodPattern(p string) bool {
+	parts := strings.SplitN(p, "/", 3)
+	return len(parts) == 3 && parts[0] == "" && strings.Contains(parts[1], ".") && !strings.Contains(parts[1], "*")
+}
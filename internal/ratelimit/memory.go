@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the interval of removing the states of idle clients.
+const sweepInterval = time.Minute
+
+type (
+	// MemoryStore keeps the limits in memory, the limits are not shared between instances of the server.
+	MemoryStore struct {
+		mu        sync.Mutex
+		states    map[string]*state
+		lastSweep time.Time
+	}
+
+	// state is the state of a key, tokens and last refill of TokenBucket,
+	// or the counts of the current and previous window of SlidingWindow.
+	state struct {
+		tokens  float64
+		last    time.Time
+		window  int64
+		current int
+		prev    int
+		// expire is the time the state is the same as the initial state.
+		expire time.Time
+	}
+)
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore returns a new MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		states: make(map[string]*state),
+	}
+}
+
+// Allow implements Store.
+func (s *MemoryStore) Allow(_ context.Context, key string, rule Rule, now time.Time) (Result, error) {
+	if err := rule.validate(); err != nil {
+		return Result{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	st, ok := s.states[key]
+	if !ok {
+		st = &state{tokens: float64(rule.Burst), last: now}
+		s.states[key] = st
+	}
+	if rule.Algorithm == SlidingWindow {
+		return st.slidingWindow(rule, now), nil
+	}
+	return st.tokenBucket(rule, now), nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for k, st := range s.states {
+		if now.After(st.expire) {
+			delete(s.states, k)
+		}
+	}
+}
+
+func (st *state) tokenBucket(rule Rule, now time.Time) Result {
+	// tokens per nanosecond.
+	rate := float64(rule.Limit) / float64(rule.Period)
+	if now.After(st.last) {
+		st.tokens = math.Min(float64(rule.Burst), st.tokens+float64(now.Sub(st.last))*rate)
+		st.last = now
+	}
+	res := Result{Limit: rule.Burst}
+	if st.tokens >= 1 {
+		st.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((1 - st.tokens) / rate))
+	}
+	res.Remaining = int(st.tokens)
+	res.Reset = time.Duration(math.Ceil((float64(rule.Burst) - st.tokens) / rate))
+	st.expire = now.Add(res.Reset)
+	return res
+}
+
+func (st *state) slidingWindow(rule Rule, now time.Time) Result {
+	window := now.UnixNano() / int64(rule.Period)
+	switch window {
+	case st.window:
+	case st.window + 1:
+		st.prev, st.current = st.current, 0
+	default:
+		st.prev, st.current = 0, 0
+	}
+	st.window = window
+	elapsed := time.Duration(now.UnixNano() - window*int64(rule.Period))
+	weight := 1 - float64(elapsed)/float64(rule.Period)
+	res := Result{Limit: rule.Limit, Reset: rule.Period - elapsed}
+	if float64(st.prev)*weight+float64(st.current+1) <= float64(rule.Limit) {
+		st.current++
+		res.Allowed = true
+	} else {
+		res.RetryAfter = slidingWindowRetryAfter(rule, st.prev, st.current, elapsed)
+	}
+	res.Remaining = rule.Limit - int(math.Ceil(float64(st.prev)*weight+float64(st.current)))
+	if res.Remaining < 0 {
+		res.Remaining = 0
+	}
+	st.expire = now.Add(2*rule.Period - elapsed)
+	return res
+}
+
+// slidingWindowRetryAfter returns the time until the weighted count of the previous window
+// decreases enough to allow one more request, or the start of the next window.
+func slidingWindowRetryAfter(rule Rule, prev, current int, elapsed time.Duration) time.Duration {
+	next := rule.Period - elapsed
+	if prev == 0 || current+1 > rule.Limit {
+		return next
+	}
+	// prev*(1-t/period) + current + 1 <= limit
+	t := time.Duration(math.Ceil(float64(rule.Period)*(1-float64(rule.Limit-current-1)/float64(prev)))) - elapsed
+	if t < 0 || t > next {
+		return next
+	}
+	return t
+}
@@ -0,0 +1,188 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/realHoangHai/awesome/pkg/log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// TokenBucket allows bursts up to Burst requests, refilled at Limit requests per Period.
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow allows Limit requests in any Period, approximated by weighting the previous window.
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+var (
+	// ErrInvalidRule reports that a rule can not be used for limiting requests.
+	ErrInvalidRule = errors.New("ratelimit: invalid rule")
+)
+
+type (
+	// Algorithm is the algorithm of a rate limit rule.
+	Algorithm string
+
+	// Rule limits the requests of the methods matching its pattern.
+	Rule struct {
+		// Algorithm is TokenBucket by default.
+		Algorithm Algorithm
+		// Limit is the number of requests allowed per Period.
+		Limit  int
+		Period time.Duration
+		// Burst is the capacity of the token bucket, default is Limit.
+		Burst int
+		// Key identifies the clients sharing the same limit, default is KeyByIP.
+		Key KeyFunc
+	}
+
+	// Result is the result of limiting a request.
+	Result struct {
+		Allowed   bool
+		Limit     int
+		Remaining int
+		// Reset is the time until the limit is fully restored.
+		Reset time.Duration
+		// RetryAfter is the time until the next request is allowed, zero if the request is allowed.
+		RetryAfter time.Duration
+	}
+
+	// Store keeps the state of the limits, it must apply the rule atomically
+	// as it can be shared by multiple instances of the server.
+	Store interface {
+		Allow(ctx context.Context, key string, rule Rule, now time.Time) (Result, error)
+	}
+
+	// Limiter limits the requests of the methods using the rules matching them.
+	// For gRPC requests the method is the full method, i.e... /helloworld.Greeter/SayHello,
+	// for HTTP requests it is URL.Path.
+	Limiter struct {
+		store Store
+		rules []pattern
+		log   log.Logger
+		now   func() time.Time
+		// token marks the gateway calls of the HTTP requests already limited, it never leaves the process.
+		token []byte
+	}
+
+	// Option is a configuration option of Limiter.
+	Option func(*Limiter)
+
+	pattern struct {
+		p    string
+		rule Rule
+	}
+)
+
+// New returns a new Limiter storing the limits in the given store.
+func New(store Store, opts ...Option) *Limiter {
+	l := &Limiter{
+		store: store,
+		now:   time.Now,
+		token: make([]byte, 32),
+	}
+	// without a token the gateway calls are limited twice rather than not at all.
+	if _, err := rand.Read(l.token); err != nil {
+		l.token = nil
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.log == nil {
+		l.log = log.Root()
+	}
+	return l
+}
+
+// WithRule is an option to limit the methods matching the given pattern.
+// The pattern is either an exact method or a prefix ending with *, i.e... /helloworld.Greeter/*,
+// the exact pattern or else the longest prefix matching a method is used.
+func WithRule(p string, rule Rule) Option {
+	return func(l *Limiter) {
+		if rule.Algorithm == "" {
+			rule.Algorithm = TokenBucket
+		}
+		if rule.Burst <= 0 {
+			rule.Burst = rule.Limit
+		}
+		if rule.Key == nil {
+			rule.Key = KeyByIP
+		}
+		l.rules = append(l.rules, pattern{p: p, rule: rule})
+		// exact patterns first, then the longest prefixes.
+		sort.SliceStable(l.rules, func(i, j int) bool {
+			pi, pj := strings.HasSuffix(l.rules[i].p, "*"), strings.HasSuffix(l.rules[j].p, "*")
+			if pi != pj {
+				return !pi
+			}
+			return len(l.rules[i].p) > len(l.rules[j].p)
+		})
+	}
+}
+
+// Logger is an option to set logger of Limiter.
+func Logger(logger log.Logger) Option {
+	return func(l *Limiter) {
+		l.log = logger
+	}
+}
+
+// Clock is an option to set the clock of Limiter, mostly used in tests.
+func Clock(now func() time.Time) Option {
+	return func(l *Limiter) {
+		l.now = now
+	}
+}
+
+// Allow applies the rule matching the method to the request.
+// The request is allowed if no rule matches, or the store fails, so that an unavailable store
+// does not take the server down.
+func (l *Limiter) Allow(ctx context.Context, method string) (Result, bool) {
+	p, ok := l.match(method)
+	if !ok {
+		return Result{Allowed: true}, false
+	}
+	return l.allow(ctx, p, method)
+}
+
+func (l *Limiter) allow(ctx context.Context, p pattern, method string) (Result, bool) {
+	key := p.p + "|" + p.rule.Key(ctx, method)
+	res, err := l.store.Allow(ctx, key, p.rule, l.now())
+	if err != nil {
+		l.log.Context(ctx).Errorf("ratelimit: apply rule %s, allow request, err: %v", p.p, err)
+		return Result{Allowed: true}, false
+	}
+	return res, true
+}
+
+func (l *Limiter) match(method string) (pattern, bool) {
+	for _, p := range l.rules {
+		if p.p == method || (strings.HasSuffix(p.p, "*") && strings.HasPrefix(method, strings.TrimSuffix(p.p, "*"))) {
+			return p, true
+		}
+	}
+	return pattern{}, false
+}
+
+// ParseAlgorithm returns the algorithm of the given name, empty name is TokenBucket.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch a := Algorithm(strings.ToLower(name)); a {
+	case "":
+		return TokenBucket, nil
+	case TokenBucket, SlidingWindow:
+		return a, nil
+	}
+	return "", fmt.Errorf("%w: unknown algorithm %q", ErrInvalidRule, name)
+}
+
+// validate reports whether the rule can be applied by a store.
+func (r Rule) validate() error {
+	if r.Limit <= 0 || r.Period <= 0 {
+		return fmt.Errorf("%w: limit and period must be positive", ErrInvalidRule)
+	}
+	return nil
+}
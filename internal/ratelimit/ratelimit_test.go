@@ -0,0 +1,264 @@
+package ratelimit_test
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/realHoangHai/awesome/internal/dispatch"
+	"github.com/realHoangHai/awesome/internal/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clock is a fake clock advanced manually.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func TestLimiter(t *testing.T) {
+	stores := map[string]func(t *testing.T) ratelimit.Store{
+		"memory": func(t *testing.T) ratelimit.Store {
+			return ratelimit.NewMemoryStore()
+		},
+		"miniredis": func(t *testing.T) ratelimit.Store {
+			client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+			t.Cleanup(func() { client.Close() })
+			return ratelimit.NewRedisStore(client, "ratelimit_test:")
+		},
+		"redis": func(t *testing.T) ratelimit.Store {
+			addr := os.Getenv("REDIS_ADDR")
+			if addr == "" {
+				t.Skip("REDIS_ADDR is not set")
+			}
+			client := redis.NewClient(&redis.Options{Addr: addr})
+			t.Cleanup(func() { client.Close() })
+			return ratelimit.NewRedisStore(client, "ratelimit_test:"+strconv.FormatInt(time.Now().UnixNano(), 10)+":")
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			t.Run("token bucket", func(t *testing.T) {
+				c := &clock{now: time.Unix(1000, 0)}
+				l := ratelimit.New(newStore(t), ratelimit.Clock(c.Now),
+					ratelimit.WithRule("/test.Service/*", ratelimit.Rule{Limit: 2, Period: time.Second, Burst: 3}))
+				for i := 0; i < 3; i++ {
+					if res, _ := l.Allow(context.Background(), "/test.Service/Get"); !res.Allowed || res.Remaining != 2-i {
+						t.Fatalf("request %d: got %+v, want allowed with remaining=%d", i, res, 2-i)
+					}
+				}
+				res, _ := l.Allow(context.Background(), "/test.Service/Get")
+				if res.Allowed || res.RetryAfter != 500*time.Millisecond {
+					t.Fatalf("got %+v, want denied with retry_after=500ms", res)
+				}
+				c.now = c.now.Add(500 * time.Millisecond)
+				if res, _ := l.Allow(context.Background(), "/test.Service/Get"); !res.Allowed {
+					t.Fatalf("got %+v, want allowed after refill", res)
+				}
+			})
+			t.Run("sliding window", func(t *testing.T) {
+				c := &clock{now: time.Unix(2000, 0)}
+				l := ratelimit.New(newStore(t), ratelimit.Clock(c.Now),
+					ratelimit.WithRule("/test.Service/Get", ratelimit.Rule{Algorithm: ratelimit.SlidingWindow, Limit: 4, Period: time.Second}))
+				for i := 0; i < 4; i++ {
+					if res, _ := l.Allow(context.Background(), "/test.Service/Get"); !res.Allowed {
+						t.Fatalf("request %d: got %+v, want allowed", i, res)
+					}
+				}
+				if res, _ := l.Allow(context.Background(), "/test.Service/Get"); res.Allowed || res.RetryAfter != time.Second {
+					t.Fatalf("got %+v, want denied until next window", res)
+				}
+				// previous window weights 3 out of 4 requests.
+				c.now = c.now.Add(1250 * time.Millisecond)
+				if res, _ := l.Allow(context.Background(), "/test.Service/Get"); !res.Allowed || res.Remaining != 0 {
+					t.Fatalf("got %+v, want allowed with remaining=0", res)
+				}
+				if res, _ := l.Allow(context.Background(), "/test.Service/Get"); res.Allowed || res.RetryAfter != 250*time.Millisecond {
+					t.Fatalf("got %+v, want denied with retry_after=250ms", res)
+				}
+			})
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	l := ratelimit.New(ratelimit.NewMemoryStore(),
+		ratelimit.WithRule("/test.Service/*", ratelimit.Rule{Limit: 1, Period: time.Hour, Key: ratelimit.KeyByAPIKey}),
+		ratelimit.WithRule("/test.Service/Get", ratelimit.Rule{Limit: 2, Period: time.Hour, Key: ratelimit.KeyByAPIKey}),
+	)
+	withKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(ratelimit.APIKeyMD, key))
+	}
+	cases := []struct {
+		method  string
+		key     string
+		allowed bool
+		limit   int
+	}{
+		{method: "/test.Service/Get", key: "a", allowed: true, limit: 2},
+		{method: "/test.Service/Get", key: "a", allowed: true, limit: 2},
+		{method: "/test.Service/Get", key: "a", allowed: false, limit: 2},
+		{method: "/test.Service/Get", key: "b", allowed: true, limit: 2},
+		{method: "/test.Service/List", key: "a", allowed: true, limit: 1},
+		{method: "/test.Service/List", key: "a", allowed: false, limit: 1},
+		{method: "/other.Service/Get", key: "a", allowed: true},
+	}
+	for i, c := range cases {
+		res, _ := l.Allow(withKey(c.key), c.method)
+		if res.Allowed != c.allowed || res.Limit != c.limit {
+			t.Fatalf("case %d %s: got %+v, want allowed=%v, limit=%d", i, c.method, res, c.allowed, c.limit)
+		}
+	}
+	// the API keys are not stored as is.
+	if key := ratelimit.KeyByAPIKey(withKey("secret"), "/test.Service/Get"); strings.Contains(key, "secret") {
+		t.Errorf("got key=%s, want the hash of the API key", key)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	cases := []struct {
+		pattern string
+		key     string
+		valid   bool
+	}{
+		{pattern: "/api/*", key: "ip", valid: true},
+		{pattern: "/test.Service/*", key: "subject", valid: true},
+		{pattern: "/test.Service/Get", key: "subject", valid: true},
+		// the subject is not authenticated for HTTP paths.
+		{pattern: "/api/*", key: "subject"},
+		{pattern: "*", key: "subject"},
+		{pattern: "/test.*", key: "subject"},
+		{pattern: "/api/*", key: "unknown"},
+	}
+	for _, c := range cases {
+		if _, err := ratelimit.ParseKey(c.pattern, c.key); (err == nil) != c.valid {
+			t.Errorf("pattern %s, key %s: got err=%v, want valid=%v", c.pattern, c.key, err, c.valid)
+		}
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	l := ratelimit.New(ratelimit.NewMemoryStore(),
+		ratelimit.WithRule("*", ratelimit.Rule{Limit: 1, Period: time.Minute}))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}
+	if _, err := l.UnaryInterceptor()(context.Background(), nil, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.UnaryInterceptor()(context.Background(), nil, info, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("got err=%v, want code=%s", err, codes.ResourceExhausted)
+	}
+
+	h := l.HTTPInterceptor()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/items", nil))
+		if w.Code != want {
+			t.Fatalf("request %d: got status_code=%d, want status_code=%d", i, w.Code, want)
+		}
+		if w.Header().Get(ratelimit.HeaderLimit) != "1" {
+			t.Fatalf("request %d: got headers=%v, want %s=1", i, w.Header(), ratelimit.HeaderLimit)
+		}
+		if want == http.StatusTooManyRequests && w.Header().Get(ratelimit.HeaderRetryAfter) != "60" {
+			t.Fatalf("got headers=%v, want %s=60", w.Header(), ratelimit.HeaderRetryAfter)
+		}
+	}
+}
+
+func TestGatewayCalls(t *testing.T) {
+	l := ratelimit.New(ratelimit.NewMemoryStore(),
+		ratelimit.WithRule("*", ratelimit.Rule{Limit: 1, Period: time.Minute}))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}
+
+	// the gateway call of the limited HTTP request is not limited again.
+	var md metadata.MD
+	h := l.HTTPInterceptor()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		md = l.Metadata(r.Context(), r)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/items", nil))
+	if len(md.Get(ratelimit.LimitedMD)) != 1 {
+		t.Fatalf("got metadata=%v, want %s", md, ratelimit.LimitedMD)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := l.UnaryInterceptor()(metadata.NewIncomingContext(context.Background(), md), nil, info, handler); err != nil {
+			t.Fatalf("call %d: got err=%v, want the gateway call not limited", i, err)
+		}
+	}
+
+	// forged tokens and requests not limited by HTTP are limited.
+	if md := l.Metadata(context.Background(), httptest.NewRequest(http.MethodGet, "/v1/items", nil)); md != nil {
+		t.Fatalf("got metadata=%v of a request not limited, want none", md)
+	}
+	forged := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ratelimit.LimitedMD, "Zm9yZ2Vk"))
+	for i, want := range []codes.Code{codes.OK, codes.ResourceExhausted} {
+		if _, err := l.UnaryInterceptor()(forged, nil, info, handler); status.Code(err) != want {
+			t.Fatalf("call %d: got err=%v, want code=%s", i, err, want)
+		}
+	}
+}
+
+func TestGatewayCallsOtherRules(t *testing.T) {
+	l := ratelimit.New(ratelimit.NewMemoryStore(),
+		ratelimit.WithRule("/v1/*", ratelimit.Rule{Limit: 1, Period: time.Minute}),
+		ratelimit.WithRule("/test.Service/*", ratelimit.Rule{Limit: 1, Period: time.Minute}))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}
+
+	var md metadata.MD
+	h := l.HTTPInterceptor()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		md = l.Metadata(r.Context(), r)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/items", nil))
+	if len(md.Get(ratelimit.LimitedMD)) != 1 {
+		t.Fatalf("got metadata=%v, want %s", md, ratelimit.LimitedMD)
+	}
+	// the gateway call skips the rule of the path only, the rule of its method still applies.
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	for i, want := range []codes.Code{codes.OK, codes.ResourceExhausted} {
+		if _, err := l.UnaryInterceptor()(ctx, nil, info, handler); status.Code(err) != want {
+			t.Fatalf("call %d: got err=%v, want code=%s", i, err, want)
+		}
+	}
+}
+
+func TestHTTPInterceptorGRPCRequests(t *testing.T) {
+	l := ratelimit.New(ratelimit.NewMemoryStore(),
+		ratelimit.WithRule("*", ratelimit.Rule{Limit: 1, Period: time.Minute}))
+	h := l.HTTPInterceptor()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// the gRPC headers of requests not dispatched to the gRPC server are not trusted.
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		r := httptest.NewRequest(http.MethodPost, "/v1/items", nil)
+		r.Header.Set("Content-Type", "application/grpc")
+		r.Header.Set("Connect-Protocol-Version", "1")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != want {
+			t.Fatalf("request %d: got status_code=%d, want status_code=%d", i, w.Code, want)
+		}
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, dispatch.WithGRPC(httptest.NewRequest(http.MethodPost, "/test.Service/Get", nil)))
+	if w.Code != http.StatusOK || w.Header().Get(ratelimit.HeaderLimit) != "" {
+		t.Fatalf("got status_code=%d, headers=%v, want the gRPC request left to the gRPC interceptors", w.Code, w.Header())
+	}
+}
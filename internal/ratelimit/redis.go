@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+var (
+	// tokenBucketScript is the same as state.tokenBucket, in milliseconds.
+	tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1]) / tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local now = tonumber(ARGV[4])
+local s = redis.call('HMGET', KEYS[1], 't', 'ts')
+local tokens = tonumber(s[1]) or burst
+local last = tonumber(s[2]) or now
+if now > last then
+	tokens = math.min(burst, tokens + (now - last) * rate)
+	last = now
+end
+local allowed, retry = 0, 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+local reset = math.ceil((burst - tokens) / rate)
+redis.call('HMSET', KEYS[1], 't', tostring(tokens), 'ts', tostring(last))
+redis.call('PEXPIRE', KEYS[1], reset + 1000)
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+	// slidingWindowScript is the same as state.slidingWindow, in milliseconds.
+	slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local window = math.floor(now / period)
+local s = redis.call('HMGET', KEYS[1], 'w', 'c', 'p')
+local w = tonumber(s[1]) or 0
+local current = tonumber(s[2]) or 0
+local prev = tonumber(s[3]) or 0
+if window == w + 1 then
+	prev, current = current, 0
+elseif window ~= w then
+	prev, current = 0, 0
+end
+local elapsed = now - window * period
+local weight = 1 - elapsed / period
+local allowed, retry = 0, 0
+if prev * weight + current + 1 <= limit then
+	current = current + 1
+	allowed = 1
+elseif prev == 0 or current + 1 > limit then
+	retry = period - elapsed
+else
+	retry = math.ceil(period * (1 - (limit - current - 1) / prev)) - elapsed
+	if retry < 0 or retry > period - elapsed then
+		retry = period - elapsed
+	end
+end
+redis.call('HMSET', KEYS[1], 'w', window, 'c', current, 'p', prev)
+redis.call('PEXPIRE', KEYS[1], 2 * period - elapsed)
+return {allowed, math.max(0, limit - math.ceil(prev * weight + current)), retry, period - elapsed}
+`)
+)
+
+// RedisStore keeps the limits in Redis, the limits are shared between instances of the server
+// using the same Redis. The rules are applied by Lua scripts using the clock of the server,
+// the clocks of the instances should be synchronized.
+type RedisStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+var _ Store = &RedisStore{}
+
+// NewRedisStore returns a new RedisStore, the keys are prefixed with the given prefix.
+func NewRedisStore(client redis.Cmdable, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Allow implements Store.
+func (s *RedisStore) Allow(ctx context.Context, key string, rule Rule, now time.Time) (Result, error) {
+	if err := rule.validate(); err != nil {
+		return Result{}, err
+	}
+	period := rule.Period.Milliseconds()
+	if period <= 0 {
+		return Result{}, fmt.Errorf("%w: period must be at least 1ms", ErrInvalidRule)
+	}
+	ms := now.UnixNano() / int64(time.Millisecond)
+	var v []int64
+	var err error
+	if rule.Algorithm == SlidingWindow {
+		v, err = slidingWindowScript.Run(ctx, s.client, []string{s.prefix + key}, rule.Limit, period, ms).Int64Slice()
+	} else {
+		v, err = tokenBucketScript.Run(ctx, s.client, []string{s.prefix + key}, rule.Limit, period, rule.Burst, ms).Int64Slice()
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	if len(v) != 4 {
+		return Result{}, fmt.Errorf("ratelimit: unexpected script result %v", v)
+	}
+	limit := rule.Burst
+	if rule.Algorithm == SlidingWindow {
+		limit = rule.Limit
+	}
+	return Result{
+		Allowed:    v[0] == 1,
+		Limit:      limit,
+		Remaining:  int(v[1]),
+		RetryAfter: time.Duration(v[2]) * time.Millisecond,
+		Reset:      time.Duration(v[3]) * time.Millisecond,
+	}, nil
+}
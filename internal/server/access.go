@@ -0,0 +1,17 @@
+package server
+
+// ReloadAccessRules replaces the rules of the AccessControl by the ones of the AccessRulesSource.
+// The current rules are kept if the new ones are invalid, the error is returned.
+func (s *Server) ReloadAccessRules() error {
+	if s.accessControl == nil || s.accessRules == nil {
+		return nil
+	}
+	rules, err := s.accessRules()
+	if err != nil {
+		s.getLogger().Errorf("server: reload access rules, err: %v", err)
+		return err
+	}
+	s.accessControl.SetRules(rules)
+	s.getLogger().Infof("server: %d access rules loaded", len(rules))
+	return nil
+}
@@ -0,0 +1,79 @@
+package server_test
+
+import (
+	"context"
+	"github.com/realHoangHai/awesome/config"
+	"github.com/realHoangHai/awesome/internal/access"
+	"github.com/realHoangHai/awesome/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessControl(t *testing.T) {
+	deny, err := access.ParseRule([]string{"^/internal/", "^/test.Echo/"}, nil, []string{"loopback"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := []access.Rule{deny}
+	srv := startServer(t, []server.Service{&echoService{}},
+		server.AccessControl(access.New(rules)),
+		server.AccessRulesSource(func() ([]access.Rule, error) {
+			return rules, nil
+		}),
+		server.HandlerFunc("/internal/ping", func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	get := func() int {
+		resp, err := http.Get("http://" + srv.addr + "/internal/ping")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	conn, err := grpc.Dial(srv.addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	echo := func() codes.Code {
+		return status.Code(conn.Invoke(context.Background(), "/test.Echo/Echo", wrapperspb.String("hello"), &wrapperspb.StringValue{}))
+	}
+
+	if got := get(); got != http.StatusForbidden {
+		t.Errorf("got status=%d, want status=%d", got, http.StatusForbidden)
+	}
+	if got := echo(); got != codes.PermissionDenied {
+		t.Errorf("got code=%v, want code=%v", got, codes.PermissionDenied)
+	}
+
+	rules = nil
+	if err := srv.ReloadAccessRules(); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(); got != http.StatusOK {
+		t.Errorf("got status=%d after reload, want status=%d", got, http.StatusOK)
+	}
+	if got := echo(); got != codes.OK {
+		t.Errorf("got code=%v after reload, want code=%v", got, codes.OK)
+	}
+}
+
+func TestAccessControlInvalidRules(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Access.Enabled = true
+	cfg.Access.Rules = []config.AccessRule{{Methods: []string{"^/internal/("}, Deny: []string{"loopback"}}}
+	srv := server.New(server.FromConfig(cfg), server.Address("127.0.0.1:0"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// the server refuses to start rather than leaving the methods of the invalid rule open.
+	if err := srv.RunWithContext(ctx); err == nil || !strings.Contains(err.Error(), "access control") {
+		t.Fatalf("got err=%v, want the error of the invalid rule", err)
+	}
+}
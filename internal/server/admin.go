@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"github.com/realHoangHai/awesome/internal/auth"
+	"google.golang.org/grpc"
+	channelzgrpc "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	channelz "google.golang.org/grpc/channelz/service"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// adminMethodPrefixes are the prefixes of the gRPC methods of the admin services.
+var adminMethodPrefixes = []string{
+	"/grpc.reflection.",
+	"/grpc.channelz.",
+	"/" + adminControlPackage + ".",
+}
+
+type (
+	// adminAuthenticator authenticates the calls to the admin services by the admin authenticator,
+	// and the other calls by the server authenticator if any.
+	adminAuthenticator struct {
+		admin auth.Authenticator
+		next  auth.Authenticator
+	}
+
+	// serviceCapturer is a grpc.ServiceRegistrar capturing the registered implementation.
+	serviceCapturer struct {
+		impl interface{}
+	}
+)
+
+var _ auth.WhiteListAuthenticator = adminAuthenticator{}
+
+// Authenticate implements auth.Authenticator.
+func (a adminAuthenticator) Authenticate(ctx context.Context) (context.Context, error) {
+	if method, ok := grpc.Method(ctx); ok && isAdminMethod(method) {
+		return a.admin.Authenticate(ctx)
+	}
+	if a.next == nil {
+		return ctx, nil
+	}
+	return a.next.Authenticate(ctx)
+}
+
+// IsWhiteListed implements auth.WhiteListAuthenticator, admin methods are never white listed.
+func (a adminAuthenticator) IsWhiteListed(path string) bool {
+	if isAdminMethod(path) {
+		return false
+	}
+	if a.next == nil {
+		return true
+	}
+	wl, ok := a.next.(auth.WhiteListAuthenticator)
+	return ok && wl.IsWhiteListed(path)
+}
+
+// checkAdminAuth returns an error if reflection or channelz is enabled without AdminAuth,
+// they would expose the APIs and the internals of the server to anyone otherwise.
+func (s *Server) checkAdminAuth() error {
+	if s.adminAuth == nil && (s.reflection || s.channelz != nil) {
+		return errors.New("server: reflection and channelz require AdminAuth")
+	}
+	return nil
+}
+
+func isAdminMethod(method string) bool {
+	for _, p := range adminMethodPrefixes {
+		if strings.HasPrefix(method, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterService implements grpc.ServiceRegistrar.
+func (c *serviceCapturer) RegisterService(_ *grpc.ServiceDesc, impl interface{}) {
+	c.impl = impl
+}
+
+// channelzService registers the channelz service, and serves its data as JSON over HTTP:
+//   - {prefix}/servers and {prefix}/servers/{id}
+//   - {prefix}/channels and {prefix}/channels/{id}
+//   - {prefix}/subchannels/{id}
+//   - {prefix}/sockets/{id}
+type channelzService struct {
+	prefix string
+	srv    channelzgrpc.ChannelzServer
+}
+
+func newChannelzService(prefix string) *channelzService {
+	c := &serviceCapturer{}
+	channelz.RegisterChannelzServiceToServer(c)
+	return &channelzService{
+		prefix: prefix,
+		srv:    c.impl.(channelzgrpc.ChannelzServer),
+	}
+}
+
+// Register implements Service.
+func (s *channelzService) Register(srv *grpc.Server) {
+	channelzgrpc.RegisterChannelzServer(srv, s.srv)
+}
+
+// ServeHTTP serves the channelz data as JSON.
+func (s *channelzService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, s.prefix), "/"), "/")
+	var id int64
+	if len(parts) == 2 {
+		v, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			http.Error(w, "invalid id", http.StatusBadRequest)
+			return
+		}
+		id = v
+	}
+	ctx := r.Context()
+	var resp proto.Message
+	var err error
+	switch {
+	case parts[0] == "servers" && len(parts) == 1:
+		resp, err = s.srv.GetServers(ctx, &channelzgrpc.GetServersRequest{})
+	case parts[0] == "servers" && len(parts) == 2:
+		resp, err = s.srv.GetServer(ctx, &channelzgrpc.GetServerRequest{ServerId: id})
+	case parts[0] == "channels" && len(parts) == 1:
+		resp, err = s.srv.GetTopChannels(ctx, &channelzgrpc.GetTopChannelsRequest{})
+	case parts[0] == "channels" && len(parts) == 2:
+		resp, err = s.srv.GetChannel(ctx, &channelzgrpc.GetChannelRequest{ChannelId: id})
+	case parts[0] == "subchannels" && len(parts) == 2:
+		resp, err = s.srv.GetSubchannel(ctx, &channelzgrpc.GetSubchannelRequest{SubchannelId: id})
+	case parts[0] == "sockets" && len(parts) == 2:
+		resp, err = s.srv.GetSocket(ctx, &channelzgrpc.GetSocketRequest{SocketId: id})
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	b, err := protojson.MarshalOptions{Indent: "  ", UseProtoNames: true}.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// serveAdmin serves the admin router on the admin endpoint in the background.
+func (s *Server) serveAdmin(ep *endpoint, router http.Handler, errChan chan<- error) error {
+	var tlsCfg *tls.Config
+	if ep.isSecured() {
+		src, err := s.certSource(ep)
+		if err != nil {
+			return err
+		}
+		if tlsCfg, err = s.serverTLSConfig(src); err != nil {
+			return err
+		}
+	}
+	s.adminSrv = &http.Server{
+		Addr:         ep.address,
+		Handler:      router,
+		ReadTimeout:  ep.readTimeout,
+		WriteTimeout: ep.writeTimeout,
+		TLSConfig:    tlsCfg,
+	}
+	go func() {
+		if tlsCfg != nil {
+			errChan <- s.adminSrv.ServeTLS(ep.lis, "", "")
+			return
+		}
+		errChan <- s.adminSrv.Serve(ep.lis)
+	}()
+	return nil
+}
+
+// splitInternalRoutes splits the routes into the public and the internal ones.
+func splitInternalRoutes(routes []HandlerOptions) (public []HandlerOptions, internal []HandlerOptions) {
+	for _, r := range routes {
+		if r.internal {
+			internal = append(internal, r)
+			continue
+		}
+		public = append(public, r)
+	}
+	return public, internal
+}
+
+// routeIndexTmpl renders the index of the registered HTTP handlers.
+var routeIndexTmpl = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Routes</title></head>
+<body>
+<h1>Routes</h1>
+<table>
+<tr><th>Path</th><th>Methods</th><th>Listener</th><th>Admin</th></tr>
+{{- range .}}
+<tr><td>{{if .Internal}}<a href="{{.Path}}">{{.Path}}</a>{{else}}{{.Path}}{{end}}{{if .Prefix}}*{{end}}</td><td>{{.Methods}}</td><td>{{if .Internal}}admin{{else}}public{{end}}</td><td>{{.Admin}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// routeIndex returns a handler listing the given routes.
+func routeIndex(routes []HandlerOptions) http.Handler {
+	type route struct {
+		Path     string
+		Methods  string
+		Prefix   bool
+		Internal bool
+		Admin    bool
+	}
+	rs := make([]route, 0, len(routes))
+	for _, r := range routes {
+		methods := "*"
+		if len(r.m) > 0 {
+			methods = strings.Join(r.m, ",")
+		}
+		rs = append(rs, route{Path: r.p, Methods: methods, Prefix: r.prefix, Internal: r.internal, Admin: r.admin})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = routeIndexTmpl.Execute(w, rs)
+	})
+}
@@ -0,0 +1,169 @@
+package server_test
+
+import (
+	"context"
+	"github.com/realHoangHai/awesome/internal/server"
+	"github.com/realHoangHai/awesome/pkg/jwt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAdminServices(t *testing.T) {
+	secret := []byte("admin-secret")
+	srv := startServer(t, []server.Service{&whoService{}},
+		server.Reflection(),
+		server.Channelz(""),
+		server.AdminAuth(jwt.ScopeAuthenticator(secret, "admin")),
+	)
+	adminToken, err := jwt.Encode(jwt.Claims{Scope: "admin"}, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userToken, err := jwt.Encode(jwt.Claims{Scope: "user"}, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := grpc.Dial(srv.addr, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	t.Run("reflection", func(t *testing.T) {
+		listServices := func(token string) ([]string, error) {
+			ctx := context.Background()
+			if token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", token)
+			}
+			stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if err := stream.Send(&rpb.ServerReflectionRequest{MessageRequest: &rpb.ServerReflectionRequest_ListServices{}}); err != nil {
+				return nil, err
+			}
+			resp, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			var names []string
+			for _, s := range resp.GetListServicesResponse().GetService() {
+				names = append(names, s.Name)
+			}
+			return names, nil
+		}
+		for _, token := range []string{"", userToken} {
+			if _, err := listServices(token); err == nil {
+				t.Fatalf("got services listed with token=%q, want error without admin scope", token)
+			}
+		}
+		names, err := listServices(adminToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := false
+		for _, n := range names {
+			found = found || n == "test.Who"
+		}
+		if !found {
+			t.Fatalf("got services=%v, want test.Who listed", names)
+		}
+	})
+
+	t.Run("other services are not protected", func(t *testing.T) {
+		out := &wrapperspb.StringValue{}
+		if err := conn.Invoke(context.Background(), "/test.Who/Call", &emptypb.Empty{}, out); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("channelz", func(t *testing.T) {
+		cases := []struct {
+			token string
+			want  int
+		}{
+			{token: "", want: http.StatusUnauthorized},
+			{token: userToken, want: http.StatusUnauthorized},
+			{token: adminToken, want: http.StatusOK},
+		}
+		for _, c := range cases {
+			req, _ := http.NewRequest(http.MethodGet, "http://"+srv.addr+"/debug/channelz/servers", nil)
+			req.Header.Set("Authorization", c.token)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != c.want {
+				t.Fatalf("got status_code=%d with token=%q, want status_code=%d", resp.StatusCode, c.token, c.want)
+			}
+		}
+	})
+}
+
+func TestAdminListener(t *testing.T) {
+	adminLis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := startServer(t, nil,
+		server.AdminListener(adminLis),
+		server.Metrics(""),
+		server.PProf(""),
+		server.HandlerFunc("/hello", func(w http.ResponseWriter, r *http.Request) {}),
+	)
+
+	get := func(addr, path string) (int, string) {
+		t.Helper()
+		resp, err := http.Get("http://" + addr + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+	cases := []struct {
+		path   string
+		public int
+		admin  int
+	}{
+		{path: "/hello", public: http.StatusOK, admin: http.StatusNotFound},
+		{path: "/internal/metrics", public: http.StatusNotFound, admin: http.StatusOK},
+		{path: "/internal/health", public: http.StatusNotFound, admin: http.StatusOK},
+		{path: "/debug/pprof/cmdline", public: http.StatusNotFound, admin: http.StatusOK},
+	}
+	for _, c := range cases {
+		if got, _ := get(srv.addr, c.path); got != c.public {
+			t.Errorf("public %s: got status_code=%d, want status_code=%d", c.path, got, c.public)
+		}
+		if got, _ := get(adminLis.Addr().String(), c.path); got != c.admin {
+			t.Errorf("admin %s: got status_code=%d, want status_code=%d", c.path, got, c.admin)
+		}
+	}
+	code, index := get(adminLis.Addr().String(), "/")
+	if code != http.StatusOK || !strings.Contains(index, "/hello") || !strings.Contains(index, "/internal/metrics") {
+		t.Errorf("got status_code=%d, index=%s, want all routes listed", code, index)
+	}
+}
+
+func TestAdminServicesRequireAdminAuth(t *testing.T) {
+	for name, opt := range map[string]server.Option{
+		"reflection": server.Reflection(),
+		"channelz":   server.Channelz(""),
+	} {
+		srv := server.New(server.Address("127.0.0.1:0"), opt)
+		if err := srv.Run(); err == nil {
+			t.Fatalf("got server started with %s without AdminAuth, want error", name)
+		}
+	}
+}
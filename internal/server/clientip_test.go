@@ -0,0 +1,92 @@
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/realHoangHai/awesome/internal/server"
+	"github.com/realHoangHai/awesome/pkg/clientip"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	grpcIP := make(chan string, 1)
+	srv := startServer(t, []server.Service{&echoService{}},
+		server.ProxyProtocol(),
+		server.TrustedProxies("127.0.0.1"),
+		server.UnaryInterceptors(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ip, _ := clientip.FromContext(ctx)
+			grpcIP <- ip.String()
+			return handler(ctx, req)
+		}),
+		server.HandlerFunc("/ip", func(w http.ResponseWriter, r *http.Request) {
+			ip, _ := clientip.FromContext(r.Context())
+			_, _ = fmt.Fprint(w, ip)
+		}),
+	)
+	addr := srv.addr
+
+	t.Run("forwarded header", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/ip", nil)
+		req.Header.Set("X-Forwarded-For", "198.51.100.2")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if b, _ := io.ReadAll(resp.Body); string(b) != "198.51.100.2" {
+			t.Errorf("got client_ip=%s, want client_ip=198.51.100.2", b)
+		}
+	})
+
+	t.Run("proxy protocol", func(t *testing.T) {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		// the forwarded header of an untrusted client is ignored.
+		_, _ = io.WriteString(conn, "PROXY TCP4 198.51.100.1 127.0.0.1 1234 80\r\n"+
+			"GET /ip HTTP/1.1\r\nHost: localhost\r\nX-Forwarded-For: 203.0.113.1\r\nConnection: close\r\n\r\n")
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if b, _ := io.ReadAll(resp.Body); string(b) != "198.51.100.1" {
+			t.Errorf("got client_ip=%s, want client_ip=198.51.100.1", b)
+		}
+	})
+
+	t.Run("grpc forwarded metadata", func(t *testing.T) {
+		conn, err := grpc.Dial(addr, grpc.WithInsecure())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-forwarded-for", "198.51.100.3")
+		if err := conn.Invoke(ctx, "/test.Echo/Echo", wrapperspb.String("hello"), &wrapperspb.StringValue{}); err != nil {
+			t.Fatal(err)
+		}
+		if got := <-grpcIP; got != "198.51.100.3" {
+			t.Errorf("got client_ip=%s, want client_ip=198.51.100.3", got)
+		}
+	})
+}
+
+func TestProxyProtocolRequiresTrustedProxies(t *testing.T) {
+	srv := server.New(server.Address("127.0.0.1:0"), server.ProxyProtocol())
+	// the server stops at once if it starts.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := srv.RunWithContext(ctx); err == nil || !strings.Contains(err.Error(), "TrustedProxies") {
+		t.Fatalf("got err=%v, want ProxyProtocol refused without TrustedProxies", err)
+	}
+}
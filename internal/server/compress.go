@@ -0,0 +1,415 @@
+package server
+
+import (
+	"compress/gzip"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc/encoding"
+	// registers the gzip compressor of gRPC.
+	_ "google.golang.org/grpc/encoding/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	gzipEncoding   = "gzip"
+	brotliEncoding = "br"
+	zstdEncoding   = "zstd"
+	// defaultCompressionMinSize is the size in bytes under which the responses are not worth compressing.
+	defaultCompressionMinSize = 1024
+)
+
+var (
+	// defaultCompressionTypes are the content types compressed by default, i.e... text/ matches
+	// all the text types and +json all the JSON based types. Images, fonts, videos and archives
+	// are compressed already.
+	defaultCompressionTypes = []string{
+		"text/",
+		"application/json",
+		"application/javascript",
+		"application/xml",
+		"application/wasm",
+		"image/svg+xml",
+		"+json",
+		"+xml",
+	}
+
+	gzipWriters = &sync.Pool{
+		New: func() interface{} {
+			return gzip.NewWriter(nil)
+		},
+	}
+	brotliWriters = &sync.Pool{
+		New: func() interface{} {
+			return brotli.NewWriter(nil)
+		},
+	}
+	zstdWriters = &sync.Pool{
+		New: func() interface{} {
+			// the options are valid.
+			enc, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+			return enc
+		},
+	}
+	zstdReaders = &sync.Pool{
+		New: func() interface{} {
+			dec, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+			return dec
+		},
+	}
+
+	// gzipEncoder, brotliEncoder and zstdEncoder are the built-in Encoders.
+	gzipEncoder   = pooledEncoder(gzipWriters)
+	brotliEncoder = pooledEncoder(brotliWriters)
+	zstdEncoder   = pooledEncoder(zstdWriters)
+)
+
+type (
+	// Encoder returns a writer compressing to w with a content encoding, i.e... br or zstd.
+	// If the writer implements Flush() error, it is flushed with the response for streaming.
+	Encoder func(w io.Writer) (io.WriteCloser, error)
+
+	// compressor compresses the HTTP responses with the encoding negotiated from Accept-Encoding.
+	compressor struct {
+		// encodings in order of preference.
+		encodings []string
+		encoders  map[string]Encoder
+		minSize   int
+		types     []string
+	}
+
+	// compressWriter buffers the response until it is large enough to be compressed,
+	// or until it is flushed or finished.
+	compressWriter struct {
+		http.ResponseWriter
+		c        *compressor
+		encoding string
+		code     int
+		buf      []byte
+		// decided is true once the response is either compressed by enc or written as is.
+		decided bool
+		enc     io.WriteCloser
+	}
+
+	// resetWriter is a compressing writer reusable for another writer once closed,
+	// i.e... *gzip.Writer, *brotli.Writer or *zstd.Encoder.
+	resetWriter interface {
+		io.WriteCloser
+		Flush() error
+		Reset(w io.Writer)
+	}
+
+	// pooledWriter returns its writer to the pool when closed.
+	pooledWriter struct {
+		resetWriter
+		pool *sync.Pool
+	}
+
+	// zstdReader returns its decoder to the pool once read.
+	zstdReader struct {
+		dec *zstd.Decoder
+	}
+
+	// grpcZstd is the zstd compressor of gRPC.
+	grpcZstd struct{}
+)
+
+func newCompressor(minSize int, types []string, encodings []string) *compressor {
+	if minSize <= 0 {
+		minSize = defaultCompressionMinSize
+	}
+	if len(types) == 0 {
+		types = defaultCompressionTypes
+	}
+	if len(encodings) == 0 {
+		encodings = []string{gzipEncoding}
+	}
+	return &compressor{
+		encodings: encodings,
+		encoders: map[string]Encoder{
+			gzipEncoding:   gzipEncoder,
+			brotliEncoding: brotliEncoder,
+			zstdEncoding:   zstdEncoder,
+		},
+		minSize: minSize,
+		types:   types,
+	}
+}
+
+// handler compresses the responses of the handler, except the upgraded connections, i.e... WebSocket.
+// The encodings without encoder are ignored.
+func (c *compressor) handler(next http.Handler) http.Handler {
+	encodings := make([]string, 0, len(c.encodings))
+	for _, enc := range c.encodings {
+		if c.encoders[enc] != nil {
+			encodings = append(encodings, enc)
+		}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		addVary(w.Header(), "Accept-Encoding")
+		enc := negotiateEncoding(r.Header.Get("Accept-Encoding"), encodings)
+		if enc == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, c: c, encoding: enc}
+		defer cw.finish()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// unsupported returns the encodings without encoder.
+func (c *compressor) unsupported() []string {
+	var encodings []string
+	for _, enc := range c.encodings {
+		if c.encoders[enc] == nil {
+			encodings = append(encodings, enc)
+		}
+	}
+	return encodings
+}
+
+// compressible reports whether a response with the given headers should be compressed.
+// The events are not compressed for being received as soon as they are sent.
+func (c *compressor) compressible(h http.Header) bool {
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+	ct, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil || ct == eventStreamContentType {
+		return false
+	}
+	for _, t := range c.types {
+		switch {
+		case strings.HasSuffix(t, "/") && strings.HasPrefix(ct, t),
+			strings.HasPrefix(t, "+") && strings.HasSuffix(ct, t),
+			ct == t:
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding returns the encoding accepted with the highest quality, the first of
+// the encodings in order of preference if several. It returns empty if none is accepted.
+func negotiateEncoding(accept string, encodings []string) string {
+	if accept == "" {
+		return ""
+	}
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(accept, ",") {
+		name, q := part, 1.0
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			name = part[:i]
+			if param := strings.TrimSpace(part[i+1:]); strings.HasPrefix(param, "q=") {
+				v, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil {
+					continue
+				}
+				q = v
+			}
+		}
+		qualities[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+	best, bestQ := "", 0.0
+	for _, enc := range encodings {
+		q, ok := qualities[enc]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+// addVary adds the header to the Vary header unless already there.
+func addVary(h http.Header, header string) {
+	for _, v := range h.Values("Vary") {
+		for _, f := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(f), header) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", header)
+}
+
+// WriteHeader implements http.ResponseWriter, the responses known not to be compressed
+// are written as is right away.
+func (cw *compressWriter) WriteHeader(code int) {
+	if code < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if cw.code != 0 {
+		return
+	}
+	cw.code = code
+	h := cw.Header()
+	if code == http.StatusNoContent || code == http.StatusNotModified || code == http.StatusPartialContent ||
+		h.Get("Content-Type") != "" && !cw.c.compressible(h) {
+		_ = cw.decide(false)
+		return
+	}
+	if n, err := strconv.Atoi(h.Get("Content-Length")); err == nil && n < cw.c.minSize {
+		_ = cw.decide(false)
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.code == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		if cw.enc != nil {
+			return cw.enc.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.c.minSize {
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush implements http.Flusher, the response is compressed regardless of its size for streaming.
+func (cw *compressWriter) Flush() {
+	if cw.code == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.decided {
+		_ = cw.decide(true)
+	}
+	if f, ok := cw.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// decide compresses the response if compress and compressible, or writes it as is,
+// then writes the buffered data.
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+	h := cw.Header()
+	if h.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	if compress && cw.c.compressible(h) {
+		if enc, err := cw.c.encoders[cw.encoding](cw.ResponseWriter); err == nil {
+			cw.enc = enc
+			h.Set("Content-Encoding", cw.encoding)
+			h.Del("Content-Length")
+			h.Del("Accept-Ranges")
+			// the compressed content is no longer byte for byte the same.
+			if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+				h.Set("ETag", "W/"+etag)
+			}
+		}
+	}
+	cw.ResponseWriter.WriteHeader(cw.code)
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(cw.buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(cw.buf)
+	}
+	cw.buf = nil
+	return err
+}
+
+// finish writes the remaining of the response, too small to be compressed if still buffered.
+func (cw *compressWriter) finish() {
+	if !cw.decided && cw.code != 0 {
+		_ = cw.decide(false)
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Close()
+	}
+}
+
+// pooledEncoder returns an Encoder of the writers of the pool.
+func pooledEncoder(pool *sync.Pool) Encoder {
+	return func(w io.Writer) (io.WriteCloser, error) {
+		rw := pool.Get().(resetWriter)
+		rw.Reset(w)
+		return &pooledWriter{resetWriter: rw, pool: pool}, nil
+	}
+}
+
+// Close implements io.Closer.
+func (w *pooledWriter) Close() error {
+	err := w.resetWriter.Close()
+	w.pool.Put(w.resetWriter)
+	return err
+}
+
+// Read implements io.Reader.
+func (r *zstdReader) Read(p []byte) (int, error) {
+	if r.dec == nil {
+		return 0, io.EOF
+	}
+	n, err := r.dec.Read(p)
+	if err != nil {
+		// the decoder does not keep the reader once read.
+		_ = r.dec.Reset(nil)
+		zstdReaders.Put(r.dec)
+		r.dec = nil
+	}
+	return n, err
+}
+
+// Compress implements encoding.Compressor.
+func (grpcZstd) Compress(w io.Writer) (io.WriteCloser, error) {
+	return zstdEncoder(w)
+}
+
+// Decompress implements encoding.Compressor.
+func (grpcZstd) Decompress(r io.Reader) (io.Reader, error) {
+	dec := zstdReaders.Get().(*zstd.Decoder)
+	if err := dec.Reset(r); err != nil {
+		zstdReaders.Put(dec)
+		return nil, err
+	}
+	return &zstdReader{dec: dec}, nil
+}
+
+// Name implements encoding.Compressor.
+func (grpcZstd) Name() string {
+	return zstdEncoding
+}
+
+// registerGRPCCompressors registers the built-in gRPC compressors of the given names,
+// and returns the names of the compressors not registered.
+func registerGRPCCompressors(names []string) []string {
+	var missing []string
+	for _, name := range names {
+		if encoding.GetCompressor(name) != nil {
+			continue
+		}
+		if name == zstdEncoding {
+			encoding.RegisterCompressor(grpcZstd{})
+			continue
+		}
+		missing = append(missing, name)
+	}
+	return missing
+}
@@ -0,0 +1,189 @@
+package server_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/realHoangHai/awesome/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	dir := t.TempDir()
+	script := strings.Repeat("console.log('hello');\n", 100)
+	var precompressed bytes.Buffer
+	zw := gzip.NewWriter(&precompressed)
+	_, _ = zw.Write([]byte(script))
+	_ = zw.Close()
+	// the web path prefix is not stripped.
+	if err := os.Mkdir(filepath.Join(dir, "web"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for name, b := range map[string][]byte{
+		"index.html":    []byte("<html></html>"),
+		"web/app.js":    []byte(script),
+		"web/app.js.gz": precompressed.Bytes(),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	large := strings.Repeat(`{"hello":"world"}`, 100)
+	srv := startServer(t, []server.Service{&echoService{}},
+		server.Compression(0, nil, "deflate", "gzip", "br", "zstd"),
+		server.Encoding("deflate", func(w io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(w, flate.DefaultCompression)
+		}),
+		server.GRPCCompressors("gzip", "zstd"),
+		server.HandlerFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, large)
+		}),
+		server.HandlerFunc("/small", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"hello":"world"}`)
+		}),
+		server.HandlerFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(make([]byte, 4096))
+		}),
+		server.Web("/web/", dir, "index.html"),
+	)
+	get := func(path, accept string) (*http.Response, []byte) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, "http://"+srv.addr+path, nil)
+		// set explicitly so that the client does not decompress.
+		req.Header.Set("Accept-Encoding", accept)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp, b
+	}
+	gunzip := func(b []byte) string {
+		t.Helper()
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(out)
+	}
+
+	t.Run("negotiation", func(t *testing.T) {
+		cases := []struct {
+			accept string
+			want   string
+		}{
+			{accept: "gzip, deflate;q=0.5", want: "gzip"},
+			{accept: "gzip, deflate", want: "deflate"},
+			{accept: "br;q=1, *;q=0.1", want: "br"},
+			{accept: "compress;q=1, *;q=0.1", want: "deflate"},
+			{accept: "zstd, gzip;q=0.5", want: "zstd"},
+			{accept: "gzip;q=0, identity", want: ""},
+			{accept: "", want: ""},
+		}
+		for _, c := range cases {
+			resp, _ := get("/large", c.accept)
+			if got := resp.Header.Get("Content-Encoding"); got != c.want {
+				t.Errorf("got encoding=%q, want encoding=%q, accept=%q", got, c.want, c.accept)
+			}
+			if vary := resp.Header.Get("Vary"); vary != "Accept-Encoding" {
+				t.Errorf("got vary=%q, want Accept-Encoding", vary)
+			}
+		}
+	})
+
+	t.Run("compressed response", func(t *testing.T) {
+		resp, b := get("/large", "gzip")
+		if resp.Header.Get("Content-Encoding") != "gzip" || gunzip(b) != large {
+			t.Errorf("got encoding=%q, want the response compressed", resp.Header.Get("Content-Encoding"))
+		}
+	})
+
+	t.Run("built-in encodings", func(t *testing.T) {
+		decoders := map[string]func(b []byte) ([]byte, error){
+			"br": func(b []byte) ([]byte, error) {
+				return io.ReadAll(brotli.NewReader(bytes.NewReader(b)))
+			},
+			"zstd": func(b []byte) ([]byte, error) {
+				zr, err := zstd.NewReader(bytes.NewReader(b))
+				if err != nil {
+					return nil, err
+				}
+				defer zr.Close()
+				return io.ReadAll(zr)
+			},
+		}
+		for enc, decode := range decoders {
+			// the writers are reused.
+			for i := 0; i < 2; i++ {
+				resp, b := get("/large", enc)
+				if got := resp.Header.Get("Content-Encoding"); got != enc {
+					t.Fatalf("got encoding=%q, want encoding=%q", got, enc)
+				}
+				out, err := decode(b)
+				if err != nil || string(out) != large {
+					t.Fatalf("got %s decoded=%q, err=%v, want the response", enc, out, err)
+				}
+			}
+		}
+	})
+
+	t.Run("uncompressed responses", func(t *testing.T) {
+		for _, path := range []string{"/small", "/image"} {
+			resp, b := get(path, "gzip")
+			if enc := resp.Header.Get("Content-Encoding"); enc != "" || len(b) == 0 {
+				t.Errorf("got encoding=%q for %s, want the response written as is", enc, path)
+			}
+		}
+	})
+
+	t.Run("precompressed assets", func(t *testing.T) {
+		resp, b := get("/web/app.js", "gzip, deflate")
+		if resp.Header.Get("Content-Encoding") != "gzip" || !bytes.Equal(b, precompressed.Bytes()) {
+			t.Errorf("got encoding=%q, want the precompressed asset served as is", resp.Header.Get("Content-Encoding"))
+		}
+		if ct := resp.Header.Get("Content-Type"); !strings.Contains(ct, "javascript") {
+			t.Errorf("got content_type=%s, want the type of the asset", ct)
+		}
+		if vary := resp.Header.Values("Vary"); len(vary) != 1 {
+			t.Errorf("got vary=%q, want Accept-Encoding once", vary)
+		}
+		resp, b = get("/web/app.js", "")
+		if resp.Header.Get("Content-Encoding") != "" || string(b) != script {
+			t.Errorf("got encoding=%q, want the asset uncompressed", resp.Header.Get("Content-Encoding"))
+		}
+	})
+
+	t.Run("grpc compression", func(t *testing.T) {
+		conn, err := grpc.Dial(srv.addr, grpc.WithInsecure())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		for _, name := range []string{"gzip", "zstd"} {
+			for i := 0; i < 2; i++ {
+				out := &wrapperspb.StringValue{}
+				if err := conn.Invoke(context.Background(), "/test.Echo/Echo", wrapperspb.String(large), out, grpc.UseCompressor(name)); err != nil || out.Value != large {
+					t.Errorf("got %s message=%.20q, err=%v, want the request echoed", name, out.GetValue(), err)
+				}
+			}
+		}
+	})
+}
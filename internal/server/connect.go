@@ -0,0 +1,245 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	connectStreamContentType = "application/connect+"
+	// connectEndStreamFlag marks the Connect frame ending the stream.
+	connectEndStreamFlag = 0x02
+)
+
+// connectCodes are the names of the codes in Connect errors, indexed by code.
+var connectCodes = []string{
+	"ok", "canceled", "unknown", "invalid_argument", "deadline_exceeded", "not_found", "already_exists",
+	"permission_denied", "resource_exhausted", "failed_precondition", "aborted", "out_of_range",
+	"unimplemented", "internal", "unavailable", "data_loss", "unauthenticated",
+}
+
+type (
+	// connectError is a Connect error, the body of a failed unary call
+	// or the error of the end of a stream.
+	connectError struct {
+		Code    string               `json:"code"`
+		Message string               `json:"message,omitempty"`
+		Details []connectErrorDetail `json:"details,omitempty"`
+	}
+
+	connectErrorDetail struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+
+	// connectEndStream is the message of the frame ending a Connect stream.
+	connectEndStream struct {
+		Error    *connectError       `json:"error,omitempty"`
+		Metadata map[string][]string `json:"metadata,omitempty"`
+	}
+
+	// unaryRecorder records the response of a unary call of the gRPC server.
+	unaryRecorder struct {
+		header http.Header
+		code   int
+		body   bytes.Buffer
+	}
+)
+
+func isConnectUnaryContentType(ct string) bool {
+	if i := strings.IndexByte(ct, ';'); i >= 0 {
+		ct = ct[:i]
+	}
+	ct = strings.TrimSpace(ct)
+	return ct == "application/proto" || ct == "application/json"
+}
+
+// serveConnectUnary serves a Connect unary call: the message is sent as is in the body,
+// the trailers are sent as headers prefixed by Trailer- and the errors as JSON.
+func (p *webProtocols) serveConnectUnary(w http.ResponseWriter, r *http.Request) {
+	if enc := r.Header.Get("Content-Encoding"); enc != "" && enc != "identity" {
+		writeConnectError(w, status.Newf(codes.Unimplemented, "unsupported compression: %s", enc))
+		return
+	}
+	msg, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeConnectError(w, status.Newf(codes.InvalidArgument, "read request: %v", err))
+		return
+	}
+	subtype := "proto"
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		subtype = "json"
+	}
+	req := grpcRequest(r, subtype, bytes.NewReader(frame(0, msg)))
+	connectHeaders(req.Header)
+	req.Header.Del("Content-Encoding")
+	req.Header.Del("Accept-Encoding")
+
+	rec := &unaryRecorder{header: make(http.Header)}
+	p.grpcServer.ServeHTTP(rec, req)
+	if rec.code != 0 && rec.code != http.StatusOK {
+		writeConnectError(w, status.New(codes.Internal, strings.TrimSpace(rec.body.String())))
+		return
+	}
+	trailer := grpcTrailers(rec.header)
+	st := trailerStatus(trailer)
+	h := w.Header()
+	copyMetadata(h, rec.header)
+	for k, vv := range trailer {
+		if !isGRPCStatusHeader(k) {
+			h["Trailer-"+k] = vv
+		}
+	}
+	if st.Code() != codes.OK {
+		writeConnectError(w, st)
+		return
+	}
+	b := rec.body.Bytes()
+	if len(b) < 5 || int(binary.BigEndian.Uint32(b[1:5])) > len(b)-5 {
+		writeConnectError(w, status.New(codes.Internal, "invalid response message"))
+		return
+	}
+	h.Set("Content-Type", "application/"+subtype)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(b[5 : 5+binary.BigEndian.Uint32(b[1:5])])
+}
+
+// serveConnectStream serves a Connect streaming call, its frames are the ones of gRPC,
+// the trailers and the error are sent in the frame ending the stream.
+func (p *webProtocols) serveConnectStream(w http.ResponseWriter, r *http.Request) {
+	subtype := contentSubtype(r.Header.Get("Content-Type"))
+	req := grpcRequest(r, subtype, r.Body)
+	connectHeaders(req.Header)
+	if v := req.Header.Get("Connect-Content-Encoding"); v != "" {
+		req.Header.Set("Grpc-Encoding", v)
+	}
+	if v := req.Header.Get("Connect-Accept-Encoding"); v != "" {
+		req.Header.Set("Grpc-Accept-Encoding", v)
+	}
+	req.Header.Del("Connect-Content-Encoding")
+	req.Header.Del("Connect-Accept-Encoding")
+	fw := &frameWriter{
+		w:              w,
+		header:         make(http.Header),
+		contentType:    connectStreamContentType + subtype,
+		encodingHeader: "Connect-Content-Encoding",
+		endFrame:       connectEndStreamFrame,
+	}
+	p.grpcServer.ServeHTTP(fw, req)
+	fw.finish()
+}
+
+// connectEndStreamFrame returns the Connect frame ending the stream.
+func connectEndStreamFrame(trailer http.Header) []byte {
+	end := connectEndStream{}
+	if st := trailerStatus(trailer); st.Code() != codes.OK {
+		end.Error = newConnectError(st)
+	}
+	for k, vv := range trailer {
+		if isGRPCStatusHeader(k) {
+			continue
+		}
+		if end.Metadata == nil {
+			end.Metadata = make(map[string][]string)
+		}
+		end.Metadata[strings.ToLower(k)] = vv
+	}
+	b, _ := json.Marshal(end)
+	return frame(connectEndStreamFlag, b)
+}
+
+// connectHeaders translates the Connect request headers to gRPC.
+func connectHeaders(h http.Header) {
+	if v := h.Get("Connect-Timeout-Ms"); v != "" {
+		h.Set("Grpc-Timeout", v+"m")
+	}
+	h.Del("Connect-Timeout-Ms")
+	h.Del("Connect-Protocol-Version")
+}
+
+// trailerStatus returns the status of the gRPC trailers.
+func trailerStatus(trailer http.Header) *status.Status {
+	if bin := trailer.Get("Grpc-Status-Details-Bin"); bin != "" {
+		b, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(bin, "="))
+		s := &spb.Status{}
+		if err == nil && proto.Unmarshal(b, s) == nil {
+			return status.FromProto(s)
+		}
+	}
+	code, err := strconv.Atoi(trailer.Get("Grpc-Status"))
+	if err != nil {
+		return status.New(codes.Unknown, "missing status")
+	}
+	msg, err := url.PathUnescape(trailer.Get("Grpc-Message"))
+	if err != nil {
+		msg = trailer.Get("Grpc-Message")
+	}
+	return status.New(codes.Code(code), msg)
+}
+
+func isGRPCStatusHeader(k string) bool {
+	return k == "Grpc-Status" || k == "Grpc-Message" || k == "Grpc-Status-Details-Bin"
+}
+
+// copyMetadata copies the response metadata of the gRPC server, without the gRPC protocol headers.
+func copyMetadata(dst, src http.Header) {
+	for k, vv := range src {
+		if k == "Trailer" || k == "Content-Type" || k == "Grpc-Encoding" || isGRPCStatusHeader(k) || strings.Contains(k, ":") {
+			continue
+		}
+		dst[k] = vv
+	}
+}
+
+func newConnectError(st *status.Status) *connectError {
+	e := &connectError{Code: connectCodes[codes.Unknown], Message: st.Message()}
+	if c := int(st.Code()); c < len(connectCodes) {
+		e.Code = connectCodes[c]
+	}
+	for _, d := range st.Proto().GetDetails() {
+		e.Details = append(e.Details, connectErrorDetail{
+			Type:  d.GetTypeUrl()[strings.LastIndexByte(d.GetTypeUrl(), '/')+1:],
+			Value: base64.RawStdEncoding.EncodeToString(d.GetValue()),
+		})
+	}
+	return e
+}
+
+// writeConnectError writes the status as the JSON error of a Connect unary call.
+func writeConnectError(w http.ResponseWriter, st *status.Status) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(runtime.HTTPStatusFromCode(st.Code()))
+	_ = json.NewEncoder(w).Encode(newConnectError(st))
+}
+
+// Header implements http.ResponseWriter.
+func (r *unaryRecorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (r *unaryRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (r *unaryRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+// Flush implements http.Flusher, the response is sent once the call finishes.
+func (r *unaryRecorder) Flush() {}
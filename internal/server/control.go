@@ -0,0 +1,349 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "github.com/realHoangHai/awesome/api/admin/v1"
+	"github.com/realHoangHai/awesome/internal/health"
+	"github.com/realHoangHai/awesome/pkg/clientip"
+	"github.com/realHoangHai/awesome/pkg/jwt"
+	"github.com/realHoangHai/awesome/pkg/log"
+	"github.com/realHoangHai/awesome/pkg/utils/response"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	adminControlPackage = "awesome.admin.v1"
+	// defaultAdminControlPrefix is the path prefix of the admin control HTTP API.
+	defaultAdminControlPrefix = "/internal/admin"
+)
+
+type (
+	// CacheFlusher flushes a cache, i.e... an in-memory cache or a Redis database.
+	CacheFlusher interface {
+		Flush(ctx context.Context) error
+	}
+
+	// CacheFlusherFunc is a function implementing CacheFlusher.
+	CacheFlusherFunc func(ctx context.Context) error
+
+	// adminControl serves the admin control operations via gRPC and HTTP.
+	// Each operation requires the admin scope in the JWT claims of the caller and is audited.
+	adminControl struct {
+		v1.UnimplementedAdminControlServer
+		srv *Server
+	}
+
+	// controlError is the body of an admin control HTTP error.
+	controlError struct {
+		Code    codes.Code `json:"code"`
+		Message string     `json:"message"`
+	}
+)
+
+var (
+	adminOperationsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "server",
+		Name:      "admin_operations_total",
+		Help:      "Total number of admin control operations by result.",
+	}, []string{"operation", "result"})
+)
+
+func init() {
+	prometheus.MustRegister(adminOperationsCounter)
+}
+
+// Flush implements CacheFlusher.
+func (f CacheFlusherFunc) Flush(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Register implements Service.
+func (c *adminControl) Register(srv *grpc.Server) {
+	v1.RegisterAdminControlServer(srv, c)
+}
+
+// Shutdown implements v1.AdminControlServer.
+func (c *adminControl) Shutdown(ctx context.Context, _ *v1.ShutdownReq) (*emptypb.Empty, error) {
+	return empty(c.shutdown(ctx, "grpc"))
+}
+
+// SetMaintenance implements v1.AdminControlServer.
+func (c *adminControl) SetMaintenance(ctx context.Context, req *v1.SetMaintenanceReq) (*emptypb.Empty, error) {
+	return empty(c.setMaintenance(ctx, "grpc", req.GetEnabled()))
+}
+
+// SetLogLevel implements v1.AdminControlServer.
+func (c *adminControl) SetLogLevel(ctx context.Context, req *v1.SetLogLevelReq) (*emptypb.Empty, error) {
+	return empty(c.setLogLevel(ctx, "grpc", req.GetLevel()))
+}
+
+// SetHealthStatus implements v1.AdminControlServer.
+func (c *adminControl) SetHealthStatus(ctx context.Context, req *v1.SetHealthStatusReq) (*emptypb.Empty, error) {
+	return empty(c.setHealthStatus(ctx, "grpc", req.GetService(), req.GetStatus()))
+}
+
+// FlushCaches implements v1.AdminControlServer.
+func (c *adminControl) FlushCaches(ctx context.Context, req *v1.FlushCachesReq) (*emptypb.Empty, error) {
+	return empty(c.flushCaches(ctx, "grpc", req.GetName()))
+}
+
+// empty returns the response of the admin control methods, all of them return google.protobuf.Empty.
+func empty(err error) (*emptypb.Empty, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// routes returns the HTTP handlers of the admin control API under the given prefix:
+//   - POST {prefix}/shutdown
+//   - PUT {prefix}/maintenance {"enabled": true}
+//   - PUT {prefix}/log-level {"level": "debug"}
+//   - PUT {prefix}/health {"service": "db", "status": "NOT_SERVING"}
+//   - POST {prefix}/caches/flush {"name": "users"}, empty name to flush all caches.
+func (c *adminControl) routes(prefix string) []HandlerOptions {
+	prefix = strings.TrimSuffix(prefix, "/")
+	route := func(path, method string, h http.HandlerFunc) HandlerOptions {
+		r := HandlerOptions{p: prefix + path, h: h, m: []string{method}}
+		r.Admin()
+		return r
+	}
+	return []HandlerOptions{
+		route("/shutdown", http.MethodPost, c.serveShutdown),
+		route("/maintenance", http.MethodPut, func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Enabled bool `json:"enabled"`
+			}
+			c.serveJSON(w, r, &req, func(ctx context.Context) error {
+				return c.setMaintenance(ctx, "http", req.Enabled)
+			})
+		}),
+		route("/log-level", http.MethodPut, func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Level string `json:"level"`
+			}
+			c.serveJSON(w, r, &req, func(ctx context.Context) error {
+				return c.setLogLevel(ctx, "http", req.Level)
+			})
+		}),
+		route("/health", http.MethodPut, func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Service string `json:"service"`
+				Status  string `json:"status"`
+			}
+			c.serveJSON(w, r, &req, func(ctx context.Context) error {
+				return c.setHealthStatus(ctx, "http", req.Service, req.Status)
+			})
+		}),
+		route("/caches/flush", http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Name string `json:"name"`
+			}
+			c.serveJSON(w, r, &req, func(ctx context.Context) error {
+				return c.flushCaches(ctx, "http", req.Name)
+			})
+		}),
+	}
+}
+
+func (c *adminControl) serveShutdown(w http.ResponseWriter, r *http.Request) {
+	c.serveJSON(w, r, nil, func(ctx context.Context) error {
+		return c.shutdown(ctx, "http")
+	})
+}
+
+// serveJSON decodes the request body into req if any, runs the operation and writes its result.
+func (c *adminControl) serveJSON(w http.ResponseWriter, r *http.Request, req interface{}, op func(ctx context.Context) error) {
+	w.Header().Set("Content-Type", "application/json")
+	err := error(nil)
+	if req != nil && r.ContentLength != 0 {
+		if derr := json.NewDecoder(r.Body).Decode(req); derr != nil {
+			err = status.Errorf(codes.InvalidArgument, "invalid request body: %v", derr)
+		}
+	}
+	if err == nil {
+		err = op(r.Context())
+	}
+	if err != nil {
+		s := status.Convert(err)
+		w.WriteHeader(runtime.HTTPStatusFromCode(s.Code()))
+		_ = json.NewEncoder(w).Encode(controlError{Code: s.Code(), Message: s.Message()})
+		return
+	}
+	_, _ = w.Write([]byte("{}"))
+}
+
+func (c *adminControl) shutdown(ctx context.Context, transport string) error {
+	return c.do(ctx, "shutdown", transport, nil, func() error {
+		// shutdown asynchronously, since it waits for this request to finish.
+		go c.srv.Shutdown(context.Background())
+		return nil
+	})
+}
+
+func (c *adminControl) setMaintenance(ctx context.Context, transport string, enabled bool) error {
+	return c.do(ctx, "set_maintenance", transport, []interface{}{"enabled", enabled}, func() error {
+		c.srv.setMaintenance(enabled)
+		return nil
+	})
+}
+
+func (c *adminControl) setLogLevel(ctx context.Context, transport string, name string) error {
+	return c.do(ctx, "set_log_level", transport, []interface{}{"level", name}, func() error {
+		level, err := log.ParseLevel(name)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		setter, ok := c.srv.getLogger().(log.LevelSetter)
+		if !ok {
+			return status.Error(codes.FailedPrecondition, "logger does not support changing level")
+		}
+		if err := setter.SetLevel(level); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil
+	})
+}
+
+func (c *adminControl) setHealthStatus(ctx context.Context, transport string, service, name string) error {
+	return c.do(ctx, "set_health_status", transport, []interface{}{"service", service, "status", name}, func() error {
+		v, ok := grpc_health_v1.HealthCheckResponse_ServingStatus_value[strings.ToUpper(name)]
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "invalid health status: %s", name)
+		}
+		setter, ok := c.srv.healthSrv.(health.StatusSetter)
+		if !ok {
+			return status.Error(codes.FailedPrecondition, "health check server does not support setting status")
+		}
+		setter.SetStatus(service, health.Status(v))
+		return nil
+	})
+}
+
+func (c *adminControl) flushCaches(ctx context.Context, transport string, name string) error {
+	return c.do(ctx, "flush_caches", transport, []interface{}{"cache", name}, func() error {
+		names := []string{name}
+		if name == "" {
+			names = names[:0]
+			for n := range c.srv.caches {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+		}
+		for _, n := range names {
+			f, ok := c.srv.caches[n]
+			if !ok {
+				return status.Errorf(codes.NotFound, "cache not found: %s", n)
+			}
+			if err := f.Flush(ctx); err != nil {
+				return status.Errorf(codes.Internal, "flush cache %s: %v", n, err)
+			}
+		}
+		return nil
+	})
+}
+
+// do authorizes the caller by the admin scope of its JWT claims, runs the operation and audits it.
+// Callers without claims are denied, i.e... when neither AdminAuth nor JWT is configured.
+func (c *adminControl) do(ctx context.Context, op, transport string, args []interface{}, f func() error) error {
+	claims, ok := jwt.FromContext(ctx)
+	kv := []interface{}{"audit", true, "operation", op, "transport", transport, "subject", claims.Subject}
+	if ip, ok := clientip.FromContext(ctx); ok {
+		kv = append(kv, "client_ip", ip.String())
+	}
+	kv = append(kv, args...)
+	logger := c.srv.getLogger().Context(ctx).Fields(kv...)
+	scope := c.srv.getAdminScope()
+	if !ok || !claims.ContainScopes(scope) {
+		adminOperationsCounter.WithLabelValues(op, "denied").Inc()
+		logger.Warnf("server: admin operation denied, scope %q required", scope)
+		return status.Errorf(codes.PermissionDenied, "admin scope %q required", scope)
+	}
+	if err := f(); err != nil {
+		adminOperationsCounter.WithLabelValues(op, "error").Inc()
+		logger.Errorf("server: admin operation failed, err: %v", err)
+		return err
+	}
+	adminOperationsCounter.WithLabelValues(op, "ok").Inc()
+	logger.Infof("server: admin operation succeeded")
+	return nil
+}
+
+// setMaintenance switches the maintenance mode, the overall health status is NOT_SERVING
+// while in maintenance so that load balancers stop routing traffic to the server.
+func (s *Server) setMaintenance(enabled bool) {
+	var v int32
+	st := health.StatusServing
+	if enabled {
+		v = 1
+		st = health.StatusNotServing
+	}
+	atomic.StoreInt32(&s.maintenance, v)
+	if setter, ok := s.healthSrv.(health.StatusSetter); ok {
+		setter.SetStatus(health.OverallServiceName, st)
+	}
+}
+
+// InMaintenance reports whether the server is in maintenance mode.
+func (s *Server) InMaintenance() bool {
+	return atomic.LoadInt32(&s.maintenance) == 1
+}
+
+func (s *Server) getAdminScope() string {
+	if s.adminScope == "" {
+		return defaultAdminScope
+	}
+	return s.adminScope
+}
+
+var errMaintenance = status.Error(codes.Unavailable, "server is in maintenance, retry later")
+
+// isMaintenanceExempt reports whether the gRPC method is served in maintenance mode.
+func isMaintenanceExempt(method string) bool {
+	return isAdminMethod(method) || strings.HasPrefix(method, "/grpc.health.v1.")
+}
+
+// maintenanceUnaryInterceptor rejects the calls with codes.Unavailable in maintenance mode,
+// except for the admin and health check services.
+func (s *Server) maintenanceUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if s.InMaintenance() && !isMaintenanceExempt(info.FullMethod) {
+			return nil, errMaintenance
+		}
+		return handler(ctx, req)
+	}
+}
+
+// maintenanceStreamInterceptor rejects the streams with codes.Unavailable in maintenance mode,
+// except for the admin and health check services.
+func (s *Server) maintenanceStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if s.InMaintenance() && !isMaintenanceExempt(info.FullMethod) {
+			return errMaintenance
+		}
+		return handler(srv, ss)
+	}
+}
+
+// maintenanceHTTPInterceptor rejects the requests with 503 in maintenance mode.
+func (s *Server) maintenanceHTTPInterceptor(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.InMaintenance() {
+			w.Header().Set("Retry-After", "60")
+			response.Error(w, r, errMaintenance)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,154 @@
+package server_test
+
+import (
+	"context"
+	v1 "github.com/realHoangHai/awesome/api/admin/v1"
+	"github.com/realHoangHai/awesome/internal/health"
+	"github.com/realHoangHai/awesome/internal/server"
+	"github.com/realHoangHai/awesome/pkg/jwt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAdminControl(t *testing.T) {
+	secret := []byte("admin-secret")
+	flushed := make(map[string]int)
+	healthSrv := health.NewServer(map[string]health.Checker{})
+	srv := startServer(t, []server.Service{&whoService{}},
+		server.AdminControl(""),
+		server.AdminAuth(jwt.ScopeAuthenticator(secret, "admin")),
+		server.HealthCheck("", healthSrv),
+		server.Cache("users", server.CacheFlusherFunc(func(ctx context.Context) error {
+			flushed["users"]++
+			return nil
+		})),
+		server.Cache("sessions", server.CacheFlusherFunc(func(ctx context.Context) error {
+			flushed["sessions"]++
+			return nil
+		})),
+	)
+	adminToken, err := jwt.Encode(jwt.Claims{Subject: "ops", Scope: "admin"}, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userToken, err := jwt.Encode(jwt.Claims{Subject: "user", Scope: "user"}, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := grpc.Dial(srv.addr, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := v1.NewAdminControlClient(conn)
+	withToken := func(token string) context.Context {
+		if token == "" {
+			return context.Background()
+		}
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", token)
+	}
+	send := func(token, method, path, body string) int {
+		t.Helper()
+		req, _ := http.NewRequest(method, "http://"+srv.addr+path, strings.NewReader(body))
+		req.Header.Set("Authorization", token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("operations require the admin scope", func(t *testing.T) {
+		for _, token := range []string{"", userToken} {
+			if _, err := client.SetLogLevel(withToken(token), &v1.SetLogLevelReq{Level: "debug"}); err == nil {
+				t.Errorf("got gRPC operation allowed with token=%q, want error", token)
+			}
+			if got := send(token, http.MethodPut, "/internal/admin/log-level", `{"level":"debug"}`); got == http.StatusOK {
+				t.Errorf("got HTTP operation allowed with token=%q, want error", token)
+			}
+		}
+	})
+
+	t.Run("log level", func(t *testing.T) {
+		if _, err := client.SetLogLevel(withToken(adminToken), &v1.SetLogLevelReq{Level: "debug"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.SetLogLevel(withToken(adminToken), &v1.SetLogLevelReq{Level: "verbose"}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("got err=%v, want code=%v", err, codes.InvalidArgument)
+		}
+		if got := send(adminToken, http.MethodPut, "/internal/admin/log-level", `{"level":"info"}`); got != http.StatusOK {
+			t.Errorf("got status_code=%d, want status_code=%d", got, http.StatusOK)
+		}
+	})
+
+	t.Run("health status", func(t *testing.T) {
+		if _, err := client.SetHealthStatus(withToken(adminToken), &v1.SetHealthStatusReq{Service: "db", Status: "NOT_SERVING"}); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "db"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("got status=%v, want status=NOT_SERVING", resp.Status)
+		}
+	})
+
+	t.Run("flush caches", func(t *testing.T) {
+		if got := send(adminToken, http.MethodPost, "/internal/admin/caches/flush", `{"name":"users"}`); got != http.StatusOK {
+			t.Fatalf("got status_code=%d, want status_code=%d", got, http.StatusOK)
+		}
+		if _, err := client.FlushCaches(withToken(adminToken), &v1.FlushCachesReq{}); err != nil {
+			t.Fatal(err)
+		}
+		if flushed["users"] != 2 || flushed["sessions"] != 1 {
+			t.Errorf("got flushed=%v, want users=2 and sessions=1", flushed)
+		}
+		if _, err := client.FlushCaches(withToken(adminToken), &v1.FlushCachesReq{Name: "unknown"}); status.Code(err) != codes.NotFound {
+			t.Errorf("got err=%v, want code=%v", err, codes.NotFound)
+		}
+	})
+
+	t.Run("maintenance", func(t *testing.T) {
+		if _, err := client.SetMaintenance(withToken(adminToken), &v1.SetMaintenanceReq{Enabled: true}); err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.Invoke(context.Background(), "/test.Who/Call", &emptypb.Empty{}, &wrapperspb.StringValue{}); status.Code(err) != codes.Unavailable {
+			t.Errorf("got err=%v, want code=%v", err, codes.Unavailable)
+		}
+		if got := send("", http.MethodGet, "/whoami", ""); got != http.StatusServiceUnavailable {
+			t.Errorf("got status_code=%d, want status_code=%d", got, http.StatusServiceUnavailable)
+		}
+		if got := send("", http.MethodGet, "/internal/health", ""); got == http.StatusServiceUnavailable {
+			t.Errorf("got health check rejected in maintenance mode")
+		}
+		if got := send(adminToken, http.MethodPut, "/internal/admin/maintenance", `{"enabled":false}`); got != http.StatusOK {
+			t.Fatalf("got status_code=%d, want status_code=%d", got, http.StatusOK)
+		}
+		if err := conn.Invoke(context.Background(), "/test.Who/Call", &emptypb.Empty{}, &wrapperspb.StringValue{}); err != nil {
+			t.Errorf("got err=%v after maintenance, want nil", err)
+		}
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		if got := send(userToken, http.MethodPost, "/internal/admin/shutdown", ""); got == http.StatusOK {
+			t.Fatalf("got shutdown allowed without the admin scope")
+		}
+		if got := send(adminToken, http.MethodPost, "/internal/admin/shutdown", ""); got != http.StatusOK {
+			t.Fatalf("got status_code=%d, want status_code=%d", got, http.StatusOK)
+		}
+		if err := srv.wait(t); err != nil {
+			t.Errorf("got err=%v after shutdown, want nil", err)
+		}
+	})
+}
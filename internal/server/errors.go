@@ -0,0 +1,102 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/realHoangHai/awesome/pkg/utils/response"
+	"net"
+	"net/http"
+)
+
+// gatewayErrorHandler renders the errors of the gateway like the ones of the other HTTP handlers,
+// see response.Error. The header metadata of the call are forwarded with the Grpc-Metadata- prefix.
+func gatewayErrorHandler(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Del("Trailer")
+	w.Header().Del("Transfer-Encoding")
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		for k, vs := range md.HeaderMD {
+			for _, v := range vs {
+				w.Header().Add(runtime.MetadataHeaderPrefix+k, v)
+			}
+		}
+	}
+	// i.e... 404 and 405 of the routing.
+	var custom *runtime.HTTPStatusError
+	if errors.As(err, &custom) {
+		response.ErrorWithStatus(w, r, custom.HTTPStatus, custom.Err)
+		return
+	}
+	response.Error(w, r, err)
+}
+
+// recoveryHTTPInterceptor recovers the HTTP handlers and interceptors from panics,
+// rendering the error returned by the recovery handler. The error is only logged
+// if the response has already been sent.
+func (s *Server) recoveryHTTPInterceptor(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &recoveryWriter{ResponseWriter: w}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			// aborting the response on purpose.
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			err := s.recovery(r.Context(), p)
+			if rw.sent {
+				s.getLogger().Context(r.Context()).Errorf("server: panic after the response is sent, err: %v", err)
+				return
+			}
+			response.Error(w, r, err)
+		}()
+		h.ServeHTTP(rw, r)
+	})
+}
+
+// recoveryWriter is a http.ResponseWriter tracking whether the response has been sent,
+// i.e... its header written or its connection hijacked.
+type recoveryWriter struct {
+	http.ResponseWriter
+	sent bool
+}
+
+// WriteHeader implements http.ResponseWriter, the informational responses do not send the response.
+func (rw *recoveryWriter) WriteHeader(code int) {
+	if code >= http.StatusOK {
+		rw.sent = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements http.ResponseWriter.
+func (rw *recoveryWriter) Write(p []byte) (int, error) {
+	rw.sent = true
+	return rw.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher.
+func (rw *recoveryWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		rw.sent = true
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, i.e... for the WebSocket of the stream bridge.
+func (rw *recoveryWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	rw.sent = true
+	return hj.Hijack()
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, see http.ResponseController.
+func (rw *recoveryWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,134 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/realHoangHai/awesome/internal/server"
+	"github.com/realHoangHai/awesome/pkg/i18n"
+	"github.com/realHoangHai/awesome/pkg/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// detailsService is echoService with a gateway route failing with error details.
+type detailsService struct {
+	echoService
+}
+
+func (s *detailsService) RegisterWithEndpoint(ctx context.Context, mux *runtime.ServeMux, addr string, opts []grpc.DialOption) {
+	_ = mux.HandlePath(http.MethodGet, "/v1/details", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		err := status.Build(codes.InvalidArgument, "invalid user").FieldViolation("email", "invalid email").Err()
+		runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, err)
+	})
+}
+
+func TestErrorRendering(t *testing.T) {
+	srv := startServer(t, []server.Service{&detailsService{}},
+		server.Recovery(nil),
+		server.HandlerFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+			panic("handler failed")
+		}),
+		server.HandlerFunc("/panic/sent", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = io.WriteString(w, "partial")
+			panic("handler failed")
+		}),
+		server.HTTPInterceptors(func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/interceptor/panic" {
+					panic("interceptor failed")
+				}
+				h.ServeHTTP(w, r)
+			})
+		}),
+	)
+
+	cases := []struct {
+		name       string
+		path       string
+		accept     string
+		wantStatus int
+		wantCode   codes.Code
+	}{
+		{name: "gateway not found", path: "/v1/unknown", wantStatus: http.StatusNotFound, wantCode: codes.NotFound},
+		{name: "gateway not found as problem", path: "/v1/unknown", accept: status.ProblemContentType, wantStatus: http.StatusNotFound, wantCode: codes.NotFound},
+		{name: "gateway details", path: "/v1/details", wantStatus: http.StatusBadRequest, wantCode: codes.InvalidArgument},
+		{name: "handler panic", path: "/panic", wantStatus: http.StatusInternalServerError, wantCode: codes.Internal},
+		{name: "interceptor panic", path: "/interceptor/panic", wantStatus: http.StatusInternalServerError, wantCode: codes.Internal},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "http://"+srv.addr+c.path, nil)
+			if c.accept != "" {
+				req.Header.Set("Accept", c.accept)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != c.wantStatus {
+				t.Errorf("got status=%d, want status=%d", resp.StatusCode, c.wantStatus)
+			}
+			b, _ := io.ReadAll(resp.Body)
+			if c.accept == status.ProblemContentType {
+				var p status.Problem
+				if ct := resp.Header.Get("Content-Type"); ct != status.ProblemContentType {
+					t.Errorf("got content_type=%s, want %s", ct, status.ProblemContentType)
+				}
+				if err := json.Unmarshal(b, &p); err != nil || p.Code != c.wantCode || p.Status != c.wantStatus {
+					t.Errorf("got problem=%s, err=%v, want code=%v", b, err, c.wantCode)
+				}
+				return
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("got content_type=%s, want application/json", ct)
+			}
+			s, err := status.Parse(b)
+			if err != nil {
+				t.Fatalf("got body=%s, want a status, err: %v", b, err)
+			}
+			if s.Code() != c.wantCode {
+				t.Errorf("got code=%v, want code=%v", s.Code(), c.wantCode)
+			}
+			if c.path == "/v1/details" {
+				if v := status.FieldViolations(s.Err()); len(v) != 1 || v[0].Field != "email" {
+					t.Errorf("got field violations=%v, want the email one", v)
+				}
+			}
+		})
+	}
+
+	// the error of a panic after the response is sent is not rendered.
+	resp, err := http.Get("http://" + srv.addr + "/panic/sent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if b, _ := io.ReadAll(resp.Body); resp.StatusCode != http.StatusAccepted || string(b) != "partial" {
+		t.Errorf("got status=%d, body=%s, want the response sent before the panic", resp.StatusCode, b)
+	}
+}
+
+func TestAcceptLanguageForwarded(t *testing.T) {
+	mux := runtime.NewServeMux(server.DefaultHeaderMatcher())
+	var got string
+	_ = mux.HandlePath(http.MethodGet, "/v1/language", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		ctx, err := runtime.AnnotateIncomingContext(r.Context(), mux, r, "/test.Echo/Echo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = i18n.AcceptLanguage(ctx)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/v1/language", nil)
+	req.Header.Set("Accept-Language", "vi-VN,en;q=0.5")
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+	if got != "vi-VN,en;q=0.5" {
+		t.Errorf("got accept_language=%q, want the header forwarded", got)
+	}
+}
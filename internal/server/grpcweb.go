@@ -0,0 +1,256 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+	// grpcWebTrailerFlag marks the gRPC-Web frame holding the trailers.
+	grpcWebTrailerFlag = 0x80
+)
+
+var (
+	// webRequestHeaders are the request headers of gRPC-Web and Connect allowed by CORS.
+	webRequestHeaders = []string{
+		"Content-Type", "X-Grpc-Web", "X-User-Agent", "Grpc-Timeout",
+		"Connect-Protocol-Version", "Connect-Timeout-Ms", "Connect-Content-Encoding", "Connect-Accept-Encoding",
+	}
+	// webResponseHeaders are the response headers of gRPC-Web and Connect exposed by CORS.
+	webResponseHeaders = []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin", "Grpc-Encoding", "Connect-Content-Encoding"}
+)
+
+type (
+	// webProtocols serves the gRPC-Web and Connect requests by the gRPC server in-process,
+	// so that they go through the same interceptors as gRPC.
+	webProtocols struct {
+		grpcWeb    bool
+		connect    bool
+		grpcServer *grpc.Server
+
+		methodsOnce sync.Once
+		methods     map[string]bool
+	}
+
+	// frameWriter adapts the response of the gRPC server to a protocol sending the trailers
+	// in a final frame of the body, i.e... gRPC-Web and Connect streaming. The headers are sent
+	// on the first flush, the messages are passed through as they are flushed.
+	frameWriter struct {
+		w           http.ResponseWriter
+		header      http.Header
+		code        int
+		wroteHeader bool
+		contentType string
+		// encodingHeader is the response header of the message encoding.
+		encodingHeader string
+		// text is true to encode the body in base64, i.e... grpc-web-text.
+		text bool
+		buf  bytes.Buffer
+		// endFrame returns the final frame from the trailers.
+		endFrame func(trailer http.Header) []byte
+	}
+)
+
+// handler returns the handler of the protocol of the request, nil if it is neither gRPC-Web nor Connect.
+func (p *webProtocols) handler(r *http.Request) http.HandlerFunc {
+	if r.Method != http.MethodPost {
+		return nil
+	}
+	ct := r.Header.Get("Content-Type")
+	switch {
+	case p.grpcWeb && strings.HasPrefix(ct, grpcWebContentType):
+		return p.serveGRPCWeb
+	case p.connect && strings.HasPrefix(ct, connectStreamContentType):
+		return p.serveConnectStream
+	case p.connect && isConnectUnaryContentType(ct) && p.isMethod(r.URL.Path):
+		return p.serveConnectUnary
+	}
+	return nil
+}
+
+// isMethod reports whether the path is a method registered to the gRPC server.
+func (p *webProtocols) isMethod(path string) bool {
+	p.methodsOnce.Do(func() {
+		p.methods = make(map[string]bool)
+		for name, info := range p.grpcServer.GetServiceInfo() {
+			for _, m := range info.Methods {
+				p.methods["/"+name+"/"+m.Name] = true
+			}
+		}
+	})
+	return p.methods[path]
+}
+
+// serveGRPCWeb serves a gRPC-Web request, binary or text, the trailers are sent in the last frame of the body.
+func (p *webProtocols) serveGRPCWeb(w http.ResponseWriter, r *http.Request) {
+	ct := r.Header.Get("Content-Type")
+	text := strings.HasPrefix(ct, grpcWebTextContentType)
+	subtype := contentSubtype(ct)
+	var body io.Reader = r.Body
+	if text {
+		body = base64.NewDecoder(base64.StdEncoding, r.Body)
+	}
+	respType := grpcWebContentType + "+" + subtype
+	if text {
+		respType = grpcWebTextContentType + "+" + subtype
+	}
+	fw := &frameWriter{
+		w:              w,
+		header:         make(http.Header),
+		contentType:    respType,
+		encodingHeader: "Grpc-Encoding",
+		text:           text,
+		endFrame:       grpcWebTrailerFrame,
+	}
+	p.grpcServer.ServeHTTP(fw, grpcRequest(r, subtype, body))
+	fw.finish()
+}
+
+// grpcWebTrailerFrame returns the gRPC-Web frame of the trailers, encoded as HTTP/1 headers.
+func grpcWebTrailerFrame(trailer http.Header) []byte {
+	keys := make([]string, 0, len(trailer))
+	for k := range trailer {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b bytes.Buffer
+	for _, k := range keys {
+		for _, v := range trailer[k] {
+			fmt.Fprintf(&b, "%s: %s\r\n", strings.ToLower(k), v)
+		}
+	}
+	return frame(grpcWebTrailerFlag, b.Bytes())
+}
+
+// grpcRequest returns a gRPC request of the given content subtype, served by the gRPC server in-process.
+func grpcRequest(r *http.Request, subtype string, body io.Reader) *http.Request {
+	req := r.Clone(r.Context())
+	// the gRPC server requires HTTP/2, the request is served in-process whatever the transport is.
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/2.0", 2, 0
+	req.Header.Set("Content-Type", "application/grpc+"+subtype)
+	req.Header.Del("Content-Length")
+	req.ContentLength = -1
+	req.Body = io.NopCloser(body)
+	return req
+}
+
+// contentSubtype returns the subtype of a content type, i.e... json of application/grpc-web+json.
+// The default is proto.
+func contentSubtype(ct string) string {
+	if i := strings.IndexByte(ct, ';'); i >= 0 {
+		ct = ct[:i]
+	}
+	if i := strings.IndexByte(ct, '+'); i >= 0 && i < len(ct)-1 {
+		return strings.TrimSpace(ct[i+1:])
+	}
+	return "proto"
+}
+
+// frame returns a length-prefixed frame with the given flags.
+func frame(flags byte, data []byte) []byte {
+	b := make([]byte, 5+len(data))
+	b[0] = flags
+	binary.BigEndian.PutUint32(b[1:5], uint32(len(data)))
+	copy(b[5:], data)
+	return b
+}
+
+// grpcTrailers returns the trailers set by the gRPC server in the response header.
+func grpcTrailers(h http.Header) http.Header {
+	t := make(http.Header)
+	for k, vv := range h {
+		switch {
+		case strings.HasPrefix(k, http2.TrailerPrefix):
+			k = http.CanonicalHeaderKey(strings.TrimPrefix(k, http2.TrailerPrefix))
+			t[k] = append(t[k], vv...)
+		case k == "Grpc-Status" || k == "Grpc-Message" || k == "Grpc-Status-Details-Bin":
+			t[k] = append(t[k], vv...)
+		}
+	}
+	return t
+}
+
+// Header implements http.ResponseWriter.
+func (fw *frameWriter) Header() http.Header {
+	return fw.header
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (fw *frameWriter) WriteHeader(code int) {
+	if fw.code == 0 {
+		fw.code = code
+	}
+}
+
+// Write implements http.ResponseWriter, the data is sent on the next flush.
+func (fw *frameWriter) Write(b []byte) (int, error) {
+	return fw.buf.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (fw *frameWriter) Flush() {
+	fw.writeHeader()
+	if fw.buf.Len() > 0 {
+		if fw.text && !fw.rejected() {
+			// each flush is encoded separately so that the messages are not delayed.
+			_, _ = fw.w.Write([]byte(base64.StdEncoding.EncodeToString(fw.buf.Bytes())))
+		} else {
+			_, _ = fw.w.Write(fw.buf.Bytes())
+		}
+		fw.buf.Reset()
+	}
+	if f, ok := fw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (fw *frameWriter) writeHeader() {
+	if fw.wroteHeader {
+		return
+	}
+	fw.wroteHeader = true
+	h := fw.w.Header()
+	for k, vv := range fw.header {
+		switch {
+		case k == "Trailer" || k == "Content-Type" || strings.HasPrefix(k, http2.TrailerPrefix):
+		case k == "Grpc-Encoding":
+			h[fw.encodingHeader] = vv
+		default:
+			h[k] = vv
+		}
+	}
+	if fw.rejected() {
+		h.Set("Content-Type", fw.header.Get("Content-Type"))
+		fw.w.WriteHeader(fw.code)
+		return
+	}
+	h.Set("Content-Type", fw.contentType)
+	fw.w.WriteHeader(http.StatusOK)
+}
+
+// rejected reports whether the request is rejected by the gRPC server before being handled,
+// i.e... an invalid timeout, the response is sent as is.
+func (fw *frameWriter) rejected() bool {
+	return fw.code != 0 && fw.code != http.StatusOK
+}
+
+// finish sends the final frame of the trailers.
+func (fw *frameWriter) finish() {
+	if fw.rejected() {
+		fw.Flush()
+		return
+	}
+	_, _ = fw.buf.Write(fw.endFrame(grpcTrailers(fw.header)))
+	fw.Flush()
+}
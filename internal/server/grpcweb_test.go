@@ -0,0 +1,240 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/realHoangHai/awesome/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// echoService is a test service with a unary method echoing its request, failing on "fail",
+// and a server streaming method sending the given number of messages.
+type echoService struct{}
+
+func (s *echoService) Register(srv *grpc.Server) {
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Echo",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Echo",
+				Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+					in := &wrapperspb.StringValue{}
+					if err := dec(in); err != nil {
+						return nil, err
+					}
+					handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+						_ = grpc.SetTrailer(ctx, metadata.Pairs("x-echo", "done"))
+						if in.Value == "fail" {
+							return nil, status.Error(codes.InvalidArgument, "echo failed")
+						}
+						return in, nil
+					}
+					return interceptor(ctx, in, &grpc.UnaryServerInfo{FullMethod: "/test.Echo/Echo"}, handler)
+				},
+			},
+		},
+		Streams: []grpc.StreamDesc{
+			{
+				StreamName:    "Count",
+				ServerStreams: true,
+				Handler: func(_ interface{}, stream grpc.ServerStream) error {
+					in := &wrapperspb.Int32Value{}
+					if err := stream.RecvMsg(in); err != nil {
+						return err
+					}
+					for i := int32(0); i < in.Value; i++ {
+						if err := stream.SendMsg(wrapperspb.String(fmt.Sprint(i))); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			},
+		},
+	}, s)
+}
+
+// webFrame is a length-prefixed frame of gRPC-Web or Connect.
+type webFrame struct {
+	flags byte
+	data  []byte
+}
+
+func encodeFrame(flags byte, msg proto.Message) []byte {
+	b, _ := proto.Marshal(msg)
+	out := make([]byte, 5, 5+len(b))
+	out[0] = flags
+	binary.BigEndian.PutUint32(out[1:], uint32(len(b)))
+	return append(out, b...)
+}
+
+func decodeFrames(t *testing.T, b []byte) []webFrame {
+	t.Helper()
+	var frames []webFrame
+	for len(b) > 0 {
+		if len(b) < 5 {
+			t.Fatalf("got truncated frame: %v", b)
+		}
+		n := int(binary.BigEndian.Uint32(b[1:5]))
+		frames = append(frames, webFrame{flags: b[0], data: b[5 : 5+n]})
+		b = b[5+n:]
+	}
+	return frames
+}
+
+// decodeText decodes a grpc-web-text body, made of padded base64 chunks.
+func decodeText(t *testing.T, s string) []byte {
+	t.Helper()
+	var out []byte
+	for i := 0; i+4 <= len(s); i += 4 {
+		b, err := base64.StdEncoding.DecodeString(s[i : i+4])
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, b...)
+	}
+	return out
+}
+
+func TestWebProtocols(t *testing.T) {
+	srv := startServer(t, []server.Service{&echoService{}},
+		server.GRPCWeb(),
+		server.Connect(),
+		server.CORS(false, nil, nil, []string{"https://example.com"}),
+	)
+	post := func(path, contentType string, body []byte, hdr ...string) (*http.Response, []byte) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, "http://"+srv.addr+path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		for j := 0; j+1 < len(hdr); j += 2 {
+			req.Header.Set(hdr[j], hdr[j+1])
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp, b
+	}
+
+	t.Run("grpc-web unary", func(t *testing.T) {
+		resp, body := post("/test.Echo/Echo", "application/grpc-web+proto", encodeFrame(0, wrapperspb.String("hello")))
+		if ct := resp.Header.Get("Content-Type"); ct != "application/grpc-web+proto" {
+			t.Fatalf("got content_type=%s, want application/grpc-web+proto", ct)
+		}
+		frames := decodeFrames(t, body)
+		if len(frames) != 2 {
+			t.Fatalf("got %d frames, want a message and the trailers", len(frames))
+		}
+		msg := &wrapperspb.StringValue{}
+		if err := proto.Unmarshal(frames[0].data, msg); err != nil || msg.Value != "hello" {
+			t.Errorf("got message=%v, err=%v, want hello", msg, err)
+		}
+		trailer := string(frames[1].data)
+		if frames[1].flags != 0x80 || !strings.Contains(trailer, "grpc-status: 0\r\n") || !strings.Contains(trailer, "x-echo: done\r\n") {
+			t.Errorf("got trailer flags=%x, trailer=%q, want grpc-status 0 and x-echo", frames[1].flags, trailer)
+		}
+	})
+
+	t.Run("grpc-web-text server streaming", func(t *testing.T) {
+		body := base64.StdEncoding.EncodeToString(encodeFrame(0, wrapperspb.Int32(3)))
+		resp, b := post("/test.Echo/Count", "application/grpc-web-text", []byte(body))
+		if ct := resp.Header.Get("Content-Type"); ct != "application/grpc-web-text+proto" {
+			t.Fatalf("got content_type=%s, want application/grpc-web-text+proto", ct)
+		}
+		frames := decodeFrames(t, decodeText(t, string(b)))
+		if len(frames) != 4 {
+			t.Fatalf("got %d frames, want 3 messages and the trailers", len(frames))
+		}
+		if !strings.Contains(string(frames[3].data), "grpc-status: 0") {
+			t.Errorf("got trailer=%q, want grpc-status 0", frames[3].data)
+		}
+	})
+
+	t.Run("grpc-web error", func(t *testing.T) {
+		_, body := post("/test.Echo/Echo", "application/grpc-web", encodeFrame(0, wrapperspb.String("fail")))
+		frames := decodeFrames(t, body)
+		if len(frames) != 1 || !strings.Contains(string(frames[0].data), fmt.Sprintf("grpc-status: %d", codes.InvalidArgument)) {
+			t.Errorf("got frames=%q, want trailers with status InvalidArgument", frames)
+		}
+	})
+
+	t.Run("connect unary", func(t *testing.T) {
+		resp, body := post("/test.Echo/Echo", "application/json", []byte(`"hello"`), "Connect-Protocol-Version", "1")
+		if resp.StatusCode != http.StatusOK || string(body) != `"hello"` {
+			t.Errorf("got status_code=%d, body=%s, want hello", resp.StatusCode, body)
+		}
+		if v := resp.Header.Get("Trailer-X-Echo"); v != "done" {
+			t.Errorf("got trailer x-echo=%q, want done", v)
+		}
+		resp, body = post("/test.Echo/Echo", "application/proto", []byte{})
+		if resp.StatusCode != http.StatusOK || len(body) != 0 {
+			t.Errorf("got status_code=%d, body=%v, want an empty message", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("connect unary error", func(t *testing.T) {
+		resp, body := post("/test.Echo/Echo", "application/json", []byte(`"fail"`))
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("got status_code=%d, want status_code=%d", resp.StatusCode, http.StatusBadRequest)
+		}
+		var e struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &e); err != nil || e.Code != "invalid_argument" || e.Message != "echo failed" {
+			t.Errorf("got error=%+v, err=%v, want invalid_argument", e, err)
+		}
+	})
+
+	t.Run("connect server streaming", func(t *testing.T) {
+		resp, body := post("/test.Echo/Count", "application/connect+proto", encodeFrame(0, wrapperspb.Int32(2)))
+		if ct := resp.Header.Get("Content-Type"); ct != "application/connect+proto" {
+			t.Fatalf("got content_type=%s, want application/connect+proto", ct)
+		}
+		frames := decodeFrames(t, body)
+		if len(frames) != 3 || frames[2].flags != 0x02 {
+			t.Fatalf("got frames=%v, want 2 messages and the end of the stream", frames)
+		}
+		if end := string(frames[2].data); strings.Contains(end, "error") {
+			t.Errorf("got end of stream=%s, want no error", end)
+		}
+	})
+
+	t.Run("other requests are served by the router", func(t *testing.T) {
+		resp, _ := post("/test.Unknown/Call", "application/json", []byte(`{}`))
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("got status_code=%d, want status_code=%d", resp.StatusCode, http.StatusNotFound)
+		}
+	})
+
+	t.Run("cors preflight", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodOptions, "http://"+srv.addr+"/test.Echo/Echo", nil)
+		req.Header.Set("Origin", "https://example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web,connect-protocol-version")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Access-Control-Allow-Headers"), "X-Grpc-Web") {
+			t.Errorf("got status_code=%d, allow_headers=%q, want gRPC-Web headers allowed", resp.StatusCode, resp.Header.Get("Access-Control-Allow-Headers"))
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package server_test
+
+import (
+	"context"
+	"github.com/realHoangHai/awesome/internal/server"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	// testServer is a server serving on a local listener for the duration of a test.
+	testServer struct {
+		*server.Server
+		// addr is the address of the listener, i.e... 127.0.0.1:34567.
+		addr   string
+		cancel context.CancelFunc
+		done   chan error
+
+		once sync.Once
+		err  error
+	}
+
+	// acceptListener is a net.Listener reporting that the server started serving on its first Accept.
+	acceptListener struct {
+		net.Listener
+		once      sync.Once
+		accepting chan struct{}
+	}
+)
+
+// startServer runs a server of the given options and services on a local listener, and returns once it serves.
+// The listener and a shutdown timeout of 1s are set first so that they can be replaced by the options.
+// The server is stopped at the end of the test if it is not stopped before.
+func startServer(t *testing.T, services []server.Service, opts ...server.Option) *testServer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis := &acceptListener{Listener: l, accepting: make(chan struct{})}
+	srv := server.New(append([]server.Option{server.Listener(lis), server.ShutdownTimeout(time.Second)}, opts...)...)
+	ctx, cancel := context.WithCancel(context.Background())
+	ts := &testServer{Server: srv, addr: l.Addr().String(), cancel: cancel, done: make(chan error, 1)}
+	go func() {
+		ts.done <- srv.RunWithContext(ctx, services...)
+	}()
+	t.Cleanup(func() {
+		ts.stop(t)
+	})
+	select {
+	case <-lis.accepting:
+	case err := <-ts.done:
+		ts.done <- err
+		t.Fatalf("server did not start, err: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not start")
+	}
+	return ts
+}
+
+// stop cancels the context of the server and waits for it to stop.
+func (ts *testServer) stop(t *testing.T) error {
+	t.Helper()
+	ts.cancel()
+	return ts.wait(t)
+}
+
+// wait waits for the server to stop and returns the error of RunWithContext.
+func (ts *testServer) wait(t *testing.T) error {
+	t.Helper()
+	ts.once.Do(func() {
+		select {
+		case ts.err = <-ts.done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("server did not stop")
+		}
+	})
+	return ts.err
+}
+
+// Accept implements net.Listener.
+func (l *acceptListener) Accept() (net.Conn, error) {
+	l.once.Do(func() {
+		close(l.accepting)
+	})
+	return l.Listener.Accept()
+}
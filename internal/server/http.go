@@ -1,12 +1,26 @@
 package server
 
 import (
+	"github.com/realHoangHai/awesome/pkg/status"
+	"github.com/realHoangHai/awesome/pkg/utils/response"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
+// precompressedEncodings are the encodings of the precompressed files served by spaHandler,
+// in order of preference, i.e... app.js.br for app.js.
+var precompressedEncodings = []string{brotliEncoding, zstdEncoding, gzipEncoding}
+
+// precompressedExtensions are the file extensions of the precompressed encodings.
+var precompressedExtensions = map[string]string{
+	brotliEncoding: ".br",
+	zstdEncoding:   ".zst",
+	gzipEncoding:   ".gz",
+}
+
 type (
 	// HandlerOptions hold information of a HTTP handler options.
 	HandlerOptions struct {
@@ -19,6 +33,8 @@ type (
 		q            []string
 		hdr          []string
 		prefix       bool
+		admin        bool
+		internal     bool
 		interceptors []HTTPInterceptor
 	}
 
@@ -66,6 +82,21 @@ func (r *HandlerOptions) Headers(headers ...string) *HandlerOptions {
 	return r
 }
 
+// Admin marks that the HTTP handler is an admin handler, protected by the AdminAuth authenticator.
+// Admin handlers are also internal handlers.
+func (r *HandlerOptions) Admin() *HandlerOptions {
+	r.admin = true
+	r.internal = true
+	return r
+}
+
+// Internal marks that the HTTP handler is an internal handler,
+// served on the admin listener instead of the public one if AdminAddress is set.
+func (r *HandlerOptions) Internal() *HandlerOptions {
+	r.internal = true
+	return r
+}
+
 // Interceptors adds interceptors into the handler.
 func (r *HandlerOptions) Interceptors(interceptors ...HTTPInterceptor) *HandlerOptions {
 	r.interceptors = interceptors
@@ -111,16 +142,55 @@ func (h spaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	_, err := os.Stat(path)
 	if os.IsNotExist(err) {
 		// file does not exist, serve index.html
-		http.ServeFile(w, r, filepath.Join(h.dir, h.index))
+		h.serveFile(w, r, filepath.Join(h.dir, h.index))
 		return
 	}
 	// other errors, response with error
 	if err != nil {
 		// if we got an error (that wasn't that the file doesn't exist) stating the
 		// file, return a 500 internal server error and stop
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		response.Error(w, r, status.Internal(err.Error()))
 		return
 	}
 	// otherwise, serve the file
+	h.serveFile(w, r, path)
+}
+
+// serveFile serves the precompressed file of the encoding accepted if any, i.e... app.js.gz
+// for app.js, so that the assets are not compressed on the fly. The file is served otherwise.
+func (h spaHandler) serveFile(w http.ResponseWriter, r *http.Request, path string) {
+	addVary(w.Header(), "Accept-Encoding")
+	encodings := precompressedEncodings
+	for len(encodings) > 0 {
+		enc := negotiateEncoding(r.Header.Get("Accept-Encoding"), encodings)
+		if enc == "" {
+			break
+		}
+		if f, err := os.Open(path + precompressedExtensions[enc]); err == nil {
+			defer f.Close()
+			if fi, err := f.Stat(); err == nil && !fi.IsDir() {
+				// not sniffed from the compressed content.
+				ct := mime.TypeByExtension(filepath.Ext(path))
+				if ct == "" {
+					ct = "application/octet-stream"
+				}
+				w.Header().Set("Content-Type", ct)
+				w.Header().Set("Content-Encoding", enc)
+				http.ServeContent(w, r, path, fi.ModTime(), f)
+				return
+			}
+		}
+		encodings = removeEncoding(encodings, enc)
+	}
 	http.ServeFile(w, r, path)
 }
+
+func removeEncoding(encodings []string, enc string) []string {
+	out := make([]string, 0, len(encodings))
+	for _, e := range encodings {
+		if e != enc {
+			out = append(out, e)
+		}
+	}
+	return out
+}
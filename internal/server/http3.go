@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// altSvcMaxAge is the max age in seconds of the HTTP/3 advertisement.
+const altSvcMaxAge = 86400
+
+type (
+	// HTTP3Server is an HTTP/3 server serving over QUIC, i.e... *http3.Server of github.com/quic-go/quic-go/http3.
+	// If it implements Shutdown(ctx context.Context) error, it is used for a graceful shutdown instead of Close.
+	HTTP3Server interface {
+		// ListenAndServe listens on the UDP address and serves until the server is closed.
+		ListenAndServe() error
+		Close() error
+	}
+
+	// NewHTTP3Server returns an HTTP/3 server listening on the given UDP address,
+	// with the TLS config and handler of the HTTP server.
+	NewHTTP3Server func(addr string, cfg *tls.Config, h http.Handler) HTTP3Server
+)
+
+// checkHTTP3 reports an error if HTTP/3 is enabled with the graceful upgrade, as the UDP socket
+// of the HTTP/3 server is not handed over to the new process which would fail to listen on it.
+func (s *Server) checkHTTP3() error {
+	if s.newHTTP3 != nil && s.upgradeEnabled {
+		return errors.New("server: HTTP3 cannot be used with GracefulUpgrade")
+	}
+	return nil
+}
+
+// serveHTTP3 serves the handler over HTTP/3 on the UDP port of the HTTP listener in the background,
+// and returns the handler of the HTTP listener advertising HTTP/3 by Alt-Svc.
+func (s *Server) serveHTTP3(ep *endpoint, tlsCfg *tls.Config, handler http.Handler, errChan chan<- error) (http.Handler, error) {
+	addr, ok := ep.lis.Addr().(*net.TCPAddr)
+	if !ok {
+		return nil, fmt.Errorf("server: HTTP/3 requires a TCP listener, got %s", ep.lis.Addr().Network())
+	}
+	udpAddr := net.JoinHostPort(addr.IP.String(), fmt.Sprint(addr.Port))
+	s.http3Srv = s.newHTTP3(udpAddr, tlsCfg.Clone(), handler)
+	go func() {
+		errChan <- s.http3Srv.ListenAndServe()
+	}()
+	altSvc := fmt.Sprintf(`h3=":%d"; ma=%d`, addr.Port, altSvcMaxAge)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Alt-Svc", altSvc)
+		handler.ServeHTTP(w, r)
+	}), nil
+}
+
+// shutdownHTTP3 gracefully shutdowns the HTTP/3 server if supported, closes it otherwise.
+func (s *Server) shutdownHTTP3(ctx context.Context) error {
+	if srv, ok := s.http3Srv.(interface {
+		Shutdown(ctx context.Context) error
+	}); ok {
+		return srv.Shutdown(ctx)
+	}
+	return s.http3Srv.Close()
+}
@@ -0,0 +1,113 @@
+package server_test
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/realHoangHai/awesome/internal/server"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeHTTP3 records the HTTP/3 server created by the server, it serves until shutdown.
+type fakeHTTP3 struct {
+	addr     string
+	cfg      *tls.Config
+	handler  http.Handler
+	shutdown chan struct{}
+}
+
+func (f *fakeHTTP3) ListenAndServe() error {
+	<-f.shutdown
+	return http.ErrServerClosed
+}
+
+func (f *fakeHTTP3) Close() error {
+	close(f.shutdown)
+	return nil
+}
+
+func (f *fakeHTTP3) Shutdown(ctx context.Context) error {
+	return f.Close()
+}
+
+func TestHTTP3(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, 1)
+	created := make(chan *fakeHTTP3, 1)
+	srv := startServer(t, nil,
+		server.TLS(keyFile, certFile),
+		server.HTTP3(func(addr string, cfg *tls.Config, h http.Handler) server.HTTP3Server {
+			f := &fakeHTTP3{addr: addr, cfg: cfg, handler: h, shutdown: make(chan struct{})}
+			created <- f
+			return f
+		}),
+		server.HandlerFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprintf(w, "HTTP/%d", r.ProtoMajor)
+		}),
+	)
+
+	var h3 *fakeHTTP3
+	select {
+	case h3 = <-created:
+	case <-time.After(5 * time.Second):
+		t.Fatal("HTTP/3 server not created")
+	}
+	if h3.addr != srv.addr || h3.cfg == nil || len(h3.cfg.Certificates) == 0 && h3.cfg.GetCertificate == nil {
+		t.Errorf("got addr=%s, tls=%v, want the address and certificates of the HTTP listener", h3.addr, h3.cfg)
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get("https://" + srv.addr + "/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	_, port, _ := net.SplitHostPort(srv.addr)
+	if got, want := resp.Header.Get("Alt-Svc"), fmt.Sprintf(`h3=":%s"; ma=86400`, port); got != want {
+		t.Errorf("got alt_svc=%q, want alt_svc=%q", got, want)
+	}
+
+	// the HTTP/3 handler routes as the HTTP listener.
+	req := httptest.NewRequest(http.MethodGet, "https://"+srv.addr+"/hello", nil)
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/3.0", 3, 0
+	rec := httptest.NewRecorder()
+	h3.handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "HTTP/3" {
+		t.Errorf("got status_code=%d, body=%s, want HTTP/3 served by the route", rec.Code, rec.Body.String())
+	}
+
+	srv.stop(t)
+	select {
+	case <-h3.shutdown:
+	default:
+		t.Error("HTTP/3 server not shutdown")
+	}
+}
+
+func TestHTTP3WithGracefulUpgrade(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, 1)
+	srv := server.New(
+		server.Address("127.0.0.1:0"),
+		server.TLS(keyFile, certFile),
+		server.HTTP3(func(addr string, cfg *tls.Config, h http.Handler) server.HTTP3Server {
+			return &fakeHTTP3{addr: addr, cfg: cfg, handler: h, shutdown: make(chan struct{})}
+		}),
+		server.GracefulUpgrade(time.Second),
+		server.ShutdownTimeout(time.Second),
+	)
+	// the server stops at once if it starts.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := srv.RunWithContext(ctx); err == nil || !strings.Contains(err.Error(), "GracefulUpgrade") {
+		t.Fatalf("got err=%v, want HTTP3 refused with GracefulUpgrade", err)
+	}
+}
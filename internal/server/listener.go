@@ -0,0 +1,242 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"github.com/realHoangHai/awesome/pkg/certs"
+	"github.com/realHoangHai/awesome/pkg/proxyproto"
+	"github.com/realHoangHai/awesome/pkg/systemd"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// names of the listeners, used for handing them over to the next process in a graceful upgrade.
+	listenerMain  = "main"
+	listenerGRPC  = "grpc"
+	listenerHTTP  = "http"
+	listenerAdmin = "admin"
+
+	// unixScheme is the address scheme of unix domain sockets, i.e... unix:///run/awesome.sock.
+	unixScheme = "unix://"
+	// systemdScheme is the address scheme of listeners passed by systemd socket activation,
+	// i.e... systemd://http, using the FileDescriptorName= of the socket unit, or systemd://0 using its index.
+	systemdScheme = "systemd://"
+)
+
+// endpoint holds the configuration of a dedicated listener,
+// used when gRPC and HTTP are served on separated addresses.
+type endpoint struct {
+	address     string
+	lis         net.Listener
+	tlsCertFile string
+	tlsKeyFile  string
+	// tlsSource serves the certificates if no certificate file is configured, i.e... ACME.
+	tlsSource certs.Source
+
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	// connectionTimeout is the connection establishment timeout of the gRPC listener,
+	// only the shared listener falls back to the read timeout.
+	connectionTimeout time.Duration
+}
+
+// isSet reports whether the endpoint has its own address or listener.
+func (e *endpoint) isSet() bool {
+	return e != nil && (e.address != "" || e.lis != nil)
+}
+
+// isSecured reports whether TLS is configured for the endpoint.
+func (e *endpoint) isSecured() bool {
+	return (e.tlsCertFile != "" && e.tlsKeyFile != "") || e.tlsSource != nil
+}
+
+// dialAddress returns the address used to dial the endpoint.
+// The listener address is used unless the configured one is a host and a fixed port,
+// i.e... it is empty, using a random port or passed by systemd.
+func (e *endpoint) dialAddress() string {
+	if e.lis.Addr().Network() == "unix" {
+		return unixTarget(e.lis.Addr().String())
+	}
+	if _, port, err := net.SplitHostPort(e.address); err == nil && !strings.Contains(e.address, "://") {
+		if p, err := strconv.Atoi(port); err == nil && p > 0 {
+			return e.address
+		}
+	}
+	return e.lis.Addr().String()
+}
+
+// inherit fills the missing TLS and timeout settings of the endpoint with the server's settings.
+func (e *endpoint) inherit(s *Server) {
+	if !e.isSecured() {
+		e.tlsCertFile = s.tlsCertFile
+		e.tlsKeyFile = s.tlsKeyFile
+	}
+	if !e.isSecured() {
+		e.tlsSource = s.tlsSource
+	}
+	if e.readTimeout == 0 {
+		e.readTimeout = s.readTimeout
+	}
+	if e.writeTimeout == 0 {
+		e.writeTimeout = s.writeTimeout
+	}
+}
+
+// isSeparated reports whether gRPC and HTTP are served on separated listeners.
+func (s *Server) isSeparated() bool {
+	return s.grpcEndpoint.isSet() || s.httpEndpoint.isSet()
+}
+
+// resolveEndpoints returns the endpoints used for serving gRPC and HTTP.
+// If the address of one of them is not configured, the server's address will be used for it.
+func (s *Server) resolveEndpoints() (grpcEp *endpoint, httpEp *endpoint) {
+	resolve := func(e *endpoint) *endpoint {
+		rs := endpoint{}
+		if e != nil {
+			rs = *e
+		}
+		if !rs.isSet() {
+			rs.address = s.address
+			rs.lis = s.lis
+		}
+		rs.inherit(s)
+		return &rs
+	}
+	return resolve(s.grpcEndpoint), resolve(s.httpEndpoint)
+}
+
+func (s *Server) getGRPCEndpoint() *endpoint {
+	if s.grpcEndpoint == nil {
+		s.grpcEndpoint = &endpoint{}
+	}
+	return s.grpcEndpoint
+}
+
+func (s *Server) getAdminEndpoint() *endpoint {
+	if s.adminEndpoint == nil {
+		s.adminEndpoint = &endpoint{}
+	}
+	return s.adminEndpoint
+}
+
+func (s *Server) getHTTPEndpoint() *endpoint {
+	if s.httpEndpoint == nil {
+		s.httpEndpoint = &endpoint{}
+	}
+	return s.httpEndpoint
+}
+
+// listen returns the given listener if it is not nil. Otherwise, it reuses the listener with
+// the same name inherited from the parent process in a graceful upgrade or announces on the given address.
+// The listener is tracked by its name so that it can be handed over in the next upgrade.
+func (s *Server) listen(name string, lis net.Listener, address string) (net.Listener, error) {
+	if lis == nil {
+		inherited, err := inheritedListener(name)
+		if err != nil {
+			return nil, err
+		}
+		lis = inherited
+	}
+	if lis == nil {
+		l, err := listen(address, s.unixSocketMode)
+		if err != nil {
+			return nil, err
+		}
+		lis = l
+	}
+	if s.listeners == nil {
+		s.listeners = make(map[string]net.Listener)
+	}
+	s.listeners[name] = lis
+	return lis, nil
+}
+
+// checkProxyProtocol reports an error if the PROXY protocol is enabled without trusted proxies,
+// as any client could then forge its address.
+func (s *Server) checkProxyProtocol() error {
+	if s.proxyProtocol && len(s.trustedProxies) == 0 {
+		return errors.New("server: ProxyProtocol requires TrustedProxies")
+	}
+	return nil
+}
+
+// proxyListener returns the listener accepting the PROXY protocol headers of the trusted proxies.
+// The listener is returned as is if the PROXY protocol is disabled.
+func (s *Server) proxyListener(lis net.Listener) net.Listener {
+	if !s.proxyProtocol {
+		return lis
+	}
+	if _, ok := lis.(*proxyproto.Listener); ok {
+		return lis
+	}
+	return proxyproto.NewListener(lis, s.clientIP.Trusted)
+}
+
+// listen announces on the given address. Beside tcp addresses, it supports
+// unix domain sockets using unix:// scheme and systemd socket activation using systemd:// scheme.
+// The unix socket file mode is changed to the given mode if it is not zero.
+func listen(address string, mode os.FileMode) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(address, systemdScheme):
+		return systemd.Listener(strings.TrimPrefix(address, systemdScheme))
+	case strings.HasPrefix(address, unixScheme):
+		return listenUnix(strings.TrimPrefix(address, unixScheme), mode)
+	}
+	return net.Listen("tcp", address)
+}
+
+// listenUnix opens a unix domain socket listener at the given path.
+// A stale socket file left by a previous process is removed before listening.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			_ = lis.Close()
+			return nil, err
+		}
+	}
+	return lis, nil
+}
+
+// removeStaleSocket removes the socket file at the given path if no one is listening on it.
+func removeStaleSocket(path string) error {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("server: %s exists and is not a unix socket", path)
+	}
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("server: unix socket %s is already in use", path)
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// unixTarget returns gRPC dial target of the given unix socket path.
+func unixTarget(path string) string {
+	if filepath.IsAbs(path) {
+		return unixScheme + path
+	}
+	return "unix:" + path
+}
@@ -0,0 +1,193 @@
+package server_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/realHoangHai/awesome/internal/auth"
+	"github.com/realHoangHai/awesome/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+)
+
+// whoService is a test service returning the SPIFFE ID of the authenticated client,
+// its gateway endpoint is GET /whoami.
+type whoService struct{}
+
+func (s *whoService) Register(srv *grpc.Server) {
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Who",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Call",
+				Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+					in := &emptypb.Empty{}
+					if err := dec(in); err != nil {
+						return nil, err
+					}
+					handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+						if id, _ := auth.IdentityFromContext(ctx); id != nil {
+							return wrapperspb.String(id.SPIFFEID), nil
+						}
+						return wrapperspb.String(""), nil
+					}
+					return interceptor(ctx, in, &grpc.UnaryServerInfo{FullMethod: "/test.Who/Call"}, handler)
+				},
+			},
+		},
+	}, s)
+}
+
+func (s *whoService) RegisterWithEndpoint(ctx context.Context, mux *runtime.ServeMux, addr string, opts []grpc.DialOption) {
+	conn, err := grpc.DialContext(ctx, addr, opts...)
+	if err != nil {
+		panic(err)
+	}
+	_ = mux.HandlePath(http.MethodGet, "/whoami", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		ctx, err := runtime.AnnotateContext(r.Context(), mux, r, "/test.Who/Call")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		out := &wrapperspb.StringValue{}
+		if err := conn.Invoke(ctx, "/test.Who/Call", &emptypb.Empty{}, out); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, out.Value)
+	})
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := issueCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil)
+	caFile := filepath.Join(dir, "ca.crt")
+	ca.write(t, caFile, filepath.Join(dir, "ca.key"))
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	issueCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		URIs:         []*url.URL{{Scheme: "spiffe", Host: "example.org", Path: "/server"}},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}, ca).write(t, certFile, keyFile)
+	client := issueCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "alice"},
+		URIs:         []*url.URL{{Scheme: "spiffe", Host: "example.org", Path: "/alice"}},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	srv := startServer(t, []server.Service{&whoService{}},
+		server.TLS(keyFile, certFile),
+		server.MutualTLS(caFile, tls.VerifyClientCertIfGiven),
+		server.Auth(auth.MTLS(nil)),
+	)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	newTLSConfig := func(withCert bool) *tls.Config {
+		cfg := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+		if withCert {
+			cfg.Certificates = []tls.Certificate{client.tlsCertificate()}
+		}
+		return cfg
+	}
+
+	t.Run("gRPC", func(t *testing.T) {
+		for _, withCert := range []bool{true, false} {
+			conn, err := grpc.Dial(srv.addr, grpc.WithTransportCredentials(credentials.NewTLS(newTLSConfig(withCert))), grpc.WithBlock())
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := &wrapperspb.StringValue{}
+			err = conn.Invoke(context.Background(), "/test.Who/Call", &emptypb.Empty{}, out)
+			conn.Close()
+			if withCert && (err != nil || out.Value != "spiffe://example.org/alice") {
+				t.Fatalf("got id=%s, err=%v, want id=spiffe://example.org/alice", out.Value, err)
+			}
+			if !withCert && err == nil {
+				t.Fatalf("got id=%s, want error without client certificate", out.Value)
+			}
+		}
+	})
+
+	t.Run("gateway", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			withCert bool
+			header   http.Header
+			want     string
+		}{
+			{
+				name:     "identity is forwarded",
+				withCert: true,
+				want:     "spiffe://example.org/alice",
+			},
+			{
+				name: "gateway identity is not used without client certificate",
+			},
+			{
+				name:   "forged identity is rejected",
+				header: http.Header{"Grpc-Metadata-" + auth.IdentityMD: []string{"e30.e30"}},
+			},
+		}
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				hc := &http.Client{Transport: &http.Transport{TLSClientConfig: newTLSConfig(c.withCert)}}
+				req, _ := http.NewRequest(http.MethodGet, "https://"+srv.addr+"/whoami", nil)
+				for k, v := range c.header {
+					req.Header[k] = v
+				}
+				resp, err := hc.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				b, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if c.want == "" {
+					if resp.StatusCode == http.StatusOK {
+						t.Fatalf("got status_code=%d, id=%s, want error", resp.StatusCode, b)
+					}
+					return
+				}
+				if resp.StatusCode != http.StatusOK || string(b) != c.want {
+					t.Fatalf("got status_code=%d, id=%s, want id=%s", resp.StatusCode, b, c.want)
+				}
+			})
+		}
+	})
+}
+
+func TestMutualTLSUnverifiedModes(t *testing.T) {
+	for _, mode := range []tls.ClientAuthType{tls.RequestClientCert, tls.RequireAnyClientCert} {
+		srv := server.New(
+			server.Address("127.0.0.1:0"),
+			server.MutualTLS(filepath.Join(t.TempDir(), "ca.crt"), mode),
+		)
+		if err := srv.Run(); err == nil {
+			t.Fatalf("got server started with mode=%v, want error", mode)
+		}
+	}
+}
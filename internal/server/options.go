@@ -2,7 +2,9 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"github.com/go-redis/redis/v8"
 	"github.com/gorilla/handlers"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
@@ -11,8 +13,13 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/realHoangHai/awesome/config"
+	"github.com/realHoangHai/awesome/internal/access"
 	"github.com/realHoangHai/awesome/internal/auth"
+	"github.com/realHoangHai/awesome/internal/concurrency"
 	"github.com/realHoangHai/awesome/internal/health"
+	"github.com/realHoangHai/awesome/internal/ratelimit"
+	"github.com/realHoangHai/awesome/pkg/certs"
+	"github.com/realHoangHai/awesome/pkg/i18n"
 	"github.com/realHoangHai/awesome/pkg/jwt"
 	"github.com/realHoangHai/awesome/pkg/log"
 	"github.com/realHoangHai/awesome/pkg/utils/header"
@@ -25,11 +32,14 @@ import (
 	"net/http/pprof"
 	"net/textproto"
 	"os"
+	"strings"
 	"time"
 )
 
 const (
 	defaultAddr = ":8088"
+	// defaultAdminScope is the scope required in the JWT of admin requests.
+	defaultAdminScope = "admin"
 )
 
 // FromEnv is an option to create a new server from environment variables configuration.
@@ -49,13 +59,42 @@ func FromConfig(cfg *config.Config) Option {
 			Address(cfg.Core.Address),
 			TLS(cfg.Core.TLSKeyFile, cfg.Core.TLSCertFile),
 			Timeout(cfg.Core.ReadTimeout, cfg.Core.WriteTimeout),
+			GRPCAddress(cfg.Core.GRPCAddress),
+			GRPCTLS(cfg.Core.GRPCTLSKeyFile, cfg.Core.GRPCTLSCertFile),
+			GRPCConnectionTimeout(cfg.Core.GRPCConnectionTimeout),
+			HTTPAddress(cfg.Core.HTTPAddress),
+			HTTPTLS(cfg.Core.HTTPTLSKeyFile, cfg.Core.HTTPTLSCertFile),
+			HTTPTimeout(cfg.Core.HTTPReadTimeout, cfg.Core.HTTPWriteTimeout),
+			UnixSocketMode(os.FileMode(cfg.Core.UnixSocketMode)),
 			JWT(cfg.Core.JWTSecret),
 			APIPrefix(cfg.Core.APIPrefix),
 			CORS(cfg.Core.CORSAllowedCredential, cfg.Core.CORSAllowedHeaders, cfg.Core.CORSAllowedMethods, cfg.Core.CORSAllowedOrigins),
 			ShutdownTimeout(cfg.Core.ShutdownTimeout),
+			ShutdownDrainPeriod(cfg.Core.ShutdownDrainPeriod),
 			RoutesPrioritization(cfg.Core.RoutesPrioritization),
 			ShutdownHook(cfg.Core.ShutdownHook),
 		}
+		if cfg.Core.GRPCWeb {
+			opts = append(opts, GRPCWeb())
+		}
+		if cfg.Core.Connect {
+			opts = append(opts, Connect())
+		}
+		if cfg.Core.StreamBridge {
+			opts = append(opts, StreamBridge(cfg.Core.CORSAllowedOrigins...))
+		}
+		if cfg.Core.ProxyProtocol {
+			opts = append(opts, ProxyProtocol())
+		}
+		if len(cfg.Core.TrustedProxies) > 0 {
+			opts = append(opts, TrustedProxies(cfg.Core.TrustedProxies...))
+		}
+		if len(cfg.Core.Compression) > 0 {
+			opts = append(opts, Compression(cfg.Core.CompressionMinSize, cfg.Core.CompressionTypes, cfg.Core.Compression...))
+		}
+		if len(cfg.Core.GRPCCompressors) > 0 {
+			opts = append(opts, GRPCCompressors(cfg.Core.GRPCCompressors...))
+		}
 		if cfg.Core.Metrics {
 			opts = append(opts, Metrics(cfg.Core.MetricsPath))
 		}
@@ -78,9 +117,60 @@ func FromConfig(cfg *config.Config) Option {
 		if cfg.Core.Recovery {
 			opts = append(opts, Recovery(nil))
 		}
+		if cfg.Core.Validation {
+			opts = append(opts, Validation())
+		}
 		if cfg.Core.PProf {
 			opts = append(opts, PProf(cfg.Core.PProfPrefix))
 		}
+		if cfg.AutoTLS.Enabled {
+			opts = append(opts, AutoTLS(cfg.AutoTLS))
+		}
+		if cfg.Core.TLSClientCAFile != "" {
+			mode, err := certs.ParseClientAuth(cfg.Core.TLSClientAuth)
+			if err != nil {
+				// fail closed, the strictest mode is used.
+				server.getLogger().Errorf("server: %v, require and verify client certificates", err)
+				mode = tls.RequireAndVerifyClientCert
+			}
+			opts = append(opts, MutualTLS(cfg.Core.TLSClientCAFile, mode))
+		}
+		if cfg.Access.Enabled {
+			opts = append(opts, accessControlFromConfig(cfg, server.getLogger()))
+		}
+		if cfg.RateLimit.Enabled {
+			opts = append(opts, rateLimitFromConfig(cfg, server.getLogger()))
+		}
+		if cfg.I18n.Enabled {
+			c, err := i18n.Load(cfg.I18n.DefaultLocale, cfg.I18n.Dir)
+			if err != nil {
+				server.getLogger().Errorf("server: localization, err: %v", err)
+			} else {
+				opts = append(opts, Localization(c))
+			}
+		}
+		if cfg.Admin.Address != "" {
+			opts = append(opts, AdminAddress(cfg.Admin.Address))
+		}
+		if cfg.Admin.Reflection {
+			opts = append(opts, Reflection())
+		}
+		if cfg.Admin.Channelz {
+			opts = append(opts, Channelz(cfg.Core.PProfPrefix))
+		}
+		if cfg.Admin.Control {
+			opts = append(opts, AdminControl(cfg.Admin.ControlPrefix))
+		}
+		opts = append(opts, AdminScope(adminScope(cfg)))
+		if cfg.Admin.JWTSecret != "" {
+			opts = append(opts, AdminAuth(jwt.ScopeAuthenticator([]byte(cfg.Admin.JWTSecret), adminScope(cfg))))
+		}
+		if cfg.Concurrency.Enabled {
+			opts = append(opts, concurrencyLimitFromConfig(cfg.Concurrency, server.getLogger()))
+		}
+		if cfg.Core.GracefulUpgrade {
+			opts = append(opts, GracefulUpgrade(cfg.Core.UpgradeTimeout))
+		}
 		// apply all
 		for _, opt := range opts {
 			opt(server)
@@ -90,6 +180,9 @@ func FromConfig(cfg *config.Config) Option {
 
 // Address is an option to set address.
 // Default address is :8000
+// Beside tcp addresses, unix domain sockets (unix:///run/awesome.sock) and
+// listeners passed by systemd socket activation (systemd://name or systemd://index)
+// are supported.
 func Address(addr string) Option {
 	return func(opts *Server) {
 		opts.address = addr
@@ -111,6 +204,103 @@ func Listener(lis net.Listener) Option {
 	}
 }
 
+// UnixSocketMode is an option to set file mode of the unix domain sockets
+// created by the server, i.e... 0660.
+func UnixSocketMode(mode os.FileMode) Option {
+	return func(opts *Server) {
+		opts.unixSocketMode = mode
+	}
+}
+
+// GRPCAddress is an option to serve gRPC on a dedicated address
+// instead of sharing the same port with HTTP.
+// The gRPC gateway will dial this address automatically.
+func GRPCAddress(addr string) Option {
+	return func(opts *Server) {
+		if addr == "" {
+			return
+		}
+		opts.getGRPCEndpoint().address = addr
+		opts.grpcEndpoint.lis = nil
+	}
+}
+
+// GRPCListener is an option allows gRPC to be served on an existing dedicated listener.
+func GRPCListener(lis net.Listener) Option {
+	return func(opts *Server) {
+		opts.getGRPCEndpoint().address = lis.Addr().String()
+		opts.grpcEndpoint.lis = lis
+	}
+}
+
+// GRPCTLS is an option to set TLS for the dedicated gRPC listener.
+// If not set, the TLS configured by TLS option will be used.
+func GRPCTLS(key, cert string) Option {
+	return func(opts *Server) {
+		if key == "" || cert == "" {
+			return
+		}
+		opts.getGRPCEndpoint().tlsKeyFile = key
+		opts.grpcEndpoint.tlsCertFile = cert
+	}
+}
+
+// GRPCConnectionTimeout is an option to set the connection establishment timeout,
+// including HTTP/2 handshaking, of the gRPC listener. The read timeout is used by default
+// if gRPC and HTTP share the same port.
+func GRPCConnectionTimeout(d time.Duration) Option {
+	return func(opts *Server) {
+		if d == 0 {
+			return
+		}
+		opts.getGRPCEndpoint().connectionTimeout = d
+	}
+}
+
+// HTTPAddress is an option to serve HTTP (gRPC Gateway and HTTP handlers)
+// on a dedicated address instead of sharing the same port with gRPC.
+func HTTPAddress(addr string) Option {
+	return func(opts *Server) {
+		if addr == "" {
+			return
+		}
+		opts.getHTTPEndpoint().address = addr
+		opts.httpEndpoint.lis = nil
+	}
+}
+
+// HTTPListener is an option allows HTTP to be served on an existing dedicated listener.
+func HTTPListener(lis net.Listener) Option {
+	return func(opts *Server) {
+		opts.getHTTPEndpoint().address = lis.Addr().String()
+		opts.httpEndpoint.lis = lis
+	}
+}
+
+// HTTPTLS is an option to set TLS for the dedicated HTTP listener.
+// If not set, the TLS configured by TLS option will be used.
+func HTTPTLS(key, cert string) Option {
+	return func(opts *Server) {
+		if key == "" || cert == "" {
+			return
+		}
+		opts.getHTTPEndpoint().tlsKeyFile = key
+		opts.httpEndpoint.tlsCertFile = cert
+	}
+}
+
+// HTTPTimeout is an option to set read/write timeout of the dedicated HTTP listener.
+// If not set, the timeout configured by Timeout option will be used.
+func HTTPTimeout(read, write time.Duration) Option {
+	return func(opts *Server) {
+		if read == 0 && write == 0 {
+			return
+		}
+		opts.getHTTPEndpoint().readTimeout = read
+		opts.httpEndpoint.writeTimeout = write
+	}
+}
+
 // CorrelationIDStreamInterceptor returns a grpc.StreamServerInterceptor that provides
 // a context with correlation_id for tracing. It will try to looks for value of X-Correlation-ID or X-Request-ID
 // in the metadata of the incoming request. If no value is provided, a new UUID will be generated.
@@ -206,6 +396,130 @@ func TLS(key, cert string) Option {
 	}
 }
 
+// AutoTLS is an option to serve TLS without providing certificates, it overrides TLS option.
+// In self_signed mode (default), a local CA and a certificate of the hosts are generated and cached
+// in the folder for development. In acme mode, the certificates are obtained from the ACME CA
+// at the directory URL, i.e... Let's Encrypt or a local Pebble for testing, and cached in the folder.
+// The certificates are renewed in the background before they expire.
+func AutoTLS(cfg config.SectionAutoTLS) Option {
+	return func(opts *Server) {
+		opts.autoTLS = &cfg
+	}
+}
+
+// RateLimit is an option to limit the requests of both gRPC and HTTP using the given limiter.
+// gRPC requests are limited by full method after authentication, HTTP requests including
+// the ones to gRPC Gateway are limited by URL.Path. The gateway calls of the HTTP requests limited
+// by path are not limited again, the other ones are limited by method keyed by the forwarded client IP.
+func RateLimit(l *ratelimit.Limiter) Option {
+	return func(opts *Server) {
+		opts.rateLimiter = l
+	}
+}
+
+// rateLimitFromConfig returns RateLimit option of the rules in the config.
+// Invalid algorithms fall back to the default, so that the limits still apply, invalid keys make the server
+// refuse to start as the clients sharing a limit would not be the expected ones.
+func rateLimitFromConfig(cfg *config.Config, logger log.Logger) Option {
+	var store ratelimit.Store = ratelimit.NewMemoryStore()
+	var hooks []Option
+	if strings.ToLower(cfg.RateLimit.Store) == "redis" {
+		client := redis.NewClient(&redis.Options{
+			Addr:         cfg.Redis.Addr,
+			ReadTimeout:  cfg.Redis.ReadTimeout,
+			WriteTimeout: cfg.Redis.WriteTimeout,
+		})
+		store = ratelimit.NewRedisStore(client, "ratelimit:")
+		hooks = append(hooks, CleanupHooks(func() { _ = client.Close() }))
+	}
+	limitOpts := []ratelimit.Option{ratelimit.Logger(logger)}
+	for _, r := range cfg.RateLimit.Rules {
+		alg, err := ratelimit.ParseAlgorithm(r.Algorithm)
+		if err != nil {
+			logger.Errorf("server: rate limit %s, use %s, err: %v", r.Method, ratelimit.TokenBucket, err)
+			alg = ratelimit.TokenBucket
+		}
+		key, err := ratelimit.ParseKey(r.Method, r.Key)
+		if err != nil {
+			hooks = append(hooks, configError(fmt.Errorf("server: rate limit %s: %w", r.Method, err)))
+			continue
+		}
+		limitOpts = append(limitOpts, ratelimit.WithRule(r.Method, ratelimit.Rule{
+			Algorithm: alg,
+			Limit:     r.Limit,
+			Period:    r.Period,
+			Burst:     r.Burst,
+			Key:       key,
+		}))
+	}
+	return func(opts *Server) {
+		RateLimit(ratelimit.New(store, limitOpts...))(opts)
+		for _, hook := range hooks {
+			hook(opts)
+		}
+	}
+}
+
+// ConcurrencyLimit is an option to limit the in-flight gRPC requests of each method by an adaptive limit,
+// the requests exceeding the limit are rejected with codes.Unavailable, the less critical first.
+// The criticality of a request is set by x-criticality metadata. When Metrics option is enabled,
+// the limits and the in-flight requests are exposed as grpc_concurrency_* metrics.
+func ConcurrencyLimit(l *concurrency.Limiter) Option {
+	return func(opts *Server) {
+		opts.concurrencyLimiter = l
+	}
+}
+
+// adminScope returns the scope required in the admin JWT, default is admin.
+func adminScope(cfg *config.Config) string {
+	if cfg.Admin.Scope != "" {
+		return cfg.Admin.Scope
+	}
+	return defaultAdminScope
+}
+
+// concurrencyLimitFromConfig returns ConcurrencyLimit option of the config.
+func concurrencyLimitFromConfig(cfg config.SectionConcurrency, logger log.Logger) Option {
+	newAlgorithm := func() concurrency.Algorithm {
+		return &concurrency.AIMD{Timeout: cfg.Timeout}
+	}
+	switch strings.ToLower(cfg.Algorithm) {
+	case "", "aimd":
+	case "gradient":
+		newAlgorithm = func() concurrency.Algorithm {
+			return &concurrency.Gradient{}
+		}
+	default:
+		logger.Errorf("server: unknown concurrency limit algorithm %q, use aimd", cfg.Algorithm)
+	}
+	return ConcurrencyLimit(concurrency.New(
+		concurrency.WithAlgorithm(newAlgorithm),
+		concurrency.Limits(cfg.InitialLimit, cfg.MinLimit, cfg.MaxLimit),
+	))
+}
+
+// MutualTLS is an option to authenticate clients by their TLS certificates, which are verified
+// against the CA bundle in the given file according to the given mode. If mode is tls.NoClientCert,
+// tls.RequireAndVerifyClientCert is used, tls.RequestClientCert and tls.RequireAnyClientCert are rejected
+// on start since they do not verify the certificates. It takes effect on the listeners serving TLS only.
+// The identity of the clients calling via the gRPC gateway is forwarded to the gRPC services,
+// use auth.MTLS to authenticate and auth.IdentityFromContext to fetch the identity.
+//
+// If the client certificates are required, the server certificate must also be valid
+// as a client certificate, since the gRPC gateway presents it when dialing the gRPC server.
+func MutualTLS(caFile string, mode tls.ClientAuthType) Option {
+	return func(opts *Server) {
+		if caFile == "" {
+			return
+		}
+		if mode == tls.NoClientCert {
+			mode = tls.RequireAndVerifyClientCert
+		}
+		opts.clientCAFile = caFile
+		opts.clientAuth = mode
+	}
+}
+
 // Timeout is an option to override default read/write timeout.
 func Timeout(read, write time.Duration) Option {
 	if read == 0 {
@@ -217,7 +531,6 @@ func Timeout(read, write time.Duration) Option {
 	return func(opts *Server) {
 		opts.readTimeout = read
 		opts.writeTimeout = write
-		opts.serverOptions = append(opts.serverOptions, grpc.ConnectionTimeout(read))
 	}
 }
 
@@ -355,7 +668,8 @@ func Web(pathPrefix, dir, index string) Option {
 }
 
 // Recovery is an option allows user to add an ability to recover a handler/API from a panic.
-// This applies for both unary and stream handlers/APIs.
+// This applies for both unary and stream handlers/APIs, and for the HTTP handlers and interceptors
+// whose error is rendered by response.Error.
 // If the provided error handler is nil, a default error handler will be used.
 func Recovery(handler func(context.Context, interface{}) error) Option {
 	return func(opts *Server) {
@@ -365,6 +679,7 @@ func Recovery(handler func(context.Context, interface{}) error) Option {
 			}
 			handler = recoveryHandler(opts.log)
 		}
+		opts.recovery = handler
 		recoverOpt := recovery.WithRecoveryHandlerContext(handler)
 		opts.unaryInterceptors = append(opts.unaryInterceptors, recovery.UnaryServerInterceptor(recoverOpt))
 		opts.streamInterceptors = append(opts.streamInterceptors, recovery.StreamServerInterceptor(recoverOpt))
@@ -388,37 +703,303 @@ func CORS(allowCredential bool, headers, methods, origins []string) Option {
 			options = append(options, handlers.AllowedOrigins(origins))
 		}
 		if len(options) > 0 {
-			opts.httpInterceptors = append(opts.httpInterceptors, handlers.CORS(options...))
+			opts.httpInterceptors = append(opts.httpInterceptors, func(h http.Handler) http.Handler {
+				// the interceptors are applied once all options are set.
+				cors := options[:len(options):len(options)]
+				if opts.grpcWeb || opts.connect {
+					cors = append(cors, handlers.AllowedHeaders(webRequestHeaders), handlers.ExposedHeaders(webResponseHeaders))
+				}
+				return handlers.CORS(cors...)(h)
+			})
+		}
+	}
+}
+
+// GRPCWeb is an option to serve gRPC-Web requests, binary and text, over HTTP/1.1 and HTTP/2
+// by the gRPC server, including server streaming, so that browsers can call the services
+// without REST mappings. Cross-origin requests require the CORS option, which then allows
+// the gRPC-Web headers.
+func GRPCWeb() Option {
+	return func(opts *Server) {
+		opts.grpcWeb = true
+	}
+}
+
+// HTTP3 is an option to serve the HTTP handlers: the gateway, Web and the other routes, over HTTP/3
+// on the UDP port of the HTTP listener, with the same TLS config and interceptors. It is advertised
+// to the clients of the HTTP listener by the Alt-Svc header, and requires TLS. It cannot be used with
+// GracefulUpgrade as the HTTP/3 server listens on its own UDP socket. The HTTP/3 server is
+// created by newServer, i.e... with github.com/quic-go/quic-go/http3:
+//
+//	server.HTTP3(func(addr string, cfg *tls.Config, h http.Handler) server.HTTP3Server {
+//		return &http3.Server{Addr: addr, TLSConfig: cfg, Handler: h}
+//	})
+func HTTP3(newServer NewHTTP3Server) Option {
+	return func(opts *Server) {
+		opts.newHTTP3 = newServer
+	}
+}
+
+// StreamBridge is an option to expose the streaming RPCs registered to the gateway by EndpointService:
+// server streams as Server-Sent Events to GET requests accepting text/event-stream, and all
+// kinds of streams over WebSocket, the HTTP method of the RPC is given by the method query parameter,
+// default is POST. Each WebSocket message is a JSON message of the request stream, an empty message
+// closes the request stream. The requests are authenticated by the authenticator set by Auth or JWT
+// option before being upgraded, the token is read from the Authorization header or cookie.
+// WebSocket requests are accepted from the same origin or from the given origins, "*" for any.
+func StreamBridge(origins ...string) Option {
+	return func(opts *Server) {
+		opts.streamBridge = &streamBridge{origins: origins}
+	}
+}
+
+// Connect is an option to serve the Connect protocol over HTTP/1.1 and HTTP/2 by the gRPC server:
+// unary calls with application/proto or application/json bodies posted to the gRPC method paths,
+// i.e... /helloworld.Greeter/SayHello, and streaming calls with application/connect+proto or
+// application/connect+json. Cross-origin requests require the CORS option, which then allows
+// the Connect headers.
+func Connect() Option {
+	return func(opts *Server) {
+		opts.connect = true
+	}
+}
+
+// ProxyProtocol is an option to accept the PROXY protocol v1 and v2 headers sent by TCP load balancers
+// on the public listeners, so that the remote address of the connections is the one of the clients.
+// The headers are accepted from the TrustedProxies only, which are required. The header is optional,
+// i.e... for the gateway dialing the gRPC listener.
+func ProxyProtocol() Option {
+	return func(opts *Server) {
+		opts.proxyProtocol = true
+	}
+}
+
+// TrustedProxies is an option to resolve the client IP behind the proxies of the given IPs or CIDRs,
+// i.e... 10.0.0.0/8, from the X-Forwarded-For or Forwarded headers of the HTTP requests and the forwarded
+// metadata of the gRPC requests. The client IP is attached to the context of the requests for logging,
+// rate limiting and auditing, use clientip.FromContext to fetch it.
+func TrustedProxies(proxies ...string) Option {
+	return func(opts *Server) {
+		opts.trustedProxies = append(opts.trustedProxies, proxies...)
+	}
+}
+
+// AccessControl is an option to restrict the methods to networks using the given controller,
+// i.e... the admin and internal methods to the private networks. gRPC requests are restricted by full method
+// and HTTP requests by URL.Path, on the client IP resolved behind the TrustedProxies if any.
+// The rules are replaced at runtime by ReloadAccessRules or on SIGHUP, using the AccessRulesSource.
+func AccessControl(c *access.Controller) Option {
+	return func(opts *Server) {
+		opts.accessControl = c
+	}
+}
+
+// Validation is an option to reject the invalid requests of the gRPC handlers and the gateway with
+// codes.InvalidArgument and their field violations as errdetails.BadRequest. The requests are checked by
+// their Validate method, i.e... generated by protoc-gen-go-validate, or by their validate tags otherwise.
+func Validation() Option {
+	return func(opts *Server) {
+		opts.validation = true
+	}
+}
+
+// Localization is an option to attach the messages of the catalog to the errors of the gRPC handlers and
+// the gateway as errdetails.LocalizedMessage, in the locale negotiated from the Accept-Language header
+// forwarded by the gateway or the accept-language metadata. The messages of the errors are kept for the logs.
+func Localization(c *i18n.Catalog) Option {
+	return func(opts *Server) {
+		opts.catalog = c
+	}
+}
+
+// AccessRulesSource is an option to set the source of the rules loaded by ReloadAccessRules.
+func AccessRulesSource(f func() ([]access.Rule, error)) Option {
+	return func(opts *Server) {
+		opts.accessRules = f
+	}
+}
+
+// configError is an option to make the server refuse to start with the given error of the config.
+// The first error is kept.
+func configError(err error) Option {
+	return func(opts *Server) {
+		if opts.configErr == nil {
+			opts.configErr = err
 		}
 	}
 }
 
-// PProf is an option allows user to enable Go profiler.
+// accessControlFromConfig returns AccessControl option of the rules in the config,
+// reloaded from the config file. The server refuses to start with invalid rules,
+// as the methods they restrict would be open otherwise.
+func accessControlFromConfig(cfg *config.Config, logger log.Logger) Option {
+	rules, err := accessRules(cfg.Access)
+	return func(opts *Server) {
+		if err != nil {
+			configError(fmt.Errorf("server: access control: %w", err))(opts)
+		}
+		AccessControl(access.New(rules, access.Logger(logger)))(opts)
+		AccessRulesSource(func() ([]access.Rule, error) {
+			cfg, err := config.Reload()
+			if err != nil {
+				return nil, err
+			}
+			return accessRules(cfg.Access)
+		})(opts)
+	}
+}
+
+// accessRules parses the rules of the access section, returning the valid ones along with the first error.
+func accessRules(cfg config.SectionAccess) ([]access.Rule, error) {
+	var rules []access.Rule
+	var first error
+	for i, r := range cfg.Rules {
+		rule, err := access.ParseRule(r.Methods, r.Allow, r.Deny)
+		if err != nil {
+			if first == nil {
+				first = fmt.Errorf("rule %d: %w", i, err)
+			}
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, first
+}
+
+// Compression is an option to compress the HTTP responses with the encoding negotiated from Accept-Encoding,
+// among the given encodings in order of preference, default is gzip. Only the responses of at least minSize
+// bytes, default is 1KB, and of the given content types are compressed, i.e... text/ for all the text types
+// or +json for all the JSON based types, default are the text, JSON, JavaScript, XML and SVG types.
+// The responses already encoded, i.e... the precompressed assets of Web, are written as is.
+// gzip, br and zstd are built in, the other encodings require an Encoder. gRPC, gRPC-Web and Connect responses
+// are not affected, see GRPCCompressors.
+func Compression(minSize int, types []string, encodings ...string) Option {
+	return func(opts *Server) {
+		opts.compression = newCompressor(minSize, types, encodings)
+	}
+}
+
+// Encoding is an option to add or replace the Encoder of a content encoding used by Compression,
+// i.e... deflate:
+//
+//	server.Encoding("deflate", func(w io.Writer) (io.WriteCloser, error) {
+//		return flate.NewWriter(w, flate.DefaultCompression)
+//	})
+func Encoding(name string, e Encoder) Option {
+	return func(opts *Server) {
+		if opts.encoders == nil {
+			opts.encoders = make(map[string]Encoder)
+		}
+		opts.encoders[name] = e
+	}
+}
+
+// GRPCCompressors is an option to register the gRPC compressors of the given names, i.e... gzip, so that
+// compressed requests are accepted, the responses are compressed with the compressor of their request.
+// gzip and zstd are built in, the others, i.e... snappy, must be registered by encoding.RegisterCompressor.
+// As compressors are registered globally, it must be used at initialization.
+func GRPCCompressors(names ...string) Option {
+	return func(opts *Server) {
+		for _, name := range registerGRPCCompressors(names) {
+			opts.getLogger().Warnf("server: gRPC compressor %s is not registered", name)
+		}
+	}
+}
+
+// PProf is an option allows user to enable Go profiler, its routes are protected by AdminAuth if set.
 func PProf(pathPrefix string) Option {
 	return func(opts *Server) {
 		opts.routes = append(opts.routes, HandlerOptions{
-			p: pathPrefix + "/debug/pprof/",
-			h: http.HandlerFunc(pprof.Index),
+			p:        pathPrefix + "/debug/pprof/",
+			h:        http.HandlerFunc(pprof.Index),
+			admin:    true,
+			internal: true,
 		})
 		opts.routes = append(opts.routes, HandlerOptions{
-			p: pathPrefix + "/debug/pprof/cmdline",
-			h: http.HandlerFunc(pprof.Cmdline),
+			p:        pathPrefix + "/debug/pprof/cmdline",
+			h:        http.HandlerFunc(pprof.Cmdline),
+			admin:    true,
+			internal: true,
 		})
 		opts.routes = append(opts.routes, HandlerOptions{
-			p: pathPrefix + "/debug/pprof/profile",
-			h: http.HandlerFunc(pprof.Profile),
+			p:        pathPrefix + "/debug/pprof/profile",
+			h:        http.HandlerFunc(pprof.Profile),
+			admin:    true,
+			internal: true,
 		})
 		opts.routes = append(opts.routes, HandlerOptions{
-			p: pathPrefix + "/debug/pprof/symbol",
-			h: http.HandlerFunc(pprof.Symbol),
+			p:        pathPrefix + "/debug/pprof/symbol",
+			h:        http.HandlerFunc(pprof.Symbol),
+			admin:    true,
+			internal: true,
 		})
 		opts.routes = append(opts.routes, HandlerOptions{
-			p: pathPrefix + "/debug/pprof/trace",
-			h: http.HandlerFunc(pprof.Trace),
+			p:        pathPrefix + "/debug/pprof/trace",
+			h:        http.HandlerFunc(pprof.Trace),
+			admin:    true,
+			internal: true,
 		})
 	}
 }
 
+// Reflection is an option to register gRPC server reflection service, allowing tools like grpcurl
+// to discover the APIs. It is protected by AdminAuth, the server refuses to start without it.
+func Reflection() Option {
+	return func(opts *Server) {
+		opts.reflection = true
+	}
+}
+
+// Channelz is an option to register gRPC channelz service, and an HTTP view of its data at
+// pathPrefix/debug/channelz/, i.e... /debug/channelz/servers. Both are protected by AdminAuth,
+// the server refuses to start without it.
+func Channelz(pathPrefix string) Option {
+	return func(opts *Server) {
+		p := pathPrefix + "/debug/channelz/"
+		opts.channelz = newChannelzService(p)
+		opts.routes = append(opts.routes, HandlerOptions{
+			p:        p,
+			h:        opts.channelz,
+			m:        []string{http.MethodGet},
+			prefix:   true,
+			admin:    true,
+			internal: true,
+		})
+	}
+}
+
+// AdminAddress is an option to serve the internal HTTP handlers: metrics, pprof, channelz, health check
+// and the handlers marked by HandlerOptions.Internal or HandlerOptions.Admin, on a dedicated address
+// instead of the public one, along with an index of all the registered handlers at /.
+// The admin listener uses the TLS configured by TLS option, its index is protected by AdminAuth if set.
+func AdminAddress(addr string) Option {
+	return func(opts *Server) {
+		if addr == "" {
+			return
+		}
+		opts.getAdminEndpoint().address = addr
+		opts.adminEndpoint.lis = nil
+	}
+}
+
+// AdminListener is an option allows the internal HTTP handlers to be served on an existing dedicated listener.
+// See AdminAddress.
+func AdminListener(lis net.Listener) Option {
+	return func(opts *Server) {
+		opts.getAdminEndpoint().address = lis.Addr().String()
+		opts.adminEndpoint.lis = lis
+	}
+}
+
+// AdminAuth is an option to protect the admin services and routes: gRPC reflection, channelz,
+// pprof and the routes marked by HandlerOptions.Admin, by the given authenticator instead of
+// the one set by Auth or JWT option. See jwt.ScopeAuthenticator for requiring an admin scope.
+func AdminAuth(a auth.Authenticator) Option {
+	return func(opts *Server) {
+		opts.adminAuth = a
+	}
+}
+
 // Metrics is an option to register standard Prometheus metrics for HTTP.
 // Default path is /internal/metrics.
 func Metrics(path string) Option {
@@ -430,14 +1011,17 @@ func Metrics(path string) Option {
 		}
 		opts.enableMetrics = true
 		opts.routes = append(opts.routes, HandlerOptions{
-			p: p,
-			h: promhttp.Handler(),
-			m: []string{http.MethodGet},
+			p:        p,
+			h:        promhttp.Handler(),
+			m:        []string{http.MethodGet},
+			internal: true,
 		})
 	}
 }
 
 // ShutdownTimeout is an option to override default shutdown timeout of server.
+// It is the maximum time to wait for in-flight requests and streams to finish
+// before the server is stopped forcibly. Default timeout is 30s.
 // Set to -1 for no timeout.
 func ShutdownTimeout(t time.Duration) Option {
 	return func(opts *Server) {
@@ -445,10 +1029,28 @@ func ShutdownTimeout(t time.Duration) Option {
 	}
 }
 
+// ShutdownDrainPeriod is an option to set the period to wait after switching health status
+// to NOT_SERVING and before stopping the listeners, giving load balancers time to stop
+// routing new traffic to the server.
+func ShutdownDrainPeriod(d time.Duration) Option {
+	return func(opts *Server) {
+		opts.shutdownDrainPeriod = d
+	}
+}
+
+// CleanupHooks is an option to register functions to be called after the server
+// is stopped, i.e... closing database and Redis connections.
+// The hooks are called in reverse order of registration, the same as defer.
+func CleanupHooks(hooks ...func()) Option {
+	return func(opts *Server) {
+		opts.cleanupHooks = append(opts.cleanupHooks, hooks...)
+	}
+}
+
 // DefaultHeaderMatcher is an ServerMuxOption that forward
-// header keys X-Request-Id, X-Correlation-ID, Api-Key to gRPC Context.
+// header keys X-Request-Id, X-Correlation-ID, Api-Key, Accept-Language to gRPC Context.
 func DefaultHeaderMatcher() runtime.ServeMuxOption {
-	return HeaderMatcher([]string{"X-Request-Id", "X-Correlation-ID", "Api-Key"})
+	return HeaderMatcher([]string{"X-Request-Id", "X-Correlation-ID", "Api-Key", i18n.AcceptLanguageMD})
 }
 
 // HeaderMatcher is an ServeMuxOption for matcher header
@@ -476,25 +1078,59 @@ func StreamTracing(tracer opentracing.Tracer) Option {
 	return StreamInterceptors(otgrpc.OpenTracingStreamServerInterceptor(tracer))
 }
 
-// ShutdownHook expose an API for shutdown the server remotely.
+// ShutdownHook expose an API for shutdown the server remotely by a POST request to the given path.
+// Like the shutdown operation of AdminControl, the caller must have the admin scope in its JWT claims
+// and the request is audited.
 //
-// WARNING: this is an experiment API and
-// it should be enabled only in development mode for live reload.
+// Deprecated: use AdminControl instead.
 func ShutdownHook(path string) Option {
 	return func(opts *Server) {
 		// do nothing if path is empty.
 		if path == "" {
 			return
 		}
-		hopt := HandlerOptions{}
-		hopt.p = path
-		hopt.h = http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
-			opts.Shutdown(context.Background())
-		})
+		c := &adminControl{srv: opts}
+		hopt := HandlerOptions{p: path, h: http.HandlerFunc(c.serveShutdown), m: []string{http.MethodPost}}
+		hopt.Admin()
 		opts.routes = append(opts.routes, hopt)
 	}
 }
 
+// AdminControl is an option to register the admin control service awesome.admin.v1.AdminControl,
+// and its HTTP API under pathPrefix, default is /internal/admin. Its operations:
+// graceful shutdown, maintenance mode, log level, health status of the dependencies
+// and flushing the caches registered by Cache, require the scope set by AdminScope
+// in the JWT claims of the caller, i.e... verified by AdminAuth, and are audited.
+// In maintenance mode, all requests but the internal handlers, admin and health check
+// services are rejected with 503 or codes.Unavailable.
+func AdminControl(pathPrefix string) Option {
+	return func(opts *Server) {
+		if pathPrefix == "" {
+			pathPrefix = defaultAdminControlPrefix
+		}
+		opts.control = &adminControl{srv: opts}
+		opts.routes = append(opts.routes, opts.control.routes(pathPrefix)...)
+	}
+}
+
+// AdminScope is an option to set the scope required in the JWT claims of the admin
+// control operations, default is admin.
+func AdminScope(scope string) Option {
+	return func(opts *Server) {
+		opts.adminScope = scope
+	}
+}
+
+// Cache is an option to register a cache flushed by the admin control service.
+func Cache(name string, f CacheFlusher) Option {
+	return func(opts *Server) {
+		if opts.caches == nil {
+			opts.caches = make(map[string]CacheFlusher)
+		}
+		opts.caches[name] = f
+	}
+}
+
 // recoveryHandler print the context log to the configured writer and return
 // a general error to the caller.
 func recoveryHandler(l log.Logger) func(context.Context, interface{}) error {
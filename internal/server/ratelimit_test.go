@@ -0,0 +1,64 @@
+package server_test
+
+import (
+	"github.com/realHoangHai/awesome/internal/ratelimit"
+	"github.com/realHoangHai/awesome/internal/server"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimitGateway(t *testing.T) {
+	cases := []struct {
+		name string
+		rule string
+	}{
+		// the gateway call of a request limited by its path is not limited again.
+		{name: "HTTP rule", rule: "*"},
+		// the gateway call of a request not limited by its path is limited by its method.
+		{name: "gRPC rule", rule: "/test.Who/*"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := startServer(t, []server.Service{&whoService{}},
+				server.RateLimit(ratelimit.New(ratelimit.NewMemoryStore(),
+					ratelimit.WithRule(c.rule, ratelimit.Rule{Limit: 1, Period: time.Minute}))),
+			)
+
+			for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+				resp, err := http.Get("http://" + srv.addr + "/whoami")
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+				// the gateway route of whoService reports the gRPC errors as 401.
+				if got := resp.StatusCode; got != want && (want == http.StatusOK || got == http.StatusOK) {
+					t.Fatalf("request %d: got status_code=%d, want status_code=%d", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitForgedGRPCHeaders(t *testing.T) {
+	srv := startServer(t, []server.Service{&whoService{}},
+		server.Connect(),
+		server.RateLimit(ratelimit.New(ratelimit.NewMemoryStore(),
+			ratelimit.WithRule("/whoami", ratelimit.Rule{Limit: 1, Period: time.Minute}))),
+	)
+
+	// the requests over HTTP/1.1 are not served by the gRPC server whatever their headers.
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req, _ := http.NewRequest(http.MethodGet, "http://"+srv.addr+"/whoami", nil)
+		req.Header.Set("Content-Type", "application/grpc")
+		req.Header.Set("Connect-Protocol-Version", "1")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("request %d: got status_code=%d, want status_code=%d", i, resp.StatusCode, want)
+		}
+	}
+}
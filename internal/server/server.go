@@ -1,27 +1,41 @@
 // Package server provides a convenient way to create and start a new server.
-// that serves both gRPC and HTTP over a single port.
+// that serves both gRPC and HTTP over a single port or over separated listeners.
 // with default useful APIs for authentication, health-checking, metrics, tracing, etc.
 package server
 
 import (
 	"context"
+	"crypto/tls"
 	"github.com/gorilla/mux"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/realHoangHai/awesome/config"
+	"github.com/realHoangHai/awesome/internal/access"
 	auth2 "github.com/realHoangHai/awesome/internal/auth"
+	"github.com/realHoangHai/awesome/internal/concurrency"
+	"github.com/realHoangHai/awesome/internal/dispatch"
 	"github.com/realHoangHai/awesome/internal/health"
+	"github.com/realHoangHai/awesome/internal/ratelimit"
+	"github.com/realHoangHai/awesome/pkg/certs"
+	"github.com/realHoangHai/awesome/pkg/clientip"
+	"github.com/realHoangHai/awesome/pkg/errors"
+	"github.com/realHoangHai/awesome/pkg/i18n"
 	"github.com/realHoangHai/awesome/pkg/log"
+	"github.com/realHoangHai/awesome/pkg/systemd"
+	"github.com/realHoangHai/awesome/pkg/utils/validator"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"sort"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 	// register default codecs
@@ -34,19 +48,71 @@ type (
 	Server struct {
 		lis         net.Listener
 		httpSrv     *http.Server
+		adminSrv    *http.Server
+		http3Srv    HTTP3Server
+		grpcSrv     *grpc.Server
 		address     string
 		tlsCertFile string
 		tlsKeyFile  string
 
+		// dedicated listeners, nil means served on the shared address.
+		grpcEndpoint *endpoint
+		httpEndpoint *endpoint
+		// dedicated listener of the internal HTTP handlers.
+		adminEndpoint *endpoint
+		// file mode of unix domain sockets.
+		unixSocketMode os.FileMode
+		// all listeners in use, by name.
+		listeners map[string]net.Listener
+		// TLS certificate managers, by certificate and key files.
+		certManagers map[string]*certs.Manager
+		// rate limit
+		rateLimiter *ratelimit.Limiter
+		// adaptive concurrency limit
+		concurrencyLimiter *concurrency.Limiter
+		// auto TLS
+		autoTLS   *config.SectionAutoTLS
+		tlsSource certs.Source
+		// mutual TLS
+		clientCAFile string
+		clientAuth   tls.ClientAuthType
+
+		// graceful upgrade
+		upgradeEnabled bool
+		upgradeTimeout time.Duration
+		upgrading      int32
+
 		// HTTP
 		readTimeout          time.Duration
 		writeTimeout         time.Duration
-		shutdownTimeout      time.Duration
 		routes               []HandlerOptions
 		apiPrefix            string
 		httpInterceptors     []func(http.Handler) http.Handler
 		routesPrioritization bool
 		notFoundHandler      http.Handler
+		// web protocols served by the gRPC server.
+		grpcWeb bool
+		connect bool
+		// streaming RPCs of the gateway over Server-Sent Events and WebSocket.
+		streamBridge *streamBridge
+		// HTTP/3 alongside the HTTP listener.
+		newHTTP3 NewHTTP3Server
+		// client IP resolution behind load balancers.
+		proxyProtocol  bool
+		trustedProxies []string
+		clientIP       *clientip.Resolver
+		// recovery of the HTTP handlers from panics.
+		recovery func(context.Context, interface{}) error
+		// access control of the methods by client IP.
+		accessControl *access.Controller
+		accessRules   func() ([]access.Rule, error)
+		// localization of the errors.
+		catalog *i18n.Catalog
+		// validation of the requests.
+		validation bool
+		// compression of the HTTP responses.
+		compression *compressor
+		encoders    map[string]Encoder
 
 		serverOptions   []grpc.ServerOption
 		serveMuxOptions []runtime.ServeMuxOption
@@ -57,12 +123,35 @@ type (
 
 		log           log.Logger
 		enableMetrics bool
+		// invalid options of the config, the server refuses to start with them.
+		configErr error
 
 		auth auth2.Authenticator
 
+		// admin
+		adminAuth  auth2.Authenticator
+		adminScope string
+		reflection bool
+		channelz   *channelzService
+		control    *adminControl
+		caches     map[string]CacheFlusher
+		// 1 when in maintenance mode.
+		maintenance int32
+
 		// health checks
 		healthCheckPath string
 		healthSrv       health.Server
+
+		// shutdown
+		shutdownTimeout     time.Duration
+		shutdownDrainPeriod time.Duration
+		cleanupHooks        []func()
+		drainer             drainer
+		shutdownOnce        sync.Once
+		stoppedOnce         sync.Once
+		stopped             chan struct{}
+		phase               int32
+		phaseStarted        time.Time
 	}
 
 	// Option is a configuration option.
@@ -90,6 +179,9 @@ func New(opts ...Option) *Server {
 	if server.log == nil {
 		server.log = log.Root()
 	}
+	if server.address == "" && server.lis != nil {
+		server.address = server.lis.Addr().String()
+	}
 	if server.address == "" {
 		server.address = defaultAddr
 	}
@@ -107,25 +199,145 @@ func (s *Server) Run(services ...Service) error {
 // RunWithContext opens a tcp listener used by a grpc.Server and a HTTP server,
 // and registers each Service with the grpc.Server. If the Service implements EndpointService
 // its endpoints will be registered to the HTTP Server running on the same port.
+// If dedicated gRPC or HTTP addresses are configured, gRPC and HTTP will be served
+// on separated listeners instead, each with its own TLS and timeout settings.
 // The server starts with default metrics and health endpoints.
 // If the context is canceled or times out, the gRPC server will attempt a graceful shutdown.
 func (s *Server) RunWithContext(ctx context.Context, services ...Service) error {
-	if s.lis == nil {
-		lis, err := net.Listen("tcp", s.address)
+	if s.configErr != nil {
+		return s.configErr
+	}
+	if err := s.checkClientAuth(); err != nil {
+		return err
+	}
+	if err := s.checkAdminAuth(); err != nil {
+		return err
+	}
+	if err := s.checkHTTP3(); err != nil {
+		return err
+	}
+	if err := s.checkProxyProtocol(); err != nil {
+		return err
+	}
+	if err := s.initAutoTLS(); err != nil {
+		return err
+	}
+	if !s.grpcEndpoint.isSet() || !s.httpEndpoint.isSet() {
+		lis, err := s.listen(listenerMain, s.lis, s.address)
 		if err != nil {
 			return err
 		}
 		s.lis = lis
 	}
+	grpcEp, httpEp := s.resolveEndpoints()
+	if s.grpcEndpoint.isSet() {
+		lis, err := s.listen(listenerGRPC, grpcEp.lis, grpcEp.address)
+		if err != nil {
+			return err
+		}
+		grpcEp.lis = lis
+	}
+	if s.httpEndpoint.isSet() {
+		lis, err := s.listen(listenerHTTP, httpEp.lis, httpEp.address)
+		if err != nil {
+			return err
+		}
+		httpEp.lis = lis
+	}
+	var adminEp *endpoint
+	if s.adminEndpoint.isSet() {
+		ep := *s.adminEndpoint
+		lis, err := s.listen(listenerAdmin, ep.lis, ep.address)
+		if err != nil {
+			return err
+		}
+		ep.lis = lis
+		ep.inherit(s)
+		adminEp = &ep
+	}
+	// access control and rate limiting need the client IP forwarded by the gateway.
+	if s.proxyProtocol || len(s.trustedProxies) > 0 || s.accessControl != nil || s.rateLimiter != nil {
+		r, err := clientip.NewResolver(s.trustedProxies...)
+		if err != nil {
+			return err
+		}
+		s.clientIP = r
+	}
+	if s.proxyProtocol || len(s.trustedProxies) > 0 {
+		// the public listeners only, the admin one is not behind the load balancers.
+		grpcEp.lis = s.proxyListener(grpcEp.lis)
+		httpEp.lis = s.proxyListener(httpEp.lis)
+	}
+	// excess requests are shed before doing any work.
+	if s.concurrencyLimiter != nil {
+		s.streamInterceptors = append([]grpc.StreamServerInterceptor{s.concurrencyLimiter.StreamInterceptor()}, s.streamInterceptors...)
+		s.unaryInterceptors = append([]grpc.UnaryServerInterceptor{s.concurrencyLimiter.UnaryInterceptor()}, s.unaryInterceptors...)
+	}
+	// maintenance mode can only be switched by the admin control service.
+	if s.control != nil {
+		s.streamInterceptors = append([]grpc.StreamServerInterceptor{s.maintenanceStreamInterceptor()}, s.streamInterceptors...)
+		s.unaryInterceptors = append([]grpc.UnaryServerInterceptor{s.maintenanceUnaryInterceptor()}, s.unaryInterceptors...)
+	}
+	var forwarder *auth2.IdentityForwarder
+	if s.clientCAFile != "" {
+		f, err := newIdentityForwarder()
+		if err != nil {
+			return err
+		}
+		forwarder = f
+		// identities forwarded by the gateway must be resolved before any authentication.
+		s.streamInterceptors = append([]grpc.StreamServerInterceptor{f.StreamInterceptor()}, s.streamInterceptors...)
+		s.unaryInterceptors = append([]grpc.UnaryServerInterceptor{f.UnaryInterceptor()}, s.unaryInterceptors...)
+	}
+	// denied clients are rejected before being authenticated.
+	if s.accessControl != nil {
+		s.streamInterceptors = append([]grpc.StreamServerInterceptor{s.accessControl.StreamInterceptor()}, s.streamInterceptors...)
+		s.unaryInterceptors = append([]grpc.UnaryServerInterceptor{s.accessControl.UnaryInterceptor()}, s.unaryInterceptors...)
+		s.httpInterceptors = append([]func(http.Handler) http.Handler{s.accessControl.HTTPInterceptor()}, s.httpInterceptors...)
+	}
+	// the client IP is resolved before anything else for being logged, rate limited and audited.
+	if s.clientIP != nil {
+		s.streamInterceptors = append([]grpc.StreamServerInterceptor{s.clientIP.StreamInterceptor()}, s.streamInterceptors...)
+		s.unaryInterceptors = append([]grpc.UnaryServerInterceptor{s.clientIP.UnaryInterceptor()}, s.unaryInterceptors...)
+		s.httpInterceptors = append([]func(http.Handler) http.Handler{s.clientIP.HTTPInterceptor()}, s.httpInterceptors...)
+	}
+	// panics of any HTTP interceptor are recovered.
+	if s.recovery != nil {
+		s.httpInterceptors = append([]func(http.Handler) http.Handler{s.recoveryHTTPInterceptor}, s.httpInterceptors...)
+	}
+	// in-flight calls must be tracked first for being drained on shutdown.
+	s.streamInterceptors = append([]grpc.StreamServerInterceptor{s.drainer.streamInterceptor()}, s.streamInterceptors...)
+	s.unaryInterceptors = append([]grpc.UnaryServerInterceptor{s.drainer.unaryInterceptor()}, s.unaryInterceptors...)
+	if s.adminAuth != nil {
+		s.auth = adminAuthenticator{admin: s.adminAuth, next: s.auth}
+	}
 	if s.auth != nil {
 		s.streamInterceptors = append(s.streamInterceptors, auth2.StreamInterceptor(s.auth))
 		s.unaryInterceptors = append(s.unaryInterceptors, auth2.UnaryInterceptor(s.auth))
 	}
+	// after authentication so that the limits can be keyed by the authenticated subject.
+	if s.rateLimiter != nil {
+		s.streamInterceptors = append(s.streamInterceptors, s.rateLimiter.StreamInterceptor())
+		s.unaryInterceptors = append(s.unaryInterceptors, s.rateLimiter.UnaryInterceptor())
+		s.httpInterceptors = append(s.httpInterceptors, s.rateLimiter.HTTPInterceptor())
+	}
 	if s.enableMetrics {
 		s.streamInterceptors = append(s.streamInterceptors, grpc_prometheus.StreamServerInterceptor)
 		s.unaryInterceptors = append(s.unaryInterceptors, grpc_prometheus.UnaryServerInterceptor)
 	}
-	isSecured := s.tlsCertFile != "" && s.tlsKeyFile != ""
+	// innermost, so that the other interceptors get the status of the errors of the handlers.
+	s.streamInterceptors = append(s.streamInterceptors, errors.StreamServerInterceptor())
+	s.unaryInterceptors = append(s.unaryInterceptors, errors.UnaryServerInterceptor())
+	if s.catalog != nil {
+		// inside the conversion of the errors, so that the failures of the validator are localized as well.
+		s.streamInterceptors = append(s.streamInterceptors, s.catalog.StreamServerInterceptor())
+		s.unaryInterceptors = append(s.unaryInterceptors, s.catalog.UnaryServerInterceptor())
+	}
+	if s.validation {
+		s.streamInterceptors = append(s.streamInterceptors, validator.StreamServerInterceptor())
+		s.unaryInterceptors = append(s.unaryInterceptors, validator.UnaryServerInterceptor())
+	}
+	isSecured := grpcEp.isSecured()
 
 	// server options
 	if len(s.streamInterceptors) > 0 {
@@ -134,28 +346,49 @@ func (s *Server) RunWithContext(ctx context.Context, services ...Service) error
 	if len(s.unaryInterceptors) > 0 {
 		s.serverOptions = append(s.serverOptions, grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(s.unaryInterceptors...)))
 	}
+	var grpcCerts certs.Source
 	if isSecured {
-		creds, err := credentials.NewServerTLSFromFile(s.tlsCertFile, s.tlsKeyFile)
+		m, err := s.certSource(grpcEp)
 		if err != nil {
 			return err
 		}
-		s.serverOptions = append(s.serverOptions, grpc.Creds(creds))
+		grpcCerts = m
+		cfg, err := s.serverTLSConfig(m)
+		if err != nil {
+			return err
+		}
+		s.serverOptions = append(s.serverOptions, grpc.Creds(credentials.NewTLS(cfg)))
+	}
+	connectionTimeout := grpcEp.connectionTimeout
+	if connectionTimeout == 0 && !s.isSeparated() {
+		connectionTimeout = s.readTimeout
+	}
+	if connectionTimeout > 0 {
+		s.serverOptions = append(s.serverOptions, grpc.ConnectionTimeout(connectionTimeout))
 	}
 	grpcServer := grpc.NewServer(s.serverOptions...)
+	s.grpcSrv = grpcServer
 	muxOpts := s.serveMuxOptions
 	if len(muxOpts) == 0 {
 		muxOpts = []runtime.ServeMuxOption{DefaultHeaderMatcher()}
 	}
+	// first, so that it can be replaced by the ServeMuxOptions.
+	muxOpts = append([]runtime.ServeMuxOption{runtime.WithErrorHandler(gatewayErrorHandler)}, muxOpts...)
+	if forwarder != nil {
+		muxOpts = append(muxOpts, runtime.WithMetadata(forwarder.Metadata))
+	}
+	if s.clientIP != nil {
+		muxOpts = append(muxOpts, runtime.WithMetadata(s.clientIP.Metadata))
+	}
+	if s.rateLimiter != nil {
+		muxOpts = append(muxOpts, runtime.WithMetadata(s.rateLimiter.Metadata))
+	}
 	gw := runtime.NewServeMux(muxOpts...)
 	router := mux.NewRouter()
 
 	dialOpts := make([]grpc.DialOption, 0)
 	if isSecured {
-		creds, err := credentials.NewClientTLSFromFile(s.tlsCertFile, "")
-		if err != nil {
-			return err
-		}
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(grpcCerts.ClientTLSConfig())))
 	}
 	if !isSecured {
 		s.log.Context(ctx).Warn("server: insecured mode is enabled.")
@@ -163,13 +396,24 @@ func (s *Server) RunWithContext(ctx context.Context, services ...Service) error
 	}
 	// expose health service via gRPC.
 	services = append(services, s.healthSrv)
+	if s.channelz != nil {
+		services = append(services, s.channelz)
+	}
+	if s.control != nil {
+		services = append(services, s.control)
+	}
 
+	// the gateway must always dial the listener serving gRPC.
+	grpcAddr := grpcEp.dialAddress()
 	for _, svc := range services {
 		svc.Register(grpcServer)
 		if epSrv, ok := svc.(EndpointService); ok {
-			epSrv.RegisterWithEndpoint(ctx, gw, s.address, dialOpts)
+			epSrv.RegisterWithEndpoint(ctx, gw, grpcAddr, dialOpts)
 		}
 	}
+	if s.reflection {
+		reflection.Register(grpcServer)
+	}
 	// Make sure Prometheus metrics are initialized.
 	if s.enableMetrics {
 		grpc_prometheus.Register(grpcServer)
@@ -177,39 +421,127 @@ func (s *Server) RunWithContext(ctx context.Context, services ...Service) error
 	// Add internal handlers.
 	s.routes = append([]HandlerOptions{
 		{
-			p: s.getHealthCheckPath(),
-			h: s.healthSrv,
-			m: []string{http.MethodGet},
+			p:        s.getHealthCheckPath(),
+			h:        s.healthSrv,
+			m:        []string{http.MethodGet},
+			internal: true,
 		},
 	}, s.routes...)
 	// Serve gRPC and GW only and only if there is at least one service registered.
 	if len(services) > 0 {
-		s.routes = append(s.routes, HandlerOptions{p: s.getAPIPrefix(), h: gw, prefix: true})
+		var gwHandler http.Handler = gw
+		if s.streamBridge != nil {
+			s.streamBridge.next = gw
+			s.streamBridge.auth = s.auth
+			gwHandler = s.streamBridge
+		}
+		s.routes = append(s.routes, HandlerOptions{p: s.getAPIPrefix(), h: gwHandler, prefix: true})
+	}
+	// register all http handlers to the router, the internal ones to the admin router if any.
+	routes := s.routes
+	var adminRouter *mux.Router
+	if adminEp != nil {
+		adminRouter = mux.NewRouter()
+		var internal []HandlerOptions
+		routes, internal = splitInternalRoutes(s.routes)
+		internal = append(internal, HandlerOptions{
+			p:     "/",
+			h:     routeIndex(s.routes),
+			m:     []string{http.MethodGet},
+			admin: true,
+		})
+		s.registerHTTPHandlers(ctx, adminRouter, internal)
 	}
-	// register all http handlers to the router.
-	s.registerHTTPHandlers(ctx, router)
+	s.registerHTTPHandlers(ctx, router, routes)
 
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 4)
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	if s.upgradeEnabled {
+		signal.Notify(sigChan, upgradeSignals...)
+	}
+	var httpTLS *tls.Config
+	if httpEp.isSecured() {
+		m, err := s.certSource(httpEp)
+		if err != nil {
+			return err
+		}
+		cfg, err := s.serverTLSConfig(m)
+		if err != nil {
+			return err
+		}
+		httpTLS = cfg
+	}
+	signal.Notify(sigChan, reloadSignals...)
+	defer signal.Stop(sigChan)
 
-	handler := grpcHandlerFunc(isSecured, grpcServer, router)
+	var handler http.Handler = router
+	// only the routes are compressed, gRPC and the web protocols have their own compression.
+	if s.compression != nil {
+		for name, e := range s.encoders {
+			s.compression.encoders[name] = e
+		}
+		for _, name := range s.compression.unsupported() {
+			s.log.Context(ctx).Warnf("server: no encoder of %s, it is not used for compression", name)
+		}
+		handler = s.compression.handler(router)
+	}
+	dispatcher := &grpcDispatcher{next: handler}
+	if !s.isSeparated() {
+		dispatcher.grpcServer = grpcServer
+	}
+	if s.grpcWeb || s.connect {
+		dispatcher.web = &webProtocols{grpcWeb: s.grpcWeb, connect: s.connect, grpcServer: grpcServer}
+	}
+	handler = dispatcher
 	for i := len(s.httpInterceptors) - 1; i >= 0; i-- {
 		handler = s.httpInterceptors[i](handler)
 	}
+	// the requests are marked before the interceptors, so that they leave the ones dispatched to gRPC.
+	handler = dispatcher.mark(handler)
+	// work-around in case TLS is disabled. See: https://github.com/grpc/grpc-go/issues/555
+	// outermost, so that the interceptors get the requests of the h2c connections rather than their preface.
+	if !s.isSeparated() && !isSecured {
+		handler = h2c.NewHandler(handler, &http2.Server{})
+	}
+	if s.newHTTP3 != nil && httpTLS == nil {
+		s.log.Context(ctx).Warn("server: HTTP/3 requires TLS, it is disabled.")
+	}
+	if s.newHTTP3 != nil && httpTLS != nil {
+		h, err := s.serveHTTP3(httpEp, httpTLS, handler, errChan)
+		if err != nil {
+			return err
+		}
+		handler = h
+		s.log.Context(ctx).Infof("server: HTTP/3 listening at: %s (udp)", httpEp.lis.Addr().String())
+	}
 	s.httpSrv = &http.Server{
-		Addr:         s.address,
+		Addr:         httpEp.address,
 		Handler:      handler,
-		ReadTimeout:  s.readTimeout,
-		WriteTimeout: s.writeTimeout,
+		ReadTimeout:  httpEp.readTimeout,
+		WriteTimeout: httpEp.writeTimeout,
+		TLSConfig:    httpTLS,
 	}
 	go func() {
-		if isSecured {
-			errChan <- s.httpSrv.ServeTLS(s.lis, s.tlsCertFile, s.tlsKeyFile)
+		if httpTLS != nil {
+			// certificates are served by TLSConfig.GetCertificate for being reloaded on the fly.
+			errChan <- s.httpSrv.ServeTLS(httpEp.lis, "", "")
 			return
 		}
-		errChan <- s.httpSrv.Serve(s.lis)
+		errChan <- s.httpSrv.Serve(httpEp.lis)
 	}()
+	if s.isSeparated() {
+		go func() {
+			errChan <- grpcServer.Serve(grpcEp.lis)
+		}()
+	}
+	if adminEp != nil {
+		if err := s.serveAdmin(adminEp, adminRouter, errChan); err != nil {
+			s.Shutdown(ctx)
+			return err
+		}
+		s.log.Context(ctx).Infof("server: admin listening at: %s", adminEp.lis.Addr().String())
+	}
 
 	// init health check service.
 	if err := s.healthSrv.Init(health.StatusServing); err != nil {
@@ -220,43 +552,102 @@ func (s *Server) RunWithContext(ctx context.Context, services ...Service) error
 	defer func() {
 		_ = s.healthSrv.Close()
 	}()
-	s.log.Context(ctx).Infof("server: listening at: %s", s.address)
-	select {
-	case <-ctx.Done():
-		s.Shutdown(ctx)
-		return ctx.Err()
-	case err := <-errChan:
-		return err
-	case sig := <-sigChan:
-		switch sig {
-		case os.Interrupt, syscall.SIGTERM:
-			s.log.Context(ctx).Info("server: gracefully shutdown...")
-			s.Shutdown(ctx)
+	if s.isSeparated() {
+		s.log.Context(ctx).Infof("server: gRPC listening at: %s", grpcAddr)
+		s.log.Context(ctx).Infof("server: HTTP listening at: %s", httpEp.lis.Addr().String())
+	} else {
+		s.log.Context(ctx).Infof("server: listening at: %s", s.address)
+	}
+	if _, err := systemd.Notify(systemd.NotifyReady); err != nil {
+		s.log.Context(ctx).Warnf("server: notify systemd ready, err: %v", err)
+	}
+	// report to the parent process if this server is started by a graceful upgrade.
+	go func() {
+		if err := s.notifyUpgradeReady(ctx); err != nil {
+			s.log.Context(ctx).Errorf("server: notify upgrade ready, err: %v", err)
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			// the context is already done, shutdown using a fresh context.
+			s.Shutdown(context.Background())
+			return ctx.Err()
+		case err := <-errChan:
+			// listeners are closed by an on-going shutdown.
+			if s.ShutdownPhase() != ShutdownPhaseRunning {
+				<-s.getStopped()
+				return nil
+			}
+			return err
+		case sig := <-sigChan:
+			switch {
+			case sig == os.Interrupt || sig == syscall.SIGTERM:
+				s.log.Context(ctx).Info("server: gracefully shutdown...")
+				s.Shutdown(ctx)
+				return nil
+			case sig == syscall.SIGHUP && s.isReloadable():
+				s.log.Context(ctx).Info("server: reloading TLS certificates and access rules...")
+				// errors are logged, the current certificates and rules are kept on failure.
+				_ = s.ReloadCertificates()
+				_ = s.ReloadAccessRules()
+			case !s.upgradeEnabled:
+				s.log.Context(ctx).Infof("server: received %v, nothing to reload", sig)
+			default:
+				s.log.Context(ctx).Infof("server: received %v, upgrading...", sig)
+				go func() {
+					if err := s.Upgrade(); err != nil {
+						s.log.Context(ctx).Errorf("server: upgrade failed, err: %v", err)
+					}
+				}()
+			}
 		}
 	}
-	return nil
 }
 
-// grpcHandlerFunc returns an http.Handler that delegates to grpcServer on incoming gRPC
-// connections or otherHandler otherwise.
-func grpcHandlerFunc(isSecured bool, grpcServer *grpc.Server, mux http.Handler) http.Handler {
-	if isSecured {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if isGRPCRequest(r) {
-				grpcServer.ServeHTTP(w, r)
-				return
-			}
-			mux.ServeHTTP(w, r)
-		})
+// grpcDispatcher serves the requests of the gRPC protocols by the gRPC server: gRPC if it is served on the
+// HTTP port, gRPC-Web and Connect if enabled, and the other requests by next.
+type grpcDispatcher struct {
+	grpcServer *grpc.Server
+	web        *webProtocols
+	next       http.Handler
+}
+
+// handler returns the handler of the gRPC protocol of the request, nil if it is not dispatched to gRPC.
+func (d *grpcDispatcher) handler(r *http.Request) http.HandlerFunc {
+	if d.grpcServer != nil && isGRPCRequest(r) {
+		return d.grpcServer.ServeHTTP
 	}
-	// work-around in case TLS is disabled. See: https://github.com/grpc/grpc-go/issues/555
-	return h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if isGRPCRequest(r) {
-			grpcServer.ServeHTTP(w, r)
-		} else {
-			mux.ServeHTTP(w, r)
+	if d.web != nil {
+		return d.web.handler(r)
+	}
+	return nil
+}
+
+// mark returns an http.Handler marking the requests dispatched to gRPC, see dispatch.IsGRPC.
+func (d *grpcDispatcher) mark(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.handler(r) != nil {
+			r = dispatch.WithGRPC(r)
 		}
-	}), &http2.Server{})
+		h.ServeHTTP(w, r)
+	})
+}
+
+// ServeHTTP implements http.Handler, only the marked requests are dispatched to gRPC.
+func (d *grpcDispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if dispatch.IsGRPC(r) {
+		if h := d.handler(r); h != nil {
+			h(w, r)
+			return
+		}
+	}
+	d.next.ServeHTTP(w, r)
+}
+
+// isReloadable reports whether there are TLS certificates or access rules reloaded on SIGHUP.
+func (s *Server) isReloadable() bool {
+	return len(s.certManagers) > 0 || s.accessRules != nil
 }
 
 func (s *Server) getHealthCheckPath() string {
@@ -286,22 +677,6 @@ func (s *Server) getAPIPrefix() string {
 	return s.apiPrefix
 }
 
-// Shutdown shutdown the server gracefully.
-func (s *Server) Shutdown(ctx context.Context) {
-	if s.healthSrv != nil {
-		if err := s.healthSrv.Close(); err != nil {
-			s.log.Errorf("server: shutdown health check service error: %v", err)
-		}
-	}
-	if s.httpSrv != nil {
-		ctx, cancel := context.WithTimeout(ctx, s.shutdownTimeout)
-		defer cancel()
-		if err := s.httpSrv.Shutdown(ctx); err != nil {
-			s.log.Errorf("server: shutdown error: %v", err)
-		}
-	}
-}
-
 func (s *Server) getLogger() log.Logger {
 	if s.log == nil {
 		return log.Root()
@@ -309,21 +684,30 @@ func (s *Server) getLogger() log.Logger {
 	return s.log
 }
 
-func (s *Server) registerHTTPHandlers(ctx context.Context, router *mux.Router) {
+func (s *Server) registerHTTPHandlers(ctx context.Context, router *mux.Router, routes []HandlerOptions) {
 	// Longer patterns take precedence over shorter ones.
 	if s.routesPrioritization {
-		sort.Sort(sort.Reverse(handlerOptionsSlice(s.routes)))
+		sort.Sort(sort.Reverse(handlerOptionsSlice(routes)))
 	}
 	if s.notFoundHandler != nil {
 		router.NotFoundHandler = s.notFoundHandler
 	}
-	for _, r := range s.routes {
+	for _, r := range routes {
 		var route *mux.Route
 		h := r.h
 		info := make([]interface{}, 0)
 		for _, interceptor := range r.interceptors {
 			h = interceptor(h)
 		}
+		// internal handlers are still served in maintenance mode.
+		if !r.internal && s.control != nil {
+			h = s.maintenanceHTTPInterceptor(h)
+			info = append(info, "maintenance", true)
+		}
+		if r.admin && s.adminAuth != nil {
+			h = auth2.HTTPInterceptor(s.adminAuth)(h)
+			info = append(info, "admin", true)
+		}
 		if r.prefix {
 			route = router.PathPrefix(r.p).Handler(h)
 			info = append(info, "path_prefix", r.p)
@@ -347,6 +731,8 @@ func (s *Server) registerHTTPHandlers(ctx context.Context, router *mux.Router) {
 	}
 }
 
+// isGRPCRequest reports whether the request is a gRPC request, gRPC-Web requests are not.
 func isGRPCRequest(r *http.Request) bool {
-	return r.ProtoMajor == 2 && strings.Contains(r.Header.Get("Content-Type"), "application/grpc")
+	ct := r.Header.Get("Content-Type")
+	return r.ProtoMajor == 2 && strings.HasPrefix(ct, "application/grpc") && !strings.HasPrefix(ct, grpcWebContentType)
 }
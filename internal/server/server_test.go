@@ -1 +1,356 @@
 package server_test
+
+import (
+	"context"
+	"errors"
+	"github.com/realHoangHai/awesome/internal/health"
+	"github.com/realHoangHai/awesome/internal/server"
+	"github.com/realHoangHai/awesome/pkg/systemd"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSeparatedListeners(t *testing.T) {
+	grpcLis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpLis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := server.New(
+		server.GRPCListener(grpcLis),
+		server.HTTPListener(httpLis),
+		server.ShutdownTimeout(time.Second),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.RunWithContext(ctx)
+	}()
+
+	// gRPC must be served on the dedicated gRPC listener only.
+	conn, err := grpc.Dial(grpcLis.Addr().String(), grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	rs, err := health.NewClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Status != health.StatusServing {
+		t.Fatalf("got status=%v, want status=%v", rs.Status, health.StatusServing)
+	}
+	// HTTP must be served on the dedicated HTTP listener only.
+	resp, err := http.Get("http://" + httpLis.Addr().String() + "/internal/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status_code=%d, want status_code=%d", resp.StatusCode, http.StatusOK)
+	}
+	resp, err = http.Get("http://" + grpcLis.Addr().String() + "/internal/health")
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Fatalf("got HTTP health check served on gRPC listener, want not served")
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after context canceled")
+	}
+}
+
+func TestUnixSocketListener(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "awesome.sock")
+	// leave a stale socket file behind.
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	srv := server.New(
+		server.Address("unix://"+path),
+		server.UnixSocketMode(0600),
+		server.ShutdownTimeout(time.Second),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.RunWithContext(ctx)
+	}()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		},
+	}
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		if resp, err = client.Get("http://unix/internal/health"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status_code=%d, want status_code=%d", resp.StatusCode, http.StatusOK)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Fatalf("got mode=%v, want mode=%v", fi.Mode().Perm(), os.FileMode(0600))
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after context canceled")
+	}
+}
+
+// slowService is a test service which blocks its unary call until release is closed.
+type slowService struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *slowService) Register(srv *grpc.Server) {
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Slow",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Call",
+				Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+					in := &emptypb.Empty{}
+					if err := dec(in); err != nil {
+						return nil, err
+					}
+					handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+						close(s.entered)
+						<-s.release
+						return &emptypb.Empty{}, nil
+					}
+					return interceptor(ctx, in, &grpc.UnaryServerInfo{FullMethod: "/test.Slow/Call"}, handler)
+				},
+			},
+		},
+	}, s)
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	cases := []struct {
+		name      string
+		timeout   time.Duration
+		separated bool
+		release   bool
+		wantErr   bool
+	}{
+		{
+			name:    "in-flight call is drained",
+			timeout: 5 * time.Second,
+			release: true,
+			wantErr: false,
+		},
+		{
+			name:      "in-flight call is drained, separated listeners",
+			timeout:   5 * time.Second,
+			separated: true,
+			release:   true,
+			wantErr:   false,
+		},
+		{
+			name:      "in-flight call exceeds timeout, server is forcibly stopped",
+			timeout:   200 * time.Millisecond,
+			separated: true,
+			release:   false,
+			wantErr:   true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			cleaned := make(chan struct{})
+			srv := server.New(
+				server.Listener(lis),
+				server.ShutdownTimeout(c.timeout),
+				server.ShutdownDrainPeriod(100*time.Millisecond),
+				server.CleanupHooks(func() { close(cleaned) }),
+			)
+			if c.separated {
+				srv.With(server.GRPCListener(lis), server.HTTPAddress("127.0.0.1:0"))
+			}
+			svc := &slowService{entered: make(chan struct{}), release: make(chan struct{})}
+			done := make(chan error, 1)
+			go func() {
+				done <- srv.Run(svc)
+			}()
+			conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure(), grpc.WithBlock())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			callErr := make(chan error, 1)
+			go func() {
+				callErr <- conn.Invoke(context.Background(), "/test.Slow/Call", &emptypb.Empty{}, &emptypb.Empty{})
+			}()
+			<-svc.entered
+
+			go srv.Shutdown(context.Background())
+			// the in-flight call holds the shutdown before the cleanup.
+			if got := waitPhase(t, srv, server.ShutdownPhaseDraining); got > server.ShutdownPhaseStopping {
+				t.Fatalf("got phase=%v, want phase=%v or %v", got, server.ShutdownPhaseDraining, server.ShutdownPhaseStopping)
+			}
+			if c.release {
+				close(svc.release)
+			}
+			select {
+			case <-cleaned:
+			case <-time.After(5 * time.Second):
+				t.Fatal("cleanup hooks are not called")
+			}
+			if err := <-callErr; (err != nil) != c.wantErr {
+				t.Fatalf("got call err=%v, want err=%v", err, c.wantErr)
+			}
+			if !c.release {
+				close(svc.release)
+			}
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("got run err=%v, want err=nil", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("server did not stop")
+			}
+			if got := srv.ShutdownPhase(); got != server.ShutdownPhaseStopped {
+				t.Fatalf("got phase=%v, want phase=%v", got, server.ShutdownPhaseStopped)
+			}
+		})
+	}
+}
+
+// waitPhase waits for the shutdown of the server to reach the given phase, returning the current one.
+func waitPhase(t *testing.T, srv *server.Server, phase server.ShutdownPhase) server.ShutdownPhase {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.ShutdownPhase() < phase {
+		if time.Now().After(deadline) {
+			t.Fatalf("got phase=%v, want phase=%v", srv.ShutdownPhase(), phase)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return srv.ShutdownPhase()
+}
+
+func TestShutdownNotifiesSystemd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", path)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	srv := server.New(server.Address("127.0.0.1:0"), server.ShutdownTimeout(time.Second))
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Run()
+	}()
+	buf := make([]byte, 64)
+	for _, want := range []string{systemd.NotifyReady, systemd.NotifyStopping} {
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); got != want {
+			t.Fatalf("got state=%s, want state=%s", got, want)
+		}
+		if want == systemd.NotifyReady {
+			srv.Shutdown(context.Background())
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("got run err=%v, want err=nil", err)
+	}
+}
+
+func TestGRPCConnectionTimeout(t *testing.T) {
+	cases := []struct {
+		name   string
+		opts   []server.Option
+		closed bool
+	}{
+		// the read timeout of HTTP does not apply to the dedicated gRPC listener.
+		{name: "read timeout", opts: []server.Option{server.Timeout(100*time.Millisecond, time.Second)}},
+		{name: "connection timeout", opts: []server.Option{server.GRPCConnectionTimeout(100 * time.Millisecond)}, closed: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			grpcLis, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			httpLis, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv := server.New(append([]server.Option{
+				server.GRPCListener(grpcLis),
+				server.HTTPListener(httpLis),
+				server.ShutdownTimeout(time.Second),
+			}, c.opts...)...)
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- srv.RunWithContext(ctx)
+			}()
+			defer func() {
+				cancel()
+				<-done
+			}()
+
+			// a connection never sending the HTTP/2 preface is closed after the connection timeout only.
+			conn, err := net.Dial("tcp", grpcLis.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+			_, err = io.ReadAll(conn)
+			var netErr net.Error
+			if timeout := errors.As(err, &netErr) && netErr.Timeout(); timeout == c.closed {
+				t.Fatalf("got err=%v, want closed=%v", err, c.closed)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/realHoangHai/awesome/pkg/systemd"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShutdownPhase is a phase of the server shutdown process.
+type ShutdownPhase int32
+
+// Phases of the shutdown process, in order.
+const (
+	// ShutdownPhaseRunning means the server is running and no shutdown has been started.
+	ShutdownPhaseRunning ShutdownPhase = iota
+	// ShutdownPhaseNotServing means the health status has been switched to NOT_SERVING.
+	ShutdownPhaseNotServing
+	// ShutdownPhaseDraining means the server is waiting for load balancers to stop sending new traffic.
+	ShutdownPhaseDraining
+	// ShutdownPhaseStopping means the server stopped accepting new connections
+	// and is waiting for in-flight requests and streams to finish.
+	ShutdownPhaseStopping
+	// ShutdownPhaseCleanup means the server is running the cleanup hooks.
+	ShutdownPhaseCleanup
+	// ShutdownPhaseStopped means the shutdown process has been completed.
+	ShutdownPhaseStopped
+)
+
+const (
+	defaultShutdownTimeout = 30 * time.Second
+)
+
+var (
+	shutdownPhaseGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "server",
+		Name:      "shutdown_phase",
+		Help:      "Current phase of the server shutdown process, 0 means running.",
+	})
+	shutdownPhaseDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "server",
+		Name:      "shutdown_phase_duration_seconds",
+		Help:      "Duration of each phase of the last server shutdown process.",
+	}, []string{"phase"})
+)
+
+func init() {
+	prometheus.MustRegister(shutdownPhaseGauge, shutdownPhaseDuration)
+}
+
+// String implements fmt.Stringer interface.
+func (p ShutdownPhase) String() string {
+	switch p {
+	case ShutdownPhaseRunning:
+		return "running"
+	case ShutdownPhaseNotServing:
+		return "not_serving"
+	case ShutdownPhaseDraining:
+		return "draining"
+	case ShutdownPhaseStopping:
+		return "stopping"
+	case ShutdownPhaseCleanup:
+		return "cleanup"
+	case ShutdownPhaseStopped:
+		return "stopped"
+	}
+	return "unknown"
+}
+
+// ShutdownPhase returns the current phase of the shutdown process.
+func (s *Server) ShutdownPhase() ShutdownPhase {
+	return ShutdownPhase(atomic.LoadInt32(&s.phase))
+}
+
+// Shutdown shutdown the server gracefully in the following order:
+//  1. switch health status to NOT_SERVING so that load balancers stop routing new traffic to the server.
+//  2. wait for the drain period configured by ShutdownDrainPeriod.
+//  3. stop accepting new connections, wait for in-flight HTTP requests, gRPC calls and streams to finish.
+//     The server is stopped forcibly if they don't finish within the shutdown timeout.
+//  4. stop watching TLS certificates and run the cleanup hooks registered by CleanupHooks.
+//
+// Shutdown can be called multiple times, subsequent calls wait for the first one to complete.
+func (s *Server) Shutdown(ctx context.Context) {
+	s.shutdownOnce.Do(func() {
+		s.shutdown(ctx)
+	})
+	<-s.getStopped()
+}
+
+func (s *Server) shutdown(ctx context.Context) {
+	defer close(s.getStopped())
+	logger := s.getLogger().Context(ctx)
+	bg := time.Now()
+	if _, err := systemd.Notify(systemd.NotifyStopping); err != nil {
+		logger.Warnf("server: notify systemd stopping, err: %v", err)
+	}
+
+	s.setPhase(ShutdownPhaseNotServing)
+	if s.healthSrv != nil {
+		if err := s.healthSrv.Close(); err != nil {
+			logger.Errorf("server: shutdown health check service error: %v", err)
+		}
+	}
+
+	s.setPhase(ShutdownPhaseDraining)
+	if s.shutdownDrainPeriod > 0 {
+		logger.Infof("server: waiting %v for load balancers to drain traffic", s.shutdownDrainPeriod)
+		select {
+		case <-time.After(s.shutdownDrainPeriod):
+		case <-ctx.Done():
+		}
+	}
+
+	s.setPhase(ShutdownPhaseStopping)
+	timeout := s.shutdownTimeout
+	if timeout == 0 {
+		timeout = defaultShutdownTimeout
+	}
+	var (
+		stopCtx context.Context
+		cancel  context.CancelFunc
+	)
+	if timeout > 0 {
+		stopCtx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		stopCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+	s.stop(stopCtx)
+
+	s.setPhase(ShutdownPhaseCleanup)
+	s.closeCertManagers()
+	// run cleanup hooks in reverse order, the same as defer.
+	for i := len(s.cleanupHooks) - 1; i >= 0; i-- {
+		s.cleanupHooks[i]()
+	}
+	s.setPhase(ShutdownPhaseStopped)
+	logger.Fields("duration", time.Since(bg)).Info("server: shutdown completed")
+}
+
+// stop stops the HTTP and gRPC servers gracefully and forcibly stops them if the context is done.
+func (s *Server) stop(ctx context.Context) {
+	logger := s.getLogger().Context(ctx)
+	var wg sync.WaitGroup
+	if s.httpSrv != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.httpSrv.Shutdown(ctx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				logger.Errorf("server: shutdown HTTP server error: %v", err)
+			}
+		}()
+	}
+	if s.http3Srv != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.shutdownHTTP3(ctx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				logger.Errorf("server: shutdown HTTP/3 server error: %v", err)
+			}
+		}()
+	}
+	if s.adminSrv != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.adminSrv.Shutdown(ctx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				logger.Errorf("server: shutdown admin server error: %v", err)
+			}
+		}()
+	}
+	if s.grpcSrv != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.drainer.drain(ctx); err != nil {
+				return
+			}
+			if !s.isSeparated() {
+				return
+			}
+			done := make(chan struct{})
+			go func() {
+				s.grpcSrv.GracefulStop()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-ctx.Done():
+			}
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		logger.Warnf("server: in-flight requests did not finish in time, forcibly stopping, err: %v", ctx.Err())
+		if s.httpSrv != nil {
+			_ = s.httpSrv.Close()
+		}
+		if s.adminSrv != nil {
+			_ = s.adminSrv.Close()
+		}
+	}
+	// all in-flight calls finished or being forcibly stopped, safe to close gRPC server now.
+	if s.grpcSrv != nil {
+		s.grpcSrv.Stop()
+	}
+}
+
+func (s *Server) setPhase(p ShutdownPhase) {
+	prev := ShutdownPhase(atomic.SwapInt32(&s.phase, int32(p)))
+	now := time.Now()
+	if prev != ShutdownPhaseRunning {
+		shutdownPhaseDuration.WithLabelValues(prev.String()).Set(now.Sub(s.phaseStarted).Seconds())
+	}
+	s.phaseStarted = now
+	shutdownPhaseGauge.Set(float64(p))
+	s.getLogger().Fields("phase", p.String()).Info("server: shutdown phase changed")
+}
+
+func (s *Server) getStopped() chan struct{} {
+	s.stoppedOnce.Do(func() {
+		s.stopped = make(chan struct{})
+	})
+	return s.stopped
+}
+
+// drainer tracks in-flight gRPC calls and streams so that they can be drained on shutdown.
+type drainer struct {
+	mu       sync.RWMutex
+	wg       sync.WaitGroup
+	draining bool
+}
+
+// enter registers a new in-flight call, return false if the server is draining.
+func (d *drainer) enter() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.draining {
+		return false
+	}
+	d.wg.Add(1)
+	return true
+}
+
+// drain rejects all new calls and waits for in-flight calls to finish or the context is done.
+func (d *drainer) drain(ctx context.Context) error {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *drainer) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !d.enter() {
+			return nil, status.Error(codes.Unavailable, "server: shutting down")
+		}
+		defer d.wg.Done()
+		return handler(ctx, req)
+	}
+}
+
+func (d *drainer) streamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !d.enter() {
+			return status.Error(codes.Unavailable, "server: shutting down")
+		}
+		defer d.wg.Done()
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,293 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/realHoangHai/awesome/internal/auth"
+	"golang.org/x/net/websocket"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+	// streamMethodParam is the query parameter of the HTTP method of the RPC bridged over WebSocket,
+	// default is POST.
+	streamMethodParam = "method"
+)
+
+var errOriginNotAllowed = errors.New("server: websocket origin not allowed")
+
+type (
+	// streamBridge exposes the streaming RPCs registered to the gateway by EndpointService
+	// as Server-Sent Events and over WebSocket. The gateway streams the messages as newline
+	// delimited JSON, they are sent as events, unwrapped from their result or error, or as is as WebSocket
+	// messages, i.e... {"result": ...} or {"error": ...}. The WebSocket messages
+	// received are streamed to the gateway as newline delimited JSON, an empty message closes
+	// the request stream, i.e... for a client stream to return its response.
+	streamBridge struct {
+		next    http.Handler
+		auth    auth.Authenticator
+		origins []string
+	}
+
+	// lineWriter splits the response of the gateway in lines, written by write.
+	// The response is written as is if the gateway fails before streaming.
+	lineWriter struct {
+		w      http.ResponseWriter
+		header http.Header
+		code   int
+		buf    bytes.Buffer
+		// start is called once before the first line is written.
+		start func(h http.Header)
+		write func(line []byte) error
+		err   error
+	}
+
+	// streamResult is a message streamed by the gateway.
+	streamResult struct {
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+)
+
+// ServeHTTP implements http.Handler.
+func (b *streamBridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var h http.Handler
+	switch {
+	case isWebSocketRequest(r):
+		h = websocket.Server{Handshake: b.checkOrigin, Handler: b.serveWebSocket}
+	case r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), eventStreamContentType):
+		h = http.HandlerFunc(b.serveEvents)
+	default:
+		b.next.ServeHTTP(w, r)
+		return
+	}
+	// authenticated before upgrading, so that failures are reported by HTTP status codes.
+	if b.auth != nil {
+		h = auth.HTTPInterceptor(b.auth)(h)
+	}
+	h.ServeHTTP(w, r)
+}
+
+// serveEvents serves a server streaming RPC as Server-Sent Events, the messages as the data of
+// the default events, and the error if any as the data of an error event.
+func (b *streamBridge) serveEvents(w http.ResponseWriter, r *http.Request) {
+	f, _ := w.(http.Flusher)
+	lw := &lineWriter{
+		w:      w,
+		header: make(http.Header),
+		start: func(h http.Header) {
+			h.Set("Content-Type", eventStreamContentType)
+			h.Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusOK)
+		},
+		write: func(line []byte) error {
+			event, data := streamEvent(line)
+			var msg bytes.Buffer
+			if event != "" {
+				msg.WriteString("event: " + event + "\n")
+			}
+			msg.WriteString("data: ")
+			msg.Write(data)
+			msg.WriteString("\n\n")
+			if _, err := w.Write(msg.Bytes()); err != nil {
+				return err
+			}
+			if f != nil {
+				f.Flush()
+			}
+			return nil
+		},
+	}
+	req := r.Clone(r.Context())
+	req.Header.Del("Accept")
+	b.next.ServeHTTP(lw, req)
+	lw.finish()
+}
+
+// serveWebSocket serves a streaming RPC over WebSocket.
+func (b *streamBridge) serveWebSocket(ws *websocket.Conn) {
+	defer ws.Close()
+	r := ws.Request()
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	pr, pw := io.Pipe()
+	go func() {
+		// the RPC is canceled when the WebSocket is closed.
+		defer cancel()
+		for {
+			var msg string
+			if err := websocket.Message.Receive(ws, &msg); err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+			if msg == "" {
+				_ = pw.Close()
+				continue
+			}
+			if _, err := io.WriteString(pw, msg+"\n"); err != nil {
+				continue
+			}
+		}
+	}()
+
+	req := r.Clone(ctx)
+	req.Method = http.MethodPost
+	if m := r.URL.Query().Get(streamMethodParam); m != "" {
+		req.Method = strings.ToUpper(m)
+	}
+	for _, h := range []string{"Connection", "Upgrade", "Sec-Websocket-Key", "Sec-Websocket-Version", "Sec-Websocket-Extensions", "Sec-Websocket-Protocol"} {
+		req.Header.Del(h)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.ContentLength = -1
+	req.Body = pr
+
+	var mu sync.Mutex
+	lw := &lineWriter{
+		header: make(http.Header),
+		write: func(line []byte) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return websocket.Message.Send(ws, string(line))
+		},
+	}
+	b.next.ServeHTTP(lw, req)
+	lw.finish()
+	_ = pr.Close()
+}
+
+// checkOrigin accepts the WebSocket requests of the same origin, or of the allowed origins.
+func (b *streamBridge) checkOrigin(cfg *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// not a browser.
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if u.Host == r.Host {
+		return nil
+	}
+	for _, o := range b.origins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return nil
+		}
+	}
+	return errOriginNotAllowed
+}
+
+func isWebSocketRequest(r *http.Request) bool {
+	return r.Method == http.MethodGet &&
+		strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
+// streamEvent returns the event and its data of a line streamed by the gateway.
+func streamEvent(line []byte) (string, []byte) {
+	var res streamResult
+	if err := json.Unmarshal(line, &res); err != nil {
+		return "", line
+	}
+	if len(res.Error) > 0 {
+		return "error", res.Error
+	}
+	if len(res.Result) > 0 {
+		return "", res.Result
+	}
+	return "", line
+}
+
+// Header implements http.ResponseWriter.
+func (lw *lineWriter) Header() http.Header {
+	return lw.header
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (lw *lineWriter) WriteHeader(code int) {
+	if lw.code == 0 {
+		lw.code = code
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	if lw.err != nil {
+		return 0, lw.err
+	}
+	lw.buf.Write(p)
+	if lw.failed() {
+		return len(p), nil
+	}
+	for {
+		i := bytes.IndexByte(lw.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := lw.buf.Next(i + 1)
+		if err := lw.writeLine(line[:i]); err != nil {
+			lw.err = err
+			return 0, err
+		}
+	}
+}
+
+// Flush implements http.Flusher, the lines are written as soon as they are complete.
+func (lw *lineWriter) Flush() {}
+
+// finish writes the remaining of the response, i.e... the response of a unary RPC or an error.
+func (lw *lineWriter) finish() {
+	if lw.err != nil {
+		return
+	}
+	if lw.failed() && lw.w != nil && lw.start != nil {
+		// nothing was streamed, the error of the gateway is written as is.
+		for k, vv := range lw.header {
+			lw.w.Header()[k] = vv
+		}
+		lw.w.WriteHeader(lw.code)
+		_, _ = lw.w.Write(lw.buf.Bytes())
+		return
+	}
+	if line := bytes.TrimSpace(lw.buf.Bytes()); len(line) > 0 {
+		_ = lw.writeLine(line)
+	}
+	// the headers are sent even if nothing was streamed.
+	lw.startOnce()
+}
+
+// failed reports whether the gateway failed before streaming.
+func (lw *lineWriter) failed() bool {
+	return lw.code != 0 && lw.code != http.StatusOK
+}
+
+func (lw *lineWriter) writeLine(line []byte) error {
+	lw.startOnce()
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil
+	}
+	return lw.write(line)
+}
+
+func (lw *lineWriter) startOnce() {
+	if lw.start == nil {
+		return
+	}
+	start := lw.start
+	lw.start = nil
+	h := lw.w.Header()
+	for k, vv := range lw.header {
+		if k != "Content-Type" && k != "Transfer-Encoding" && k != "Content-Length" {
+			h[k] = vv
+		}
+	}
+	start(h)
+}
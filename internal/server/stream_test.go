@@ -0,0 +1,223 @@
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/realHoangHai/awesome/internal/server"
+	"github.com/realHoangHai/awesome/pkg/jwt"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// chatService is a test service with a server stream counting up to its request, failing on negative
+// numbers, and a bidi stream echoing its messages in upper case, exposed by the gateway at
+// GET /v1/count?n= and POST /v1/chat.
+type chatService struct{}
+
+var (
+	countStream = grpc.StreamDesc{StreamName: "Count", ServerStreams: true}
+	chatStream  = grpc.StreamDesc{StreamName: "Chat", ServerStreams: true, ClientStreams: true}
+)
+
+func (s *chatService) Register(srv *grpc.Server) {
+	count, chat := countStream, chatStream
+	count.Handler = func(_ interface{}, stream grpc.ServerStream) error {
+		in := &wrapperspb.Int32Value{}
+		if err := stream.RecvMsg(in); err != nil {
+			return err
+		}
+		if in.Value < 0 {
+			return status.Error(codes.InvalidArgument, "negative count")
+		}
+		for i := int32(0); i < in.Value; i++ {
+			if err := stream.SendMsg(wrapperspb.String(fmt.Sprint(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	chat.Handler = func(_ interface{}, stream grpc.ServerStream) error {
+		for {
+			in := &wrapperspb.StringValue{}
+			if err := stream.RecvMsg(in); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if err := stream.SendMsg(wrapperspb.String(strings.ToUpper(in.Value))); err != nil {
+				return err
+			}
+		}
+	}
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Chat",
+		HandlerType: (*interface{})(nil),
+		Streams:     []grpc.StreamDesc{count, chat},
+	}, s)
+}
+
+func (s *chatService) RegisterWithEndpoint(ctx context.Context, mux *runtime.ServeMux, addr string, opts []grpc.DialOption) {
+	conn, err := grpc.DialContext(ctx, addr, opts...)
+	if err != nil {
+		panic(err)
+	}
+	forward := func(w http.ResponseWriter, r *http.Request, method string, desc *grpc.StreamDesc, send func(stream grpc.ClientStream, dec runtime.Decoder) error) {
+		inbound, outbound := runtime.MarshalerForRequest(mux, r)
+		ctx, err := runtime.AnnotateContext(r.Context(), mux, r, method)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outbound, w, r, err)
+			return
+		}
+		stream, err := conn.NewStream(ctx, desc, method)
+		if err != nil {
+			runtime.HTTPError(ctx, mux, outbound, w, r, err)
+			return
+		}
+		go func() {
+			if err := send(stream, inbound.NewDecoder(r.Body)); err == nil {
+				_ = stream.CloseSend()
+			}
+		}()
+		ctx = runtime.NewServerMetadataContext(ctx, runtime.ServerMetadata{})
+		runtime.ForwardResponseStream(ctx, mux, outbound, w, r, func() (proto.Message, error) {
+			out := &wrapperspb.StringValue{}
+			return out, stream.RecvMsg(out)
+		})
+	}
+	_ = mux.HandlePath(http.MethodGet, "/v1/count", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		forward(w, r, "/test.Chat/Count", &countStream, func(stream grpc.ClientStream, _ runtime.Decoder) error {
+			n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+			return stream.SendMsg(wrapperspb.Int32(int32(n)))
+		})
+	})
+	_ = mux.HandlePath(http.MethodPost, "/v1/chat", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		forward(w, r, "/test.Chat/Chat", &chatStream, func(stream grpc.ClientStream, dec runtime.Decoder) error {
+			for {
+				in := &wrapperspb.StringValue{}
+				if err := dec.Decode(in); err == io.EOF {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				if err := stream.SendMsg(in); err != nil {
+					return err
+				}
+			}
+		})
+	})
+}
+
+func TestStreamBridge(t *testing.T) {
+	secret := []byte("secret")
+	srv := startServer(t, []server.Service{&chatService{}},
+		server.JWT(string(secret)),
+		server.StreamBridge("https://example.com"),
+	)
+	token, err := jwt.Encode(jwt.Claims{Subject: "user"}, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := func(path, token string) (*http.Response, []string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, "http://"+srv.addr+path, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Authorization", token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var lines []string
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			if sc.Text() != "" {
+				lines = append(lines, sc.Text())
+			}
+		}
+		return resp, lines
+	}
+
+	t.Run("server-sent events", func(t *testing.T) {
+		resp, lines := events("/v1/count?n=3", token)
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("got content_type=%s, want text/event-stream", ct)
+		}
+		want := []string{`data: "0"`, `data: "1"`, `data: "2"`}
+		if strings.Join(lines, "|") != strings.Join(want, "|") {
+			t.Errorf("got events=%q, want events=%q", lines, want)
+		}
+	})
+
+	t.Run("error before streaming", func(t *testing.T) {
+		resp, lines := events("/v1/count?n=-1", token)
+		if resp.StatusCode != http.StatusBadRequest || len(lines) != 1 || !strings.Contains(lines[0], "negative count") {
+			t.Errorf("got status_code=%d, body=%q, want the error of the gateway", resp.StatusCode, lines)
+		}
+	})
+
+	t.Run("authentication", func(t *testing.T) {
+		resp, _ := events("/v1/count?n=3", "")
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("got status_code=%d, want status_code=%d", resp.StatusCode, http.StatusUnauthorized)
+		}
+	})
+
+	dial := func(origin string) (*websocket.Conn, error) {
+		cfg, err := websocket.NewConfig("ws://"+srv.addr+"/v1/chat", origin)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Header.Set("Authorization", token)
+		return websocket.DialConfig(cfg)
+	}
+
+	t.Run("websocket bidi stream", func(t *testing.T) {
+		ws, err := dial("http://" + srv.addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ws.Close()
+		for _, msg := range []string{"hello", "world"} {
+			if err := websocket.Message.Send(ws, fmt.Sprintf("%q", msg)); err != nil {
+				t.Fatal(err)
+			}
+			var got string
+			if err := websocket.Message.Receive(ws, &got); err != nil {
+				t.Fatal(err)
+			}
+			if want := fmt.Sprintf(`{"result":%q}`, strings.ToUpper(msg)); got != want {
+				t.Errorf("got message=%s, want message=%s", got, want)
+			}
+		}
+		// close the request stream, the server closes the WebSocket when the RPC ends.
+		if err := websocket.Message.Send(ws, ""); err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		if err := websocket.Message.Receive(ws, &got); err != io.EOF {
+			t.Errorf("got message=%q, err=%v, want EOF", got, err)
+		}
+	})
+
+	t.Run("websocket origins", func(t *testing.T) {
+		ws, err := dial("https://example.com")
+		if err != nil {
+			t.Fatalf("got err=%v, want allowed origin accepted", err)
+		}
+		ws.Close()
+		if _, err := dial("https://evil.example.com"); err == nil {
+			t.Error("got origin accepted, want origin rejected")
+		}
+	})
+}
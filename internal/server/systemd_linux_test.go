@@ -0,0 +1,75 @@
+//go:build linux
+// +build linux
+
+package server_test
+
+import (
+	"github.com/realHoangHai/awesome/internal/server"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// systemdChildEnv marks the test binary is re-executed as a service activated by a systemd socket named http.
+const systemdChildEnv = "AWESOME_TEST_SYSTEMD_CHILD"
+
+// runSystemdChild serves whoService on the socket activated listener until SIGTERM.
+func runSystemdChild() {
+	srv := server.New(
+		server.Address("systemd://http"),
+		server.ShutdownTimeout(time.Second),
+	)
+	if err := srv.Run(&whoService{}); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestSystemdSocketActivation(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := lis.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	defer f.Close()
+	defer lis.Close()
+
+	// LISTEN_PID must be the pid of the activated process, the shell keeps its pid when executing the test binary.
+	cmd := exec.Command("/bin/sh", "-c", `LISTEN_PID=$$ exec "$0" -test.run=^$`, os.Args[0])
+	cmd.Env = append(os.Environ(), systemdChildEnv+"=1", "LISTEN_FDS=1", "LISTEN_FDNAMES=http")
+	cmd.ExtraFiles = []*os.File{f}
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Process.Kill()
+
+	// the connections are queued by the listener until the child serves them.
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get("http://" + addr + "/whoami")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	// the gateway dials the gRPC server on the address of the listener, not on systemd://http.
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status_code=%d, body=%s, want status_code=%d", resp.StatusCode, body, http.StatusOK)
+	}
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("got err=%v, want the child stopped gracefully", err)
+	}
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"fmt"
+	"github.com/realHoangHai/awesome/internal/auth"
+	"github.com/realHoangHai/awesome/internal/health"
+	"github.com/realHoangHai/awesome/pkg/certs"
+	"github.com/realHoangHai/awesome/pkg/systemd"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+const (
+	defaultAutoTLSFolder = "certs"
+
+	// modes of auto TLS.
+	autoTLSSelfSigned = "self_signed"
+	autoTLSACME       = "acme"
+)
+
+var (
+	// reloadSignals are the signals triggering reload of the TLS certificates and the access rules,
+	// always handled so that they never terminate the server.
+	reloadSignals = []os.Signal{syscall.SIGHUP}
+)
+
+// initAutoTLS prepares the certificates configured by AutoTLS option.
+// Self-signed certificates are served from files the same as the ones configured by TLS option,
+// while ACME certificates are served by a dedicated source.
+func (s *Server) initAutoTLS() error {
+	if s.autoTLS == nil || !s.autoTLS.Enabled {
+		return nil
+	}
+	cfg := s.autoTLS
+	folder := cfg.Folder
+	if folder == "" {
+		folder = defaultAutoTLSFolder
+	}
+	var hosts []string
+	for _, h := range strings.Split(cfg.Host, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	switch strings.ToLower(cfg.Mode) {
+	case "", autoTLSSelfSigned:
+		m, err := certs.SelfSigned(folder, hosts, certs.Logger(s.getLogger()))
+		if err != nil {
+			return err
+		}
+		s.addCertManager(m)
+		s.tlsCertFile, s.tlsKeyFile = m.Files()
+		s.getLogger().Warnf("server: serving self-signed certificates, trust %s for development only", filepath.Join(folder, "ca.pem"))
+	case autoTLSACME:
+		opts := []certs.ACMEOption{
+			certs.ACMEDirectoryURL(cfg.DirectoryURL),
+			certs.ACMEEmail(cfg.Email),
+			certs.ACMERenewBefore(cfg.RenewBefore),
+			certs.ACMELogger(s.getLogger()),
+		}
+		if cfg.DirectoryCAFile != "" {
+			pool, err := certs.LoadCertPool(cfg.DirectoryCAFile)
+			if err != nil {
+				return err
+			}
+			opts = append(opts, certs.ACMERootCAs(pool))
+		}
+		a, err := certs.NewACME(folder, hosts, opts...)
+		if err != nil {
+			return err
+		}
+		s.tlsSource = a
+	default:
+		return fmt.Errorf("server: invalid auto TLS mode %q", cfg.Mode)
+	}
+	return nil
+}
+
+// certSource returns the source of the certificates served by the given endpoint.
+func (s *Server) certSource(e *endpoint) (certs.Source, error) {
+	if e.tlsSource != nil {
+		return e.tlsSource, nil
+	}
+	return s.certManager(e.tlsCertFile, e.tlsKeyFile)
+}
+
+// certManager returns the certificate manager of the given key pair, endpoints using
+// the same files share the same manager.
+func (s *Server) certManager(certFile, keyFile string) (*certs.Manager, error) {
+	if m, ok := s.certManagers[certFile+":"+keyFile]; ok {
+		return m, nil
+	}
+	m, err := certs.NewManager(certFile, keyFile, certs.Logger(s.getLogger()))
+	if err != nil {
+		return nil, err
+	}
+	s.addCertManager(m)
+	return m, nil
+}
+
+// addCertManager tracks the given manager and makes it watch the files for changes.
+// Its expiry is checked by the health check server if it supports adding checkers.
+func (s *Server) addCertManager(m *certs.Manager) {
+	certFile, keyFile := m.Files()
+	if err := m.Watch(); err != nil {
+		s.getLogger().Warnf("server: watch TLS certificate %s, reload on SIGHUP only, err: %v", certFile, err)
+	}
+	if adder, ok := s.healthSrv.(health.CheckerAdder); ok {
+		adder.AddChecker("tls:"+certFile, m)
+	}
+	if s.certManagers == nil {
+		s.certManagers = make(map[string]*certs.Manager)
+	}
+	s.certManagers[certFile+":"+keyFile] = m
+}
+
+// checkClientAuth returns an error if mutual TLS is enabled with a mode not verifying the client certificates,
+// the identities of the clients could be forged otherwise.
+func (s *Server) checkClientAuth() error {
+	if s.clientCAFile == "" {
+		return nil
+	}
+	switch s.clientAuth {
+	case tls.RequestClientCert, tls.RequireAnyClientCert:
+		return fmt.Errorf("server: client auth mode %v does not verify the client certificates, "+
+			"use tls.VerifyClientCertIfGiven or tls.RequireAndVerifyClientCert", s.clientAuth)
+	}
+	return nil
+}
+
+// serverTLSConfig returns the server side TLS config serving the certificates of the given source.
+// Client certificates are verified against the client CAs if mutual TLS is enabled.
+func (s *Server) serverTLSConfig(src certs.Source) (*tls.Config, error) {
+	cfg := src.TLSConfig()
+	if s.clientCAFile == "" {
+		return cfg, nil
+	}
+	pool, err := certs.LoadCertPool(s.clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = s.clientAuth
+	return cfg, nil
+}
+
+// newIdentityForwarder returns an identity forwarder signing with a random key,
+// the key never leaves the process since the gateway is served in-process.
+func newIdentityForwarder() (*auth.IdentityForwarder, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return auth.NewIdentityForwarder(key), nil
+}
+
+// ReloadCertificates reloads all TLS certificates in use from their files.
+// The current certificates are kept if the new ones are invalid, the first error is returned.
+func (s *Server) ReloadCertificates() error {
+	if _, err := systemd.Notify(systemd.NotifyReloading); err != nil {
+		s.getLogger().Warnf("server: notify systemd reloading, err: %v", err)
+	}
+	var rs error
+	for _, m := range s.certManagers {
+		if err := m.Reload(); err != nil {
+			s.getLogger().Errorf("server: reload TLS certificate, err: %v", err)
+			if rs == nil {
+				rs = err
+			}
+		}
+	}
+	if _, err := systemd.Notify(systemd.NotifyReady); err != nil {
+		s.getLogger().Warnf("server: notify systemd ready, err: %v", err)
+	}
+	return rs
+}
+
+func (s *Server) closeCertManagers() {
+	for _, m := range s.certManagers {
+		_ = m.Close()
+	}
+	if s.tlsSource != nil {
+		_ = s.tlsSource.Close()
+	}
+}
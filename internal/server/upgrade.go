@@ -0,0 +1,37 @@
+package server
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	defaultUpgradeTimeout = time.Minute
+)
+
+var (
+	// ErrUpgradeNotSupported reports that graceful upgrade is not supported on the current platform.
+	ErrUpgradeNotSupported = errors.New("server: graceful upgrade is not supported")
+	// ErrUpgradeInProgress reports that another upgrade is in progress.
+	ErrUpgradeInProgress = errors.New("server: another upgrade is in progress")
+)
+
+// GracefulUpgrade is an option to enable zero-downtime binary upgrade on SIGUSR2, or on SIGHUP
+// if there are neither TLS certificates nor access rules to reload. On receiving the signals, the server starts a new process of the current binary with
+// the same arguments, hands the listeners over to it and waits for it to report healthy
+// within the given timeout, then shutdown gracefully. Default timeout is 1 minute.
+// The UDP socket of HTTP3 is not handed over, so the server refuses to start with both options.
+// This option is supported on Linux only.
+func GracefulUpgrade(timeout time.Duration) Option {
+	return func(opts *Server) {
+		opts.upgradeEnabled = true
+		opts.upgradeTimeout = timeout
+	}
+}
+
+func (s *Server) getUpgradeTimeout() time.Duration {
+	if s.upgradeTimeout <= 0 {
+		return defaultUpgradeTimeout
+	}
+	return s.upgradeTimeout
+}
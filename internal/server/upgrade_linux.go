@@ -0,0 +1,204 @@
+//go:build linux
+// +build linux
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/realHoangHai/awesome/internal/health"
+	"github.com/realHoangHai/awesome/pkg/systemd"
+	"net"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	// upgradeFdNamesEnv holds names of the listeners handed over to the new process,
+	// in the order of their file descriptors starting from 3.
+	upgradeFdNamesEnv = "AWESOME_UPGRADE_FDNAMES"
+	// upgradeReadyFdEnv holds the file descriptor used by the new process to report it is ready.
+	upgradeReadyFdEnv = "AWESOME_UPGRADE_READY_FD"
+	// upgradeFdsStart is the first file descriptor passed to the new process.
+	upgradeFdsStart = 3
+)
+
+var (
+	upgradeSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR2}
+
+	inheritOnce sync.Once
+	inheritMu   sync.Mutex
+	inherited   map[string]net.Listener
+	inheritErr  error
+)
+
+// Upgrade starts a new process of the current binary with the same arguments, hands
+// the listeners over to it and waits for it to report healthy. If the new process
+// becomes healthy in time, the server is shutdown gracefully, otherwise the new process is killed
+// and the server keeps serving.
+func (s *Server) Upgrade() error {
+	if !atomic.CompareAndSwapInt32(&s.upgrading, 0, 1) {
+		return ErrUpgradeInProgress
+	}
+	defer atomic.StoreInt32(&s.upgrading, 0)
+	logger := s.getLogger()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(s.listeners))
+	for name := range s.listeners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	files := make([]*os.File, 0, len(names)+1)
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for _, name := range names {
+		fl, ok := s.listeners[name].(interface{ File() (*os.File, error) })
+		if !ok {
+			return fmt.Errorf("server: listener %s can not be handed over", name)
+		}
+		f, err := fl.File()
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	files = append(files, w)
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("%s=%s", upgradeFdNamesEnv, strings.Join(names, ":")),
+		fmt.Sprintf("%s=%d", upgradeReadyFdEnv, upgradeFdsStart+len(names)),
+	)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// the write end must be closed in this process for detecting the child exits.
+	_ = w.Close()
+	files = files[:len(files)-1]
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	logger.Infof("server: started new process, pid: %d, waiting for it to be ready", cmd.Process.Pid)
+
+	ready := make(chan error, 1)
+	go func() {
+		_ = r.SetReadDeadline(time.Now().Add(s.getUpgradeTimeout()))
+		buf := make([]byte, 1)
+		_, err := r.Read(buf)
+		ready <- err
+	}()
+	select {
+	case err := <-ready:
+		if err != nil {
+			_ = cmd.Process.Kill()
+			return fmt.Errorf("server: new process is not ready, err: %w", err)
+		}
+	case err := <-exited:
+		return fmt.Errorf("server: new process exited unexpectedly, err: %v", err)
+	}
+	logger.Infof("server: new process is ready, pid: %d, shutting down", cmd.Process.Pid)
+
+	if _, err := systemd.Notify(fmt.Sprintf("MAINPID=%d", cmd.Process.Pid)); err != nil {
+		logger.Warnf("server: notify systemd main pid, err: %v", err)
+	}
+	// the socket files are now owned by the new process, they must not be removed on close.
+	for _, lis := range s.listeners {
+		if ul, ok := lis.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	go s.Shutdown(context.Background())
+	return nil
+}
+
+// notifyUpgradeReady reports to the parent process that the server is healthy
+// if the server is started by a graceful upgrade.
+func (s *Server) notifyUpgradeReady(ctx context.Context) error {
+	v := os.Getenv(upgradeReadyFdEnv)
+	if v == "" {
+		return nil
+	}
+	_ = os.Unsetenv(upgradeReadyFdEnv)
+	fd, err := strconv.Atoi(v)
+	if err != nil {
+		return err
+	}
+	f := os.NewFile(uintptr(fd), "upgrade-ready")
+	defer f.Close()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		rs, err := s.healthSrv.Check(ctx, &health.CheckRequest{Service: health.OverallServiceName})
+		if err == nil && rs.Status == health.StatusServing {
+			_, err := f.Write([]byte{1})
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// inheritedListener returns the listener with the given name handed over by the parent process
+// in a graceful upgrade, or nil if there is no such listener.
+// A listener can be acquired only once.
+func inheritedListener(name string) (net.Listener, error) {
+	inheritOnce.Do(func() {
+		inherited, inheritErr = loadInherited()
+	})
+	if inheritErr != nil {
+		return nil, inheritErr
+	}
+	inheritMu.Lock()
+	defer inheritMu.Unlock()
+	lis := inherited[name]
+	delete(inherited, name)
+	return lis, nil
+}
+
+func loadInherited() (map[string]net.Listener, error) {
+	v := os.Getenv(upgradeFdNamesEnv)
+	if v == "" {
+		return nil, nil
+	}
+	// the variables must not be inherited by the next upgrade.
+	_ = os.Unsetenv(upgradeFdNamesEnv)
+	rs := make(map[string]net.Listener)
+	for i, name := range strings.Split(v, ":") {
+		f := os.NewFile(uintptr(upgradeFdsStart+i), name)
+		lis, err := net.FileListener(f)
+		// FileListener duplicates the file descriptor, the original one is no longer needed.
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("server: inherit listener %s, err: %w", name, err)
+		}
+		rs[name] = lis
+	}
+	return rs, nil
+}
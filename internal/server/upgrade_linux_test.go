@@ -0,0 +1,139 @@
+//go:build linux
+// +build linux
+
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/realHoangHai/awesome/internal/server"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// upgradeChildEnv marks the test binary is re-executed as the new process of a graceful upgrade.
+const upgradeChildEnv = "AWESOME_TEST_UPGRADE_CHILD"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(upgradeChildEnv) != "" {
+		srv := server.New(
+			server.HandlerFunc("/pid", pidHandler),
+			server.ShutdownTimeout(time.Second),
+		)
+		if err := srv.Run(); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if os.Getenv(systemdChildEnv) != "" {
+		runSystemdChild()
+	}
+	os.Exit(m.Run())
+}
+
+func pidHandler(w http.ResponseWriter, r *http.Request) {
+	_, _ = fmt.Fprint(w, os.Getpid())
+}
+
+func TestGracefulUpgrade(t *testing.T) {
+	cases := []struct {
+		name    string
+		upgrade func(srv *server.Server) error
+	}{
+		{
+			name:    "upgrade",
+			upgrade: (*server.Server).Upgrade,
+		},
+		{
+			name: "SIGHUP with nothing to reload",
+			upgrade: func(srv *server.Server) error {
+				return syscall.Kill(os.Getpid(), syscall.SIGHUP)
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			testGracefulUpgrade(t, c.upgrade)
+		})
+	}
+}
+
+func testGracefulUpgrade(t *testing.T, upgrade func(srv *server.Server) error) {
+	t.Setenv(upgradeChildEnv, "1")
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := server.New(
+		server.Listener(lis),
+		server.HandlerFunc("/pid", pidHandler),
+		server.GracefulUpgrade(10*time.Second),
+		server.ShutdownTimeout(time.Second),
+	)
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.RunWithContext(context.Background())
+	}()
+	getPID := func() (int, error) {
+		client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+		resp, err := client.Get("http://" + lis.Addr().String() + "/pid")
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return 0, err
+		}
+		return strconv.Atoi(string(b))
+	}
+	for i := 0; i < 50; i++ {
+		if _, err = getPID(); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := upgrade(srv); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("got run err=%v, want err=nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("old server did not stop after upgrade")
+	}
+	// the listener is now served by the new process only.
+	pid, err := getPID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Kill(pid, syscall.SIGTERM)
+	if pid == os.Getpid() {
+		t.Fatalf("got pid=%d, want pid of the new process", pid)
+	}
+}
+
+func TestSIGHUPWithNothingToReload(t *testing.T) {
+	// the signals are handled once serving.
+	srv := startServer(t, nil)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-srv.done:
+		t.Fatalf("got server stopped on SIGHUP, err=%v, want it still serving", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !linux
+// +build !linux
+
+package server
+
+import (
+	"context"
+	"net"
+	"os"
+)
+
+var upgradeSignals []os.Signal
+
+// Upgrade is not supported on this platform.
+func (s *Server) Upgrade() error {
+	return ErrUpgradeNotSupported
+}
+
+func (s *Server) notifyUpgradeReady(ctx context.Context) error {
+	return nil
+}
+
+func inheritedListener(name string) (net.Listener, error) {
+	return nil, nil
+}
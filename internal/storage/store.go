@@ -8,6 +8,7 @@ import (
 	"github.com/realHoangHai/awesome/internal/storage/ent"
 	"github.com/realHoangHai/awesome/internal/storage/ent/migrate"
 	"github.com/realHoangHai/awesome/pkg/log"
+	"io"
 	"time"
 	// init mysql driver
 	_ "github.com/go-sql-driver/mysql"
@@ -63,5 +64,10 @@ func NewStore(entClient *ent.Client, redisCmd redis.Cmdable) (*Store, func(), er
 		if err := store.db.Close(); err != nil {
 			log.Error(err)
 		}
+		if c, ok := store.redisCli.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				log.Error(err)
+			}
+		}
 	}, nil
 }
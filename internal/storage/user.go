@@ -48,8 +48,11 @@ func (r *userRepo) GetUser(ctx context.Context, id int64) (*biz.User, error) {
 	if err != nil {
 		// fetch from db while cache missed
 		target, err = r.store.db.User.Get(ctx, id)
+		if ent.IsNotFound(err) {
+			return nil, biz.ErrUserNotFound.WithCause(err)
+		}
 		if err != nil {
-			return nil, biz.ErrUserNotFound
+			return nil, err
 		}
 		// set cache
 		r.setUserCache(ctx, target, cacheKey)
@@ -76,8 +79,11 @@ func (r *userRepo) FindByUsername(ctx context.Context, username string) (*biz.Us
 			Query().
 			Where(user.UsernameEQ(username)).
 			Only(ctx)
+		if ent.IsNotFound(err) {
+			return nil, biz.ErrUserNotFound.WithCause(err)
+		}
 		if err != nil {
-			return nil, biz.ErrUserNotFound
+			return nil, err
 		}
 		// set cache
 		r.setUserCache(ctx, target, cacheKey)
@@ -0,0 +1,180 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"github.com/realHoangHai/awesome/pkg/log"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// ACME obtains certificates of the given hosts from an ACME CA, i.e... Let's Encrypt,
+	// using the tls-alpn-01 challenge, hence the server must be reachable on port 443 of the hosts.
+	// The certificates and the account key are cached in a folder, and renewed in the background before they expire.
+	ACME struct {
+		m     *autocert.Manager
+		hosts []string
+		log   log.Logger
+
+		closeOnce sync.Once
+		done      chan struct{}
+	}
+
+	// ACMEOption is a configuration option of ACME.
+	ACMEOption func(*ACME)
+)
+
+var (
+	_ Source = &ACME{}
+
+	// ecdsaCipherSuites makes autocert serve ECDSA certificates, the same as for Go clients.
+	ecdsaCipherSuites = []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}
+)
+
+// NewACME returns a new ACME obtaining certificates of the given hosts, caching them in the given folder.
+// The certificates are requested in the background immediately, instead of on the first TLS handshake.
+func NewACME(folder string, hosts []string, opts ...ACMEOption) (*ACME, error) {
+	if len(hosts) == 0 {
+		return nil, errors.New("certs: ACME requires at least one host")
+	}
+	a := &ACME{
+		m: &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			Cache:      autocert.DirCache(folder),
+			HostPolicy: autocert.HostWhitelist(hosts...),
+			Client:     &acme.Client{DirectoryURL: autocert.DefaultACMEDirectory},
+		},
+		hosts: hosts,
+		done:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.log == nil {
+		a.log = log.Root()
+	}
+	go a.prefetch()
+	return a, nil
+}
+
+// ACMEDirectoryURL is an option to set the directory URL of the ACME CA.
+// Default is Let's Encrypt production directory.
+func ACMEDirectoryURL(url string) ACMEOption {
+	return func(a *ACME) {
+		if url != "" {
+			a.m.Client.DirectoryURL = url
+		}
+	}
+}
+
+// ACMEEmail is an option to set the contact email of the ACME account.
+func ACMEEmail(email string) ACMEOption {
+	return func(a *ACME) {
+		a.m.Email = email
+	}
+}
+
+// ACMERootCAs is an option to set the root CAs trusted when connecting to the ACME CA,
+// i.e... the root of a local test CA like Pebble.
+func ACMERootCAs(pool *x509.CertPool) ACMEOption {
+	return func(a *ACME) {
+		a.m.Client.HTTPClient = &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+			},
+		}
+	}
+}
+
+// ACMERenewBefore is an option to set how early the certificates are renewed before they expire.
+// Default is 30 days.
+func ACMERenewBefore(d time.Duration) ACMEOption {
+	return func(a *ACME) {
+		a.m.RenewBefore = d
+	}
+}
+
+// ACMELogger is an option to set logger of ACME.
+func ACMELogger(l log.Logger) ACMEOption {
+	return func(a *ACME) {
+		a.log = l
+	}
+}
+
+// GetCertificate returns the certificate of the requested host, it is used as tls.Config.GetCertificate.
+func (a *ACME) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return a.m.GetCertificate(hello)
+}
+
+// TLSConfig implements Source.
+func (a *ACME) TLSConfig() *tls.Config {
+	cfg := a.m.TLSConfig()
+	cfg.MinVersion = tls.VersionTLS12
+	return cfg
+}
+
+// ClientTLSConfig implements Source. The peer is trusted if it serves the same certificate
+// as the one served for the first host.
+func (a *ACME) ClientTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: a.hosts[0],
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return a.m.GetCertificate(a.hello())
+		},
+		// the verification is done by VerifyConnection against the served certificate.
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			cert, err := a.m.GetCertificate(a.hello())
+			if err != nil {
+				return err
+			}
+			if len(cs.PeerCertificates) == 0 || !bytes.Equal(cs.PeerCertificates[0].Raw, cert.Certificate[0]) {
+				return errors.New("certs: peer certificate is not the one served by ACME")
+			}
+			return nil
+		},
+	}
+}
+
+// Close stops obtaining the certificates in the background.
+// Note that the renewal scheduled by autocert can not be stopped.
+func (a *ACME) Close() error {
+	a.closeOnce.Do(func() {
+		close(a.done)
+	})
+	return nil
+}
+
+// prefetch obtains the certificates of all the hosts, which also schedules their renewal.
+func (a *ACME) prefetch() {
+	for _, h := range a.hosts {
+		select {
+		case <-a.done:
+			return
+		default:
+		}
+		cert, err := a.m.GetCertificate(&tls.ClientHelloInfo{ServerName: h, CipherSuites: ecdsaCipherSuites})
+		if err != nil {
+			a.log.Errorf("certs: obtain ACME certificate of %s, err: %v", h, err)
+			continue
+		}
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+			expiryGauge.WithLabelValues("acme:" + h).Set(float64(leaf.NotAfter.Unix()))
+			a.log.Fields("host", h, "not_after", leaf.NotAfter).Info("certs: ACME certificate is ready")
+		}
+	}
+}
+
+// hello returns the ClientHelloInfo of a Go client connecting to the first host,
+// which is served with an ECDSA certificate.
+func (a *ACME) hello() *tls.ClientHelloInfo {
+	return &tls.ClientHelloInfo{ServerName: a.hosts[0], CipherSuites: ecdsaCipherSuites}
+}
@@ -0,0 +1,292 @@
+package certs_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/realHoangHai/awesome/pkg/certs"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeACME is an in-process ACME CA, a minimal stand-in of Pebble. The orders are ready as soon as created,
+// i.e... the challenges are skipped, and the certificates are issued by its own CA.
+type fakeACME struct {
+	srv    *httptest.Server
+	caKey  *ecdsa.PrivateKey
+	caCert *x509.Certificate
+
+	mu     sync.Mutex
+	orders int
+	certs  map[string][]byte
+}
+
+func newFakeACME(t *testing.T) *fakeACME {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fake ACME CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeACME{caKey: key, caCert: ca, certs: make(map[string][]byte)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/directory", f.directory)
+	mux.HandleFunc("/new-nonce", func(w http.ResponseWriter, r *http.Request) {
+		f.nonce(w)
+	})
+	mux.HandleFunc("/new-account", f.newAccount)
+	mux.HandleFunc("/new-order", f.newOrder)
+	mux.HandleFunc("/finalize/", f.finalize)
+	mux.HandleFunc("/cert/", f.cert)
+	f.srv = httptest.NewTLSServer(mux)
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+// rootCAs returns the root CAs trusted for connecting to the directory.
+func (f *fakeACME) rootCAs() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(f.srv.Certificate())
+	return pool
+}
+
+func (f *fakeACME) url(path string) string {
+	return f.srv.URL + path
+}
+
+func (f *fakeACME) nonce(w http.ResponseWriter) {
+	w.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", time.Now().UnixNano()))
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+func (f *fakeACME) directory(w http.ResponseWriter, r *http.Request) {
+	f.nonce(w)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"newNonce":   f.url("/new-nonce"),
+		"newAccount": f.url("/new-account"),
+		"newOrder":   f.url("/new-order"),
+		"revokeCert": f.url("/revoke-cert"),
+		"keyChange":  f.url("/key-change"),
+		"meta":       map[string]interface{}{"termsOfService": f.url("/terms")},
+	})
+}
+
+func (f *fakeACME) newAccount(w http.ResponseWriter, r *http.Request) {
+	f.nonce(w)
+	w.Header().Set("Location", f.url("/account/1"))
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"status": "valid"})
+}
+
+func (f *fakeACME) newOrder(w http.ResponseWriter, r *http.Request) {
+	f.nonce(w)
+	var req struct {
+		Identifiers []map[string]string `json:"identifiers"`
+	}
+	if err := decodeJWS(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.orders++
+	id := f.orders
+	f.mu.Unlock()
+	w.Header().Set("Location", f.url(fmt.Sprintf("/order/%d", id)))
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"status":         "ready",
+		"identifiers":    req.Identifiers,
+		"authorizations": []string{},
+		"finalize":       f.url(fmt.Sprintf("/finalize/%d", id)),
+	})
+}
+
+func (f *fakeACME) finalize(w http.ResponseWriter, r *http.Request) {
+	f.nonce(w)
+	var req struct {
+		CSR string `json:"csr"`
+	}
+	if err := decodeJWS(r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	b, err := base64.RawURLEncoding.DecodeString(req.CSR)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(b)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: csr.Subject.CommonName},
+		DNSNames:     csr.DNSNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		// the lifetime of Let's Encrypt certificates, not renewed in the tests.
+		NotAfter:    time.Now().Add(90 * 24 * time.Hour),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, f.caCert, csr.PublicKey, f.caKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	id := filepath.Base(r.URL.Path)
+	chain := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.caCert.Raw})...)
+	f.mu.Lock()
+	f.certs[id] = chain
+	f.mu.Unlock()
+	w.Header().Set("Location", f.url("/order/"+id))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":      "valid",
+		"finalize":    f.url(r.URL.Path),
+		"certificate": f.url("/cert/" + id),
+	})
+}
+
+func (f *fakeACME) cert(w http.ResponseWriter, r *http.Request) {
+	f.nonce(w)
+	f.mu.Lock()
+	chain, ok := f.certs[filepath.Base(r.URL.Path)]
+	f.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pem-certificate-chain")
+	_, _ = w.Write(chain)
+}
+
+// issued returns the number of the orders created.
+func (f *fakeACME) issued() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.orders
+}
+
+// decodeJWS decodes the payload of the flattened JWS of the request, the signature is not verified.
+func decodeJWS(r *http.Request, v interface{}) error {
+	var jws struct {
+		Payload string `json:"payload"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jws); err != nil {
+		return err
+	}
+	b, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestACME(t *testing.T) {
+	ca := newFakeACME(t)
+	dir := t.TempDir()
+	a, err := certs.NewACME(dir, []string{"example.org"},
+		certs.ACMEDirectoryURL(ca.url("/directory")),
+		certs.ACMERootCAs(ca.rootCAs()),
+		certs.ACMEEmail("admin@example.org"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	cert, err := a.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.caCert)
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "example.org", Roots: roots}); err != nil {
+		t.Fatalf("got certificate not issued by the ACME CA for example.org, err: %v", err)
+	}
+	// the hosts not configured are refused.
+	if _, err := a.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.org"}); err == nil {
+		t.Fatal("got certificate of other.org, want error")
+	}
+
+	// the peers serving the same certificate are trusted by the client config.
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- tls.Server(serverConn, a.TLSConfig()).Handshake()
+	}()
+	if err := tls.Client(clientConn, a.ClientTLSConfig()).Handshake(); err != nil {
+		t.Fatalf("got client handshake err=%v, want the served certificate trusted", err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("got server handshake err=%v, want err=nil", err)
+	}
+
+	// the certificates are cached, they are not requested again by a new instance.
+	if _, err := os.Stat(filepath.Join(dir, "example.org")); err != nil {
+		t.Fatalf("got no cached certificate, err: %v", err)
+	}
+	n := ca.issued()
+	cached, err := certs.NewACME(dir, []string{"example.org"},
+		certs.ACMEDirectoryURL(ca.url("/directory")),
+		certs.ACMERootCAs(ca.rootCAs()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cached.Close()
+	if _, err := cached.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.org"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := ca.issued(); got != n {
+		t.Fatalf("got orders=%d, want orders=%d, the cached certificate used", got, n)
+	}
+}
+
+func TestACMENoHost(t *testing.T) {
+	if _, err := certs.NewACME(t.TempDir(), nil); err == nil {
+		t.Fatal("got ACME without host, want error")
+	}
+}
@@ -0,0 +1,353 @@
+// Package certs provides a TLS certificate manager that reloads the certificate
+// and its private key from files without restarting the server.
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/realHoangHai/awesome/pkg/log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultExpiryWarning = 7 * 24 * time.Hour
+	// reloadDelay is the time to wait after a file change before reloading,
+	// so that the certificate and the key written separately are reloaded together.
+	reloadDelay = 100 * time.Millisecond
+	// k8sDataDir is the symlink swapped by Kubernetes when updating mounted secrets.
+	k8sDataDir = "..data"
+)
+
+var (
+	// ErrExpired reports that the certificate has expired.
+	ErrExpired = errors.New("certs: certificate has expired")
+
+	expiryGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "tls",
+		Name:      "certificate_expiry_timestamp_seconds",
+		Help:      "Expiry time of the TLS certificate in use, in unix timestamp.",
+	}, []string{"cert_file"})
+	reloadCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tls",
+		Name:      "certificate_reloads_total",
+		Help:      "Total number of TLS certificate reloads by result.",
+	}, []string{"cert_file", "result"})
+)
+
+func init() {
+	prometheus.MustRegister(expiryGauge, reloadCounter)
+}
+
+type (
+	// Manager holds a certificate loaded from files and reloads it when the files change
+	// or Reload is called. The new certificate is validated before replacing the current one,
+	// so that an invalid rotation never breaks the server.
+	Manager struct {
+		certFile      string
+		keyFile       string
+		expiryWarning time.Duration
+		log           log.Logger
+
+		mu   sync.RWMutex
+		cert *tls.Certificate
+
+		watcher   *fsnotify.Watcher
+		closeOnce sync.Once
+		done      chan struct{}
+	}
+
+	// Option is a configuration option of the Manager.
+	Option func(*Manager)
+
+	// Source is a source of the server certificates.
+	Source interface {
+		// TLSConfig returns a server side tls.Config serving the certificates.
+		TLSConfig() *tls.Config
+		// ClientTLSConfig returns a client side tls.Config for dialing the server itself.
+		ClientTLSConfig() *tls.Config
+		// Close releases the underlying resources.
+		Close() error
+	}
+)
+
+var (
+	_ Source = &Manager{}
+)
+
+// NewManager loads the certificate and the private key from the given files
+// and returns a new Manager serving them.
+func NewManager(certFile, keyFile string, opts ...Option) (*Manager, error) {
+	m := &Manager{
+		certFile:      certFile,
+		keyFile:       keyFile,
+		expiryWarning: defaultExpiryWarning,
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.log == nil {
+		m.log = log.Root()
+	}
+	cert, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	m.cert = cert
+	expiryGauge.WithLabelValues(m.certFile).Set(float64(cert.Leaf.NotAfter.Unix()))
+	return m, nil
+}
+
+// Logger is an option to set logger of the Manager.
+func Logger(l log.Logger) Option {
+	return func(m *Manager) {
+		m.log = l
+	}
+}
+
+// ExpiryWarning is an option to set how long before expiry the health check starts warning.
+// Default is 7 days.
+func ExpiryWarning(d time.Duration) Option {
+	return func(m *Manager) {
+		m.expiryWarning = d
+	}
+}
+
+// GetCertificate returns the current certificate, it is used as tls.Config.GetCertificate.
+func (m *Manager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cert, nil
+}
+
+// Certificate returns the current certificate.
+func (m *Manager) Certificate() *tls.Certificate {
+	cert, _ := m.GetCertificate(nil)
+	return cert
+}
+
+// Files returns the certificate and the private key files.
+func (m *Manager) Files() (certFile, keyFile string) {
+	return m.certFile, m.keyFile
+}
+
+// NotAfter returns the expiry time of the current certificate.
+func (m *Manager) NotAfter() time.Time {
+	return m.Certificate().Leaf.NotAfter
+}
+
+// TLSConfig returns a server side tls.Config serving the current certificate.
+func (m *Manager) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: m.GetCertificate,
+	}
+}
+
+// ClientTLSConfig returns a client side tls.Config trusting the certificates served by the Manager,
+// it is used for dialing the server itself, i.e... from the gRPC gateway.
+// The trusted certificates are always the current ones, hence the config keeps working after reloads.
+// The current certificate is also presented as the client certificate if the server requests one.
+func (m *Manager) ClientTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return m.Certificate(), nil
+		},
+		// the verification is done by VerifyConnection against the current certificates.
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("certs: no peer certificate")
+			}
+			roots := x509.NewCertPool()
+			for _, der := range m.Certificate().Certificate {
+				c, err := x509.ParseCertificate(der)
+				if err != nil {
+					return err
+				}
+				roots.AddCert(c)
+			}
+			intermediates := x509.NewCertPool()
+			for _, c := range cs.PeerCertificates[1:] {
+				intermediates.AddCert(c)
+			}
+			_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+				DNSName:       cs.ServerName,
+				Roots:         roots,
+				Intermediates: intermediates,
+			})
+			return err
+		},
+	}
+}
+
+// Reload reloads the certificate from the files. If the new certificate is invalid,
+// the current one is kept and an error is returned.
+func (m *Manager) Reload() error {
+	cert, err := m.load()
+	if err != nil {
+		reloadCounter.WithLabelValues(m.certFile, "failure").Inc()
+		return err
+	}
+	m.mu.Lock()
+	unchanged := bytes.Equal(m.cert.Certificate[0], cert.Certificate[0])
+	m.cert = cert
+	m.mu.Unlock()
+	if unchanged {
+		return nil
+	}
+	reloadCounter.WithLabelValues(m.certFile, "success").Inc()
+	expiryGauge.WithLabelValues(m.certFile).Set(float64(cert.Leaf.NotAfter.Unix()))
+	m.log.Fields("cert_file", m.certFile, "not_after", cert.Leaf.NotAfter).Info("certs: certificate reloaded")
+	return nil
+}
+
+// Watch starts watching the certificate and key files and reloads them on changes.
+// Watching the parent directories makes it work with files replaced atomically by
+// renaming as well as Kubernetes mounted secrets.
+func (m *Manager) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	dirs := map[string]bool{}
+	for _, f := range []string{m.certFile, m.keyFile} {
+		dir := filepath.Dir(f)
+		if dirs[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return err
+		}
+		dirs[dir] = true
+	}
+	m.watcher = watcher
+	go m.watch()
+	return nil
+}
+
+func (m *Manager) watch() {
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-m.done:
+			return
+		case ev, ok := <-m.watcher.Events:
+			if !ok {
+				return
+			}
+			if m.isWatched(ev.Name) {
+				timer.Reset(reloadDelay)
+			}
+		case err, ok := <-m.watcher.Errors:
+			if !ok {
+				return
+			}
+			m.log.Errorf("certs: watch %s, err: %v", m.certFile, err)
+		case <-timer.C:
+			if err := m.Reload(); err != nil {
+				m.log.Errorf("certs: reload %s, keep using the current certificate, err: %v", m.certFile, err)
+			}
+		}
+	}
+}
+
+func (m *Manager) isWatched(name string) bool {
+	name = filepath.Clean(name)
+	return name == filepath.Clean(m.certFile) ||
+		name == filepath.Clean(m.keyFile) ||
+		filepath.Base(name) == k8sDataDir
+}
+
+// Close stops watching the files.
+func (m *Manager) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.done)
+		if m.watcher != nil {
+			err = m.watcher.Close()
+		}
+	})
+	return err
+}
+
+// CheckHealth implements health.Checker. It logs a warning if the certificate
+// is about to expire and returns an error if it has expired.
+func (m *Manager) CheckHealth(ctx context.Context) error {
+	notAfter := m.NotAfter()
+	remaining := time.Until(notAfter)
+	if remaining <= 0 {
+		return fmt.Errorf("%w, cert_file: %s, not_after: %v", ErrExpired, m.certFile, notAfter)
+	}
+	if remaining < m.expiryWarning {
+		m.log.Context(ctx).Fields("cert_file", m.certFile, "not_after", notAfter).
+			Warnf("certs: certificate will expire in %v", remaining.Round(time.Second))
+	}
+	return nil
+}
+
+// LoadCertPool returns a certificate pool of the PEM encoded certificates in the given files,
+// i.e... the CA bundle used to verify client certificates.
+func LoadCertPool(files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("certs: no certificate found in %s", f)
+		}
+	}
+	return pool, nil
+}
+
+// ParseClientAuth parses the client authentication mode, the valid values are:
+// none, request, require, verify_if_given and require_and_verify.
+// Note that request and require do not verify the certificates presented by the clients.
+func ParseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(mode) {
+	case "", "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.RequestClientCert, nil
+	case "require":
+		return tls.RequireAnyClientCert, nil
+	case "verify_if_given":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require_and_verify":
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return tls.NoClientCert, fmt.Errorf("certs: invalid client auth mode %q", mode)
+}
+
+// load loads and validates the certificate and the private key.
+func (m *Manager) load() (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(m.certFile, m.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("certs: load key pair, err: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("certs: parse certificate, err: %w", err)
+	}
+	if time.Now().After(leaf.NotAfter) {
+		return nil, fmt.Errorf("%w, cert_file: %s, not_after: %v", ErrExpired, m.certFile, leaf.NotAfter)
+	}
+	cert.Leaf = leaf
+	return &cert, nil
+}
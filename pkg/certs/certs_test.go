@@ -0,0 +1,131 @@
+package certs_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"github.com/realHoangHai/awesome/pkg/certs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, 1, time.Now().Add(time.Hour))
+	m, err := certs.NewManager(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if got := m.Certificate().Leaf.SerialNumber.Int64(); got != 1 {
+		t.Fatalf("got serial=%d, want serial=1", got)
+	}
+
+	// invalid key pair must not replace the current certificate.
+	if err := os.WriteFile(keyFile, []byte("invalid"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Reload(); err == nil {
+		t.Fatal("got reload ok, want error for invalid key")
+	}
+	if got := m.Certificate().Leaf.SerialNumber.Int64(); got != 1 {
+		t.Fatalf("got serial=%d, want serial=1 after failed reload", got)
+	}
+
+	// expired certificate must not replace the current certificate.
+	writeCert(t, certFile, keyFile, 2, time.Now().Add(-time.Hour))
+	if err := m.Reload(); !errors.Is(err, certs.ErrExpired) {
+		t.Fatalf("got err=%v, want err=%v", err, certs.ErrExpired)
+	}
+
+	writeCert(t, certFile, keyFile, 3, time.Now().Add(time.Hour))
+	if err := m.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Certificate().Leaf.SerialNumber.Int64(); got != 3 {
+		t.Fatalf("got serial=%d, want serial=3", got)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, 1, time.Now().Add(time.Hour))
+	m, err := certs.NewManager(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if err := m.Watch(); err != nil {
+		t.Fatal(err)
+	}
+	writeCert(t, certFile, keyFile, 2, time.Now().Add(time.Hour))
+	deadline := time.Now().Add(5 * time.Second)
+	for m.Certificate().Leaf.SerialNumber.Int64() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("certificate is not reloaded after the files changed")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, 1, time.Now().Add(time.Second))
+	m, err := certs.NewManager(certFile, keyFile, certs.ExpiryWarning(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// about to expire is only a warning.
+	if err := m.CheckHealth(context.Background()); err != nil {
+		t.Fatalf("got err=%v, want nil before expiry", err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if err := m.CheckHealth(context.Background()); !errors.Is(err, certs.ErrExpired) {
+		t.Fatalf("got err=%v, want err=%v", err, certs.ErrExpired)
+	}
+}
+
+// writeCert writes a self-signed certificate for localhost with the given serial and expiry.
+func writeCert(t *testing.T, certFile, keyFile string, serial int64, notAfter time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-2 * time.Hour),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,204 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// files of the self-signed certificates in the cache folder.
+	caCertFileName = "ca.pem"
+	caKeyFileName  = "ca-key.pem"
+	certFileName   = "cert.pem"
+	keyFileName    = "key.pem"
+
+	caValidity       = 10 * 365 * 24 * time.Hour
+	leafValidity     = 90 * 24 * time.Hour
+	leafRenewBefore  = 30 * 24 * time.Hour
+	leafRenewalCheck = 12 * time.Hour
+)
+
+var (
+	// defaultHosts are the hosts of the self-signed certificate if no host is given.
+	defaultHosts = []string{"localhost", "127.0.0.1", "::1"}
+)
+
+// SelfSigned returns a Manager serving a certificate of the given hosts signed by a local CA,
+// both are generated and cached in the given folder, the CA certificate (ca.pem) can be added
+// to the trust store of the clients. The certificate is re-issued in the background before it expires.
+// It is meant for development only.
+func SelfSigned(folder string, hosts []string, opts ...Option) (*Manager, error) {
+	if len(hosts) == 0 {
+		hosts = defaultHosts
+	}
+	renew := func() error {
+		return ensureSelfSigned(folder, hosts, time.Now())
+	}
+	if err := renew(); err != nil {
+		return nil, err
+	}
+	m, err := NewManager(filepath.Join(folder, certFileName), filepath.Join(folder, keyFileName), opts...)
+	if err != nil {
+		return nil, err
+	}
+	go m.renewLoop(renew, leafRenewalCheck)
+	return m, nil
+}
+
+// renewLoop periodically calls renew and reloads the renewed certificate until the Manager is closed.
+func (m *Manager) renewLoop(renew func() error, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+			if err := renew(); err != nil {
+				m.log.Errorf("certs: renew %s, err: %v", m.certFile, err)
+				continue
+			}
+			if err := m.Reload(); err != nil {
+				m.log.Errorf("certs: reload %s, err: %v", m.certFile, err)
+			}
+		}
+	}
+}
+
+// ensureSelfSigned makes sure the folder contains a CA and a certificate of the hosts
+// signed by the CA that does not expire soon, they are generated if not.
+func ensureSelfSigned(folder string, hosts []string, now time.Time) error {
+	if err := os.MkdirAll(folder, 0o700); err != nil {
+		return err
+	}
+	ca, caKey, err := loadPair(filepath.Join(folder, caCertFileName), filepath.Join(folder, caKeyFileName))
+	if err != nil || now.Add(leafValidity).After(ca.NotAfter) {
+		ca, caKey, err = createCert(&x509.Certificate{
+			Subject:               pkix.Name{CommonName: "awesome development CA"},
+			NotBefore:             now.Add(-time.Hour),
+			NotAfter:              now.Add(caValidity),
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}, nil, nil)
+		if err != nil {
+			return err
+		}
+		if err := writePair(filepath.Join(folder, caCertFileName), filepath.Join(folder, caKeyFileName), ca, caKey); err != nil {
+			return err
+		}
+	}
+	leaf, _, err := loadPair(filepath.Join(folder, certFileName), filepath.Join(folder, keyFileName))
+	if err == nil && isLeafValid(leaf, ca, hosts, now) {
+		return nil
+	}
+	tmpl := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: hosts[0]},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(leafValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+			continue
+		}
+		tmpl.DNSNames = append(tmpl.DNSNames, h)
+	}
+	leaf, key, err := createCert(tmpl, ca, caKey)
+	if err != nil {
+		return err
+	}
+	return writePair(filepath.Join(folder, certFileName), filepath.Join(folder, keyFileName), leaf, key)
+}
+
+// isLeafValid reports whether the certificate is signed by the CA, covers all the hosts
+// and does not expire soon.
+func isLeafValid(leaf, ca *x509.Certificate, hosts []string, now time.Time) bool {
+	if leaf.CheckSignatureFrom(ca) != nil || now.Add(leafRenewBefore).After(leaf.NotAfter) {
+		return false
+	}
+	for _, h := range hosts {
+		if leaf.VerifyHostname(h) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func createCert(tmpl, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl.SerialNumber = serial
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func loadPair(certFile, keyFile string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	certBlock, _ := pem.Decode(certPEM)
+	keyBlock, _ := pem.Decode(keyPEM)
+	if certBlock == nil || keyBlock == nil {
+		return nil, nil, errors.New("certs: invalid PEM data")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !key.PublicKey.Equal(cert.PublicKey) {
+		return nil, nil, fmt.Errorf("certs: private key does not match %s", certFile)
+	}
+	return cert, key, nil
+}
+
+// writePair writes the certificate and the private key, the key is written first
+// so that a watcher reloading on the certificate change always gets a matching pair.
+func writePair(certFile, keyFile string, cert *x509.Certificate, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0o644)
+}
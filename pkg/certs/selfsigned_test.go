@@ -0,0 +1,69 @@
+package certs_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/realHoangHai/awesome/pkg/certs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSelfSigned(t *testing.T) {
+	dir := t.TempDir()
+	leaf := func(hosts ...string) *x509.Certificate {
+		t.Helper()
+		m, err := certs.SelfSigned(dir, hosts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer m.Close()
+		cert, err := x509.ParseCertificate(m.Certificate().Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert
+	}
+	verify := func(cert *x509.Certificate, host string) {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(dir, "ca.pem"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, _ := pem.Decode(b)
+		ca, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		roots := x509.NewCertPool()
+		roots.AddCert(ca)
+		if _, err := cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: host}); err != nil {
+			t.Fatalf("verify %s, err: %v", host, err)
+		}
+	}
+
+	first := leaf()
+	for _, h := range []string{"localhost", "127.0.0.1", "::1"} {
+		verify(first, h)
+	}
+	if first.NotAfter.Before(time.Now().Add(60 * 24 * time.Hour)) {
+		t.Fatalf("got not_after=%v, want at least 60 days", first.NotAfter)
+	}
+
+	// cached certificate is reused.
+	if got := leaf(); got.SerialNumber.Cmp(first.SerialNumber) != 0 {
+		t.Fatalf("got serial=%v, want cached serial=%v", got.SerialNumber, first.SerialNumber)
+	}
+
+	// re-issued by the same CA if the hosts change.
+	second := leaf("example.local")
+	if second.SerialNumber.Cmp(first.SerialNumber) == 0 {
+		t.Fatal("got cached certificate, want re-issued for new hosts")
+	}
+	verify(second, "example.local")
+
+	// re-issued if the cached certificate is not signed by the CA.
+	writeCert(t, filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), 1, time.Now().Add(time.Hour))
+	verify(leaf("example.local"), "example.local")
+}
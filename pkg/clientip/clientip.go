@@ -0,0 +1,274 @@
+// Package clientip resolves the IP address of the clients behind trusted proxies,
+// from the X-Forwarded-For and Forwarded headers of HTTP requests and from the forwarded
+// metadata of gRPC requests.
+package clientip
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const (
+	// ForwardedMD is the metadata name of the client IP forwarded by the gRPC gateway.
+	ForwardedMD = "x-client-ip"
+
+	forwardedHeader     = "Forwarded"
+	xForwardedForHeader = "X-Forwarded-For"
+)
+
+type (
+	// Resolver resolves the IP address of the clients behind the trusted proxies. The address of
+	// the peer is the client IP unless it is a trusted proxy, the forwarded addresses are then walked
+	// from the closest proxy up to the first one not trusted. Peers of unix domain sockets are trusted.
+	Resolver struct {
+		trusted []*net.IPNet
+		// key signs the client IP forwarded by the gateway, it never leaves the process.
+		key []byte
+	}
+
+	clientIPKey struct{}
+)
+
+// NewResolver returns a new Resolver trusting the proxies of the given IPs or CIDRs, i.e... 10.0.0.0/8.
+func NewResolver(trusted ...string) (*Resolver, error) {
+	r := &Resolver{key: make([]byte, 32)}
+	if _, err := rand.Read(r.key); err != nil {
+		return nil, err
+	}
+	nets, err := ParseNetworks(trusted...)
+	if err != nil {
+		return nil, fmt.Errorf("clientip: invalid trusted proxy: %w", err)
+	}
+	r.trusted = nets
+	return r, nil
+}
+
+// ParseNetworks parses the given IPs or CIDRs, i.e... 10.0.0.0/8 or 192.0.2.1, an IP is a network of its own.
+func ParseNetworks(values ...string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP %q", v)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// NewContext returns a new context with the given client IP.
+func NewContext(ctx context.Context, ip net.IP) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// FromContext returns the client IP resolved for the request of the context.
+func FromContext(ctx context.Context) (net.IP, bool) {
+	ip, ok := ctx.Value(clientIPKey{}).(net.IP)
+	return ip, ok && ip != nil
+}
+
+// Trusted reports whether the given address is a trusted proxy,
+// a nil address is the peer of a unix domain socket.
+func (r *Resolver) Trusted(ip net.IP) bool {
+	if ip == nil {
+		return true
+	}
+	for _, n := range r.trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromRequest returns the client IP of the given HTTP request.
+func (r *Resolver) FromRequest(req *http.Request) net.IP {
+	ip := hostIP(req.RemoteAddr)
+	if !r.Trusted(ip) {
+		return ip
+	}
+	if v := req.Header.Values(forwardedHeader); len(v) > 0 {
+		return r.resolve(ip, forwardedFor(v))
+	}
+	return r.resolve(ip, xForwardedFor(req.Header.Values(xForwardedForHeader)))
+}
+
+// FromGRPC returns the client IP of the gRPC request of the given context,
+// the one forwarded by the gateway of the process takes precedence.
+func (r *Resolver) FromGRPC(ctx context.Context) net.IP {
+	md, _ := metadata.FromIncomingContext(ctx)
+	// clients may send forged values along with the one added by the gateway.
+	for _, v := range md.Get(ForwardedMD) {
+		if ip := r.verify(v); ip != nil {
+			return ip
+		}
+	}
+	var ip net.IP
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ip = hostIP(p.Addr.String())
+	}
+	if !r.Trusted(ip) {
+		return ip
+	}
+	if v := md.Get(strings.ToLower(forwardedHeader)); len(v) > 0 {
+		return r.resolve(ip, forwardedFor(v))
+	}
+	return r.resolve(ip, xForwardedFor(md.Get(strings.ToLower(xForwardedForHeader))))
+}
+
+// Metadata returns the signed client IP of the given request as metadata.
+// It is used as the gRPC gateway metadata annotator, see runtime.WithMetadata.
+func (r *Resolver) Metadata(ctx context.Context, req *http.Request) metadata.MD {
+	ip, ok := FromContext(req.Context())
+	if !ok {
+		ip = r.FromRequest(req)
+	}
+	if ip == nil {
+		return nil
+	}
+	return metadata.Pairs(ForwardedMD, r.sign(ip))
+}
+
+// HTTPInterceptor returns an HTTP interceptor attaching the client IP to the context of the requests.
+func (r *Resolver) HTTPInterceptor() func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if ip := r.FromRequest(req); ip != nil {
+				req = req.WithContext(NewContext(req.Context(), ip))
+			}
+			h.ServeHTTP(w, req)
+		})
+	}
+}
+
+// UnaryInterceptor returns a grpc.UnaryServerInterceptor attaching the client IP to the context.
+func (r *Resolver) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if ip := r.FromGRPC(ctx); ip != nil {
+			ctx = NewContext(ctx, ip)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor returns a grpc.StreamServerInterceptor attaching the client IP to the context.
+func (r *Resolver) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ip := r.FromGRPC(ss.Context())
+		if ip == nil {
+			return handler(srv, ss)
+		}
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = NewContext(ss.Context(), ip)
+		return handler(srv, wrapped)
+	}
+}
+
+// resolve walks the forwarded addresses from the closest proxy and returns the first one not trusted.
+// The last address walked is returned if a hop is unknown, i.e... obfuscated, or if all are trusted.
+func (r *Resolver) resolve(ip net.IP, hops []string) net.IP {
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseHop(hops[i])
+		if hop == nil {
+			return ip
+		}
+		ip = hop
+		if !r.Trusted(ip) {
+			return ip
+		}
+	}
+	return ip
+}
+
+func (r *Resolver) sign(ip net.IP) string {
+	v := ip.String()
+	return v + "." + base64.RawURLEncoding.EncodeToString(r.mac(v))
+}
+
+func (r *Resolver) verify(v string) net.IP {
+	i := strings.LastIndexByte(v, '.')
+	if i < 0 {
+		return nil
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(v[i+1:])
+	if err != nil || !hmac.Equal(sig, r.mac(v[:i])) {
+		return nil
+	}
+	return net.ParseIP(v[:i])
+}
+
+func (r *Resolver) mac(v string) []byte {
+	h := hmac.New(sha256.New, r.key)
+	h.Write([]byte(v))
+	return h.Sum(nil)
+}
+
+// forwardedFor returns the for parameters of the Forwarded headers, i.e... for=192.0.2.60;proto=http.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, v := range values {
+		for _, elem := range strings.Split(v, ",") {
+			hop := ""
+			for _, pair := range strings.Split(elem, ";") {
+				kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+				if len(kv) == 2 && strings.EqualFold(kv[0], "for") {
+					hop = strings.Trim(kv[1], `"`)
+				}
+			}
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
+
+// xForwardedFor returns the addresses of the X-Forwarded-For headers.
+func xForwardedFor(values []string) []string {
+	var hops []string
+	for _, v := range values {
+		for _, hop := range strings.Split(v, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	return hops
+}
+
+// parseHop parses a forwarded address, with an optional port, i.e... [2001:db8::1]:4711.
+func parseHop(v string) net.IP {
+	if ip := net.ParseIP(v); ip != nil {
+		return ip
+	}
+	return hostIP(v)
+}
+
+// hostIP returns the IP of a host:port address, nil if it is not an IP address, i.e... a unix socket.
+func hostIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = strings.Trim(addr, "[]")
+	}
+	return net.ParseIP(host)
+}
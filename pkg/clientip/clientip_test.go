@@ -0,0 +1,80 @@
+package clientip_test
+
+import (
+	"context"
+	"github.com/realHoangHai/awesome/pkg/clientip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFromRequest(t *testing.T) {
+	r, err := clientip.NewResolver("10.0.0.0/8", "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{name: "untrusted peer", remoteAddr: "203.0.113.1:1234", headers: map[string]string{"X-Forwarded-For": "198.51.100.1"}, want: "203.0.113.1"},
+		{name: "trusted peer without header", remoteAddr: "10.0.0.1:1234", want: "10.0.0.1"},
+		{name: "x-forwarded-for", remoteAddr: "10.0.0.1:1234", headers: map[string]string{"X-Forwarded-For": "198.51.100.9, 198.51.100.1, 192.0.2.1"}, want: "198.51.100.1"},
+		{name: "all trusted", remoteAddr: "10.0.0.1:1234", headers: map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"}, want: "10.0.0.3"},
+		{name: "forwarded", remoteAddr: "10.0.0.1:1234", headers: map[string]string{"Forwarded": `for=198.51.100.1;proto=http, for="[2001:db8::1]:4711"`, "X-Forwarded-For": "198.51.100.2"}, want: "2001:db8::1"},
+		{name: "obfuscated", remoteAddr: "10.0.0.1:1234", headers: map[string]string{"Forwarded": "for=198.51.100.1, for=_hidden, for=10.0.0.2"}, want: "10.0.0.2"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = c.remoteAddr
+			for k, v := range c.headers {
+				req.Header.Set(k, v)
+			}
+			if got := r.FromRequest(req); got.String() != c.want {
+				t.Errorf("got client_ip=%s, want client_ip=%s", got, c.want)
+			}
+		})
+	}
+	if _, err := clientip.NewResolver("10.0.0.0/33"); err == nil {
+		t.Error("got no error, want invalid trusted proxy")
+	}
+}
+
+func TestFromGRPC(t *testing.T) {
+	r, err := clientip.NewResolver("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newCtx := func(addr string, md metadata.MD) context.Context {
+		tcp, _ := net.ResolveTCPAddr("tcp", addr)
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: tcp})
+		return metadata.NewIncomingContext(ctx, md)
+	}
+
+	// forwarded by the gateway.
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "198.51.100.1:1234"
+	md := r.Metadata(context.Background(), req)
+	md.Append(clientip.ForwardedMD, "203.0.113.66.forged")
+	if got := r.FromGRPC(newCtx("127.0.0.1:5000", md)); got.String() != "198.51.100.1" {
+		t.Errorf("got client_ip=%s, want the one forwarded by the gateway", got)
+	}
+	// signed by another process.
+	other, _ := clientip.NewResolver()
+	if got := r.FromGRPC(newCtx("203.0.113.1:5000", other.Metadata(context.Background(), req))); got.String() != "203.0.113.1" {
+		t.Errorf("got client_ip=%s, want the peer", got)
+	}
+	// forwarded by a trusted proxy.
+	if got := r.FromGRPC(newCtx("10.0.0.1:5000", metadata.Pairs("x-forwarded-for", "198.51.100.2"))); got.String() != "198.51.100.2" {
+		t.Errorf("got client_ip=%s, want the forwarded one", got)
+	}
+	// untrusted peer.
+	if got := r.FromGRPC(newCtx("203.0.113.1:5000", metadata.Pairs("x-forwarded-for", "198.51.100.2"))); got.String() != "203.0.113.1" {
+		t.Errorf("got client_ip=%s, want the peer", got)
+	}
+}
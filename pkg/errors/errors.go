@@ -0,0 +1,178 @@
+// Package errors provides errors carrying a gRPC code, a reason and metadata as errdetails.ErrorInfo,
+// i.e... the ones generated by protoc-gen-go-errors from the ErrorReason enums annotated with errors.proto.
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	// Error is an error of a reason in a domain, i.e... USER_NOT_FOUND in user.service.v1,
+	// converted to a status of its code with the reason, the domain and the metadata as errdetails.ErrorInfo.
+	Error struct {
+		code     codes.Code
+		domain   string
+		reason   string
+		message  string
+		metadata map[string]string
+		cause    error
+	}
+)
+
+// New returns a new Error of the given code, domain, reason and message.
+func New(code codes.Code, domain, reason, message string) *Error {
+	return &Error{
+		code:    code,
+		domain:  domain,
+		reason:  reason,
+		message: message,
+	}
+}
+
+// Newf returns a new Error of the given code, domain, reason and formatted message.
+func Newf(code codes.Code, domain, reason, format string, args ...interface{}) *Error {
+	return New(code, domain, reason, fmt.Sprintf(format, args...))
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("error: code = %s domain = %s reason = %s message = %s metadata = %v",
+		e.code, e.domain, e.reason, e.message, e.metadata)
+	if e.cause != nil {
+		msg += fmt.Sprintf(" cause = %v", e.cause)
+	}
+	return msg
+}
+
+// Code returns the gRPC code of the error.
+func (e *Error) Code() codes.Code {
+	return e.code
+}
+
+// Domain returns the domain of the reason, i.e... the proto package of the ErrorReason enum.
+func (e *Error) Domain() string {
+	return e.domain
+}
+
+// Reason returns the reason of the error, i.e... the name of a value of the ErrorReason enum.
+func (e *Error) Reason() string {
+	return e.reason
+}
+
+// Message returns the message of the error.
+func (e *Error) Message() string {
+	return e.message
+}
+
+// Metadata returns the metadata of the error.
+func (e *Error) Metadata() map[string]string {
+	return e.metadata
+}
+
+// WithMetadata returns a copy of the error with the given metadata.
+func (e *Error) WithMetadata(md map[string]string) *Error {
+	err := *e
+	err.metadata = make(map[string]string, len(md))
+	for k, v := range md {
+		err.metadata[k] = v
+	}
+	return &err
+}
+
+// WithCause returns a copy of the error caused by the given error, the cause is not sent to the clients.
+func (e *Error) WithCause(cause error) *Error {
+	err := *e
+	err.cause = cause
+	return &err
+}
+
+// Unwrap returns the cause of the error.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether the target is an Error of the same reason in the same domain.
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if !errors.As(target, &t) {
+		return false
+	}
+	return t.reason == e.reason && t.domain == e.domain
+}
+
+// GRPCStatus returns the status of the error, with the reason, the domain and the metadata as errdetails.ErrorInfo.
+func (e *Error) GRPCStatus() *status.Status {
+	s := status.New(e.code, e.message)
+	if e.reason == "" {
+		return s
+	}
+	ds, err := s.WithDetails(&errdetails.ErrorInfo{
+		Reason:   e.reason,
+		Domain:   e.domain,
+		Metadata: e.metadata,
+	})
+	if err != nil {
+		return s
+	}
+	return ds
+}
+
+// FromError returns the Error of the given error, either wrapped in it or
+// converted from its status with errdetails.ErrorInfo, i.e... received by a gRPC client.
+func FromError(err error) (*Error, bool) {
+	if err == nil {
+		return nil, false
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+	for _, d := range s.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return New(s.Code(), info.GetDomain(), info.GetReason(), s.Message()).WithMetadata(info.GetMetadata()), true
+		}
+	}
+	return nil, false
+}
+
+// Code returns the gRPC code of the given error, codes.OK if it is nil, codes.Unknown if it has no status.
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if e, ok := FromError(err); ok {
+		return e.Code()
+	}
+	return status.Code(err)
+}
+
+// Reason returns the reason of the given error, empty if it has none.
+func Reason(err error) string {
+	if e, ok := FromError(err); ok {
+		return e.Reason()
+	}
+	return ""
+}
+
+// Is is an alias of the standard errors.Is.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As is an alias of the standard errors.As.
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
+
+// Unwrap is an alias of the standard errors.Unwrap.
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
@@ -0,0 +1,69 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/realHoangHai/awesome/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	notFound := errors.New(codes.NotFound, "user.service.v1", "USER_NOT_FOUND", "user not found")
+	err := fmt.Errorf("get user: %w", notFound.WithMetadata(map[string]string{"id": "1"}).WithCause(fmt.Errorf("no rows")))
+
+	if !errors.Is(err, notFound) {
+		t.Errorf("got is=false, want the wrapped error is %v", notFound)
+	}
+	if errors.Is(err, errors.New(codes.NotFound, "user.service.v1", "ADDRESS_NOT_FOUND", "")) {
+		t.Error("got is=true, want an error of another reason is not")
+	}
+	if got := errors.Code(err); got != codes.NotFound {
+		t.Errorf("got code=%v, want code=%v", got, codes.NotFound)
+	}
+
+	// the status sent to the clients.
+	s := status.Convert(errors.Convert(err))
+	if s.Code() != codes.NotFound || s.Message() != "user not found" {
+		t.Errorf("got status=%v, want not found", s)
+	}
+	if len(s.Details()) != 1 {
+		t.Fatalf("got details=%v, want the error info", s.Details())
+	}
+	info, ok := s.Details()[0].(*errdetails.ErrorInfo)
+	if !ok || info.Reason != "USER_NOT_FOUND" || info.Domain != "user.service.v1" || info.Metadata["id"] != "1" {
+		t.Errorf("got details=%v, want the error info", s.Details())
+	}
+
+	// received by the clients.
+	e, ok := errors.FromError(s.Err())
+	if !ok {
+		t.Fatal("got no error, want the error of the status")
+	}
+	if !errors.Is(e, notFound) || e.Metadata()["id"] != "1" {
+		t.Errorf("got error=%v, want %v", e, notFound)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		name string
+		give error
+		want codes.Code
+	}{
+		{name: "nil", give: nil, want: codes.OK},
+		{name: "status", give: fmt.Errorf("wrapped: %w", status.Error(codes.InvalidArgument, "invalid")), want: codes.InvalidArgument},
+		{name: "canceled", give: fmt.Errorf("query: %w", context.Canceled), want: codes.Canceled},
+		{name: "deadline exceeded", give: context.DeadlineExceeded, want: codes.DeadlineExceeded},
+		{name: "other", give: fmt.Errorf("failed"), want: codes.Unknown},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := status.Code(errors.Convert(c.give)); got != c.want {
+				t.Errorf("got code=%v, want code=%v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that converts the errors of the handlers
+// to their status, see Convert.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, Convert(err)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that converts the errors of the handlers
+// to their status, see Convert.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return Convert(handler(srv, ss))
+	}
+}
+
+// Convert returns the status error of the given error: the status of the Error or of the status error
+// wrapped in it, codes.Canceled and codes.DeadlineExceeded for the context errors, codes.Unknown otherwise.
+func Convert(err error) error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := FromError(err); ok {
+		return e.GRPCStatus().Err()
+	}
+	var se interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &se) {
+		return se.GRPCStatus().Err()
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
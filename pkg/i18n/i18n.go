@@ -0,0 +1,206 @@
+// Package i18n provides a catalog of the messages localized by locale, loaded from TOML or JSON files,
+// and interceptors attaching the localized messages of the errors as errdetails.LocalizedMessage
+// in the locale negotiated from the Accept-Language header or metadata.
+package i18n
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	// Catalog is a catalog of the messages by locale and key. The keys are case-insensitive and
+	// the messages are templates of named arguments, i.e... "{field} is required".
+	//
+	// The keys looked up by Localize are:
+	//	<domain>.<reason> for the errors with errdetails.ErrorInfo, i.e... user.service.v1.USER_NOT_FOUND.
+	//	validator.<tag> for the failures of the validator, i.e... validator.required.
+	//	codes.<code> for the other status errors, i.e... codes.NotFound.
+	Catalog struct {
+		mu       sync.RWMutex
+		fallback string
+		// messages by lowercase locale and lowercase key.
+		messages map[string]map[string]string
+		// locales as added, by lowercase locale.
+		locales map[string]string
+	}
+)
+
+// New returns a new empty catalog, falling back to the given locale if none of the accepted
+// languages is in the catalog, i.e... en. An empty fallback leaves the messages of the unknown
+// languages not localized.
+func New(fallback string) *Catalog {
+	return &Catalog{
+		fallback: fallback,
+		messages: make(map[string]map[string]string),
+		locales:  make(map[string]string),
+	}
+}
+
+// Load returns a new catalog of the files of the given directory, see LoadDir.
+func Load(fallback, dir string) (*Catalog, error) {
+	c := New(fallback)
+	if err := c.LoadDir(dir); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Add adds the messages by key of the given locale, replacing the existing ones of the same keys.
+func (c *Catalog) Add(locale string, messages map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l := strings.ToLower(locale)
+	if c.messages[l] == nil {
+		c.messages[l] = make(map[string]string, len(messages))
+		c.locales[l] = locale
+	}
+	for k, v := range messages {
+		c.messages[l][strings.ToLower(k)] = v
+	}
+}
+
+// LoadFile adds the messages of a TOML or JSON file named after its locale, i.e... vi.toml or en-US.json.
+// The nested tables are joined by dots, i.e... required of the table validator is validator.required.
+func (c *Catalog) LoadFile(path string) error {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("i18n: load %s, err: %w", path, err)
+	}
+	messages := make(map[string]string)
+	for _, k := range v.AllKeys() {
+		messages[k] = v.GetString(k)
+	}
+	name := filepath.Base(path)
+	c.Add(strings.TrimSuffix(name, filepath.Ext(name)), messages)
+	return nil
+}
+
+// LoadDir adds the messages of the TOML and JSON files of the given directory, see LoadFile.
+func (c *Catalog) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("i18n: load %s, err: %w", dir, err)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".toml", ".json":
+			if err := c.LoadFile(filepath.Join(dir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Locales returns the locales of the catalog, sorted.
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	locales := make([]string, 0, len(c.locales))
+	for _, l := range c.locales {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Match returns the locale of the catalog best matching the given Accept-Language, i.e... "vi-VN,vi;q=0.9,en;q=0.8".
+// A language matches its exact locale first then the locales of the same base language, i.e... vi-VN matches vi.
+// The fallback locale is returned if none matches.
+func (c *Catalog) Match(acceptLanguage string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, lang := range parseAcceptLanguage(acceptLanguage) {
+		if lang == "*" {
+			break
+		}
+		if l, ok := c.locales[lang]; ok {
+			return l
+		}
+		base := baseLanguage(lang)
+		if l, ok := c.locales[base]; ok {
+			return l
+		}
+		for k, l := range c.locales {
+			if baseLanguage(k) == base {
+				return l
+			}
+		}
+	}
+	return c.fallback
+}
+
+// Message returns the message of the key in the locale with the named arguments replaced,
+// i.e... {field} replaced by args["field"].
+func (c *Catalog) Message(locale, key string, args map[string]string) (string, bool) {
+	c.mu.RLock()
+	msg, ok := c.messages[strings.ToLower(locale)][strings.ToLower(key)]
+	c.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	if len(args) == 0 {
+		return msg, true
+	}
+	pairs := make([]string, 0, 2*len(args))
+	for k, v := range args {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(msg), true
+}
+
+// parseAcceptLanguage returns the lowercase languages of the given Accept-Language
+// in order of preference, the ones of quality 0 excluded.
+func parseAcceptLanguage(v string) []string {
+	type language struct {
+		tag string
+		q   float64
+	}
+	var languages []language
+	for _, part := range strings.Split(v, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if p := strings.TrimSpace(params); strings.HasPrefix(p, "q=") {
+			f, err := strconv.ParseFloat(p[2:], 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+		if q <= 0 {
+			continue
+		}
+		languages = append(languages, language{tag: tag, q: q})
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].q > languages[j].q
+	})
+	tags := make([]string, 0, len(languages))
+	for _, l := range languages {
+		tags = append(tags, l.tag)
+	}
+	return tags
+}
+
+// baseLanguage returns the base language of a lowercase tag, i.e... vi for vi-vn.
+func baseLanguage(tag string) string {
+	if i := strings.IndexAny(tag, "-_"); i > 0 {
+		return tag[:i]
+	}
+	return tag
+}
@@ -0,0 +1,265 @@
+package i18n_test
+
+import (
+	"context"
+	validate "github.com/go-playground/validator/v10"
+	"github.com/realHoangHai/awesome/pkg/errors"
+	"github.com/realHoangHai/awesome/pkg/i18n"
+	"github.com/realHoangHai/awesome/pkg/status"
+	"github.com/realHoangHai/awesome/pkg/utils/validator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	c := i18n.New("en")
+	c.Add("en", map[string]string{"k": "v"})
+	c.Add("vi", map[string]string{"k": "v"})
+	c.Add("pt-BR", map[string]string{"k": "v"})
+	cases := []struct {
+		accept string
+		want   string
+	}{
+		{accept: "", want: "en"},
+		{accept: "vi", want: "vi"},
+		{accept: "vi-VN,vi;q=0.9,en;q=0.8", want: "vi"},
+		{accept: "fr;q=0.9,VI;q=0.5", want: "vi"},
+		{accept: "en;q=0.1,vi;q=0.8", want: "vi"},
+		{accept: "vi;q=0,pt", want: "pt-BR"},
+		{accept: "fr,*", want: "en"},
+	}
+	for _, c2 := range cases {
+		if got := c.Match(c2.accept); got != c2.want {
+			t.Errorf("Match(%q) got locale=%s, want locale=%s", c2.accept, got, c2.want)
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"vi.toml":    "[validator]\nrequired = \"{field} là bắt buộc\"\n",
+		"en-US.json": `{"validator": {"required": "{field} is required"}}`,
+		"README.md":  "not a catalog",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := i18n.Load("en-US", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Locales(); len(got) != 2 || got[0] != "en-US" || got[1] != "vi" {
+		t.Errorf("got locales=%v, want [en-US vi]", got)
+	}
+	if msg, ok := c.Message("vi", "Validator.Required", map[string]string{"field": "Email"}); !ok || msg != "Email là bắt buộc" {
+		t.Errorf("got message=%q, ok=%v, want the Vietnamese one", msg, ok)
+	}
+	if _, err := i18n.Load("en", filepath.Join(dir, "unknown")); err == nil {
+		t.Error("got err=nil, want an error of the unknown directory")
+	}
+	// the catalogs of the repository.
+	repo, err := i18n.Load("en", "../../i18n")
+	if err != nil {
+		t.Fatalf("got err=%v, want the catalogs of the repository loaded", err)
+	}
+	if _, ok := repo.Message("vi", "user.service.v1.USER_NOT_FOUND", nil); !ok {
+		t.Error("got no message of user.service.v1.USER_NOT_FOUND, want the Vietnamese one")
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	c := i18n.New("")
+	c.Add("vi", map[string]string{
+		"user.service.v1.USER_NOT_FOUND": "Không tìm thấy người dùng {id}",
+		"codes.NotFound":                 "Không tìm thấy",
+		"validator.required":             "{field} là bắt buộc",
+		"validator.min":                  "{field} phải tối thiểu là {param}",
+		"codes.InvalidArgument":          "Yêu cầu không hợp lệ",
+	})
+	type user struct {
+		Name string `validate:"required"`
+	}
+	cases := []struct {
+		name      string
+		err       error
+		locale    string
+		wantCode  codes.Code
+		wantMsg   string
+		localized string
+	}{
+		{
+			name:      "reason",
+			err:       errors.New(codes.NotFound, "user.service.v1", "USER_NOT_FOUND", "user not found").WithMetadata(map[string]string{"id": "1"}),
+			locale:    "vi",
+			wantCode:  codes.NotFound,
+			wantMsg:   "user not found",
+			localized: "Không tìm thấy người dùng 1",
+		},
+		{
+			name:      "code",
+			err:       status.Error(codes.NotFound, "not found"),
+			locale:    "vi",
+			wantCode:  codes.NotFound,
+			wantMsg:   "not found",
+			localized: "Không tìm thấy",
+		},
+		{
+			name:      "validation",
+			err:       validate.New().Struct(user{}),
+			locale:    "vi",
+			wantCode:  codes.InvalidArgument,
+			localized: "Name là bắt buộc",
+		},
+		{
+			name: "field violations",
+			err: validator.FieldViolations{
+				{Field: "username", Description: "is required", Tag: "required"},
+				{Field: "address.post_code", Description: "must be at least 5", Tag: "min", Param: "5"},
+				{Field: "note", Description: "must match the pattern ^a$", Tag: "pattern", Param: "^a$"},
+			},
+			locale:    "vi",
+			wantCode:  codes.InvalidArgument,
+			localized: "username là bắt buộc; address.post_code phải tối thiểu là 5",
+		},
+		{
+			name:      "field violations without message",
+			err:       validator.FieldViolations{{Field: "note", Description: "must match the pattern ^a$", Tag: "pattern", Param: "^a$"}},
+			locale:    "vi",
+			wantCode:  codes.InvalidArgument,
+			localized: "Yêu cầu không hợp lệ",
+		},
+		{
+			name:     "no message",
+			err:      status.Error(codes.Internal, "internal"),
+			locale:   "vi",
+			wantCode: codes.Internal,
+			wantMsg:  "internal",
+		},
+		{
+			name:     "no locale",
+			err:      status.Error(codes.NotFound, "not found"),
+			wantCode: codes.NotFound,
+			wantMsg:  "not found",
+		},
+	}
+	for _, c2 := range cases {
+		t.Run(c2.name, func(t *testing.T) {
+			err := c.Localize(c2.err, c2.locale)
+			s := status.Convert(err)
+			if s.Code() != c2.wantCode {
+				t.Errorf("got code=%v, want code=%v", s.Code(), c2.wantCode)
+			}
+			if c2.wantMsg != "" && s.Message() != c2.wantMsg {
+				t.Errorf("got message=%q, want message=%q", s.Message(), c2.wantMsg)
+			}
+			m, ok := status.LocalizedMessage(err, c2.locale)
+			if c2.localized == "" {
+				if ok {
+					t.Errorf("got localized message=%v, want none", m)
+				}
+				return
+			}
+			if !ok || m.GetMessage() != c2.localized {
+				t.Errorf("got localized message=%v, want %q", m, c2.localized)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	c := i18n.New("en")
+	c.Add("en", map[string]string{"codes.NotFound": "Not found"})
+	c.Add("vi", map[string]string{"codes.NotFound": "Không tìm thấy"})
+	interceptor := c.UnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(i18n.AcceptLanguageMD, "vi-VN,en;q=0.5"))
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test"}, handler)
+	if m, ok := status.LocalizedMessage(err, ""); !ok || m.GetLocale() != "vi" || m.GetMessage() != "Không tìm thấy" {
+		t.Errorf("got localized message=%v, want the Vietnamese one", m)
+	}
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test"}, handler)
+	if m, ok := status.LocalizedMessage(err, ""); !ok || m.GetLocale() != "en" {
+		t.Errorf("got localized message=%v, want the default one", m)
+	}
+}
+
+func TestLocalizeDomainValidators(t *testing.T) {
+	c, err := i18n.Load("en", "../../i18n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type payment struct {
+		Phone      string `json:"phone" validate:"phone"`
+		Country    string `json:"country"`
+		PostalCode string `json:"postal_code" validate:"postal_code=Country"`
+		CardNumber string `json:"card_number" validate:"card_number"`
+		CardExpiry string `json:"card_expiry" validate:"card_expiry"`
+		Username   string `json:"username" validate:"username"`
+	}
+	req := &payment{
+		Phone:      "0901234567",
+		Country:    "VN",
+		PostalCode: "7000",
+		CardNumber: "4111111111111112",
+		CardExpiry: "01/20",
+		Username:   "1john",
+	}
+	// the validator is the innermost interceptor, after the catalog.
+	validating := validator.UnaryServerInterceptor()
+	localizing := c.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Payment/Pay"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	cases := []struct {
+		acceptLanguage string
+		locale         string
+		want           string
+	}{
+		{
+			acceptLanguage: "en-US",
+			locale:         "en",
+			want: "phone must be a phone number in the E.164 format, i.e. +84901234567.; " +
+				"postal_code must be a valid postal code.; " +
+				"card_number must be a valid card number.; " +
+				"card_expiry must be a card expiry date in the MM/YY format, not in the past.; " +
+				"username must be 3 to 32 letters, digits or single ., _, - starting with a letter.",
+		},
+		{
+			acceptLanguage: "vi-VN,en;q=0.5",
+			locale:         "vi",
+			want: "phone phải là số điện thoại theo định dạng E.164, ví dụ +84901234567.; " +
+				"postal_code phải là mã bưu chính hợp lệ.; " +
+				"card_number phải là số thẻ hợp lệ.; " +
+				"card_expiry phải là ngày hết hạn thẻ theo định dạng MM/YY, chưa quá hạn.; " +
+				"username phải gồm 3 đến 32 chữ cái, chữ số hoặc một dấu ., _, - và bắt đầu bằng chữ cái.",
+		},
+	}
+	for _, c2 := range cases {
+		t.Run(c2.locale, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(i18n.AcceptLanguageMD, c2.acceptLanguage))
+			_, err := localizing(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return validating(ctx, req, info, handler)
+			})
+			if code := status.Convert(err).Code(); code != codes.InvalidArgument {
+				t.Fatalf("got code=%v, want code=%v", code, codes.InvalidArgument)
+			}
+			if got := len(status.FieldViolations(err)); got != 5 {
+				t.Errorf("got %d field violations, want 5", got)
+			}
+			m, ok := status.LocalizedMessage(err, "")
+			if !ok || m.GetLocale() != c2.locale || m.GetMessage() != c2.want {
+				t.Errorf("got localized message=%v, want locale=%s, message=%q", m, c2.locale, c2.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	validate "github.com/go-playground/validator/v10"
+	"github.com/realHoangHai/awesome/pkg/status"
+	"github.com/realHoangHai/awesome/pkg/utils/validator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"strings"
+)
+
+// AcceptLanguageMD is the metadata name of the accepted languages, forwarded by the gateway from the Accept-Language header.
+const AcceptLanguageMD = "accept-language"
+
+// AcceptLanguage returns the accepted languages of the incoming metadata of the context.
+func AcceptLanguage(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	return strings.Join(md.Get(AcceptLanguageMD), ",")
+}
+
+// Localize returns the status error of the given error with its message in the locale attached as
+// errdetails.LocalizedMessage, the message of the status is kept as is for the logs.
+// The failures of the validator and the validator.FieldViolations are converted to codes.InvalidArgument,
+// localized by the messages of their tags.
+// The error is returned as is if it has no message in the locale or is already localized.
+func (c *Catalog) Localize(err error, locale string) error {
+	if err == nil {
+		return nil
+	}
+	var ve validate.ValidationErrors
+	if errors.As(err, &ve) {
+		return c.localizeValidation(ve, locale)
+	}
+	var fv validator.FieldViolations
+	if errors.As(err, &fv) {
+		return c.localizeViolations(fv, locale)
+	}
+	if locale == "" {
+		return err
+	}
+	s := status.Convert(err)
+	if s.Code() == codes.OK {
+		return err
+	}
+	if _, ok := status.LocalizedMessage(err, ""); ok {
+		return err
+	}
+	msg, ok := "", false
+	if info, found := status.ErrorInfo(err); found {
+		msg, ok = c.Message(locale, info.GetDomain()+"."+info.GetReason(), info.GetMetadata())
+	}
+	if !ok {
+		msg, ok = c.Message(locale, "codes."+s.Code().String(), nil)
+	}
+	if !ok {
+		return err
+	}
+	return withLocalizedMessage(s, locale, msg).Err()
+}
+
+// localizeValidation returns the status error of codes.InvalidArgument of the failures of the validator,
+// with the messages of the failed tags joined as errdetails.LocalizedMessage.
+func (c *Catalog) localizeValidation(ve validate.ValidationErrors, locale string) error {
+	s := status.New(codes.InvalidArgument, ve.Error())
+	if locale == "" {
+		return s.Err()
+	}
+	var messages []string
+	for _, fe := range ve {
+		msg, ok := c.Message(locale, "validator."+fe.Tag(), map[string]string{
+			"field":     fe.Field(),
+			"namespace": fe.Namespace(),
+			"tag":       fe.Tag(),
+			"param":     fe.Param(),
+			"value":     fmt.Sprint(fe.Value()),
+		})
+		if ok {
+			messages = append(messages, msg)
+		}
+	}
+	if len(messages) == 0 {
+		return s.Err()
+	}
+	return withLocalizedMessage(s, locale, strings.Join(messages, "; ")).Err()
+}
+
+// localizeViolations returns the status of the violations, with the messages of their tags joined
+// as errdetails.LocalizedMessage, the message of codes.InvalidArgument if none of them has a message.
+func (c *Catalog) localizeViolations(fv validator.FieldViolations, locale string) error {
+	s := fv.GRPCStatus()
+	if locale == "" {
+		return s.Err()
+	}
+	var messages []string
+	for _, v := range fv {
+		if v.Tag == "" {
+			continue
+		}
+		msg, ok := c.Message(locale, "validator."+v.Tag, map[string]string{
+			"field":     v.Field,
+			"namespace": v.Field,
+			"tag":       v.Tag,
+			"param":     v.Param,
+		})
+		if ok {
+			messages = append(messages, msg)
+		}
+	}
+	if len(messages) == 0 {
+		msg, ok := c.Message(locale, "codes."+codes.InvalidArgument.String(), nil)
+		if !ok {
+			return s.Err()
+		}
+		messages = append(messages, msg)
+	}
+	return withLocalizedMessage(s, locale, strings.Join(messages, "; ")).Err()
+}
+
+// withLocalizedMessage returns the status with the localized message, the status as is if it fails.
+func withLocalizedMessage(s *status.Status, locale, msg string) *status.Status {
+	ds, err := s.WithDetails(&errdetails.LocalizedMessage{Locale: locale, Message: msg})
+	if err != nil {
+		return s
+	}
+	return ds
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor localizing the errors of the handlers
+// in the locale matching the accepted languages of the metadata, see Localize.
+func (c *Catalog) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			err = c.Localize(err, c.Match(AcceptLanguage(ctx)))
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor localizing the errors of the handlers
+// in the locale matching the accepted languages of the metadata, see Localize.
+func (c *Catalog) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err != nil {
+			err = c.Localize(err, c.Match(AcceptLanguage(ss.Context())))
+		}
+		return err
+	}
+}
@@ -38,6 +38,21 @@ func DecodeOnly(secret []byte) auth.AuthenticatorFunc {
 	}
 }
 
+// ScopeAuthenticator returns an AuthenticatorFunc that validates the JWT the same as Authenticator
+// and requires the claims to contain all the given scopes, i.e... admin.
+func ScopeAuthenticator(secret []byte, scopes ...string) auth.AuthenticatorFunc {
+	return func(ctx context.Context) (context.Context, error) {
+		var claims Claims
+		if err := ParseFromMetadata(ctx, secret, &claims); err != nil {
+			return nil, err
+		}
+		if !claims.ContainScopes(scopes...) {
+			return nil, auth.ErrPermissionDenied
+		}
+		return NewContext(ctx, claims), nil
+	}
+}
+
 // ParseFromMetadata fetches the JWT from the authorization metadata
 // or in the grpcgateway-cookie located in the `Context`,
 // validates the JWT and extracts the Claims.
@@ -166,3 +166,26 @@ func TestSubjectEqual(t *testing.T) {
 		t.Errorf("got equal=true, want equal=false")
 	}
 }
+
+func TestScopeAuthenticator(t *testing.T) {
+	secret := []byte("very-secret-secret")
+	fn := ScopeAuthenticator(secret, "admin")
+	tt := []struct {
+		scope string
+		err   error
+	}{
+		{"foo admin", nil},
+		{"foo", auth.ErrPermissionDenied},
+		{"", auth.ErrPermissionDenied},
+	}
+	for _, tc := range tt {
+		token, err := Encode(Claims{Scope: tc.scope}, secret)
+		if err != nil {
+			t.Fatalf("Encode failed with: %v", err)
+		}
+		md := metadata.New(map[string]string{"authorization": token})
+		if _, err := fn(metadata.NewIncomingContext(context.Background(), md)); err != tc.err {
+			t.Errorf("ScopeAuthenticator(%q) = %v; want %v", tc.scope, err, tc.err)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -60,6 +61,12 @@ type (
 		Context(ctx context.Context) Logger
 	}
 
+	// LevelSetter is a logger whose level can be changed at runtime,
+	// the change applies to the loggers derived from it by Fields and Context.
+	LevelSetter interface {
+		SetLevel(level Level) error
+	}
+
 	// context key
 	contextKey string
 
@@ -111,6 +118,27 @@ const (
 	LevelTrace
 )
 
+// levelNames are the names of the levels, indexed by Level.
+var levelNames = []string{"panic", "fatal", "error", "warn", "info", "debug", "trace"}
+
+// ParseLevel returns the level of the given name, i.e... info, or of the given number, i.e... 4.
+func ParseLevel(name string) (Level, error) {
+	for i, n := range levelNames {
+		if strings.EqualFold(n, name) || strconv.Itoa(i) == name {
+			return Level(i), nil
+		}
+	}
+	return LevelDebug, fmt.Errorf("log: level not supported: %s", name)
+}
+
+// String returns the name of the level.
+func (l Level) String() string {
+	if l < LevelPanic || l > LevelTrace {
+		return fmt.Sprintf("level(%d)", int32(l))
+	}
+	return levelNames[l]
+}
+
 // Formats of log output.
 const (
 	FormatJSON Format = "json"
@@ -290,6 +318,15 @@ func (l *Logh) Init(opts ...Option) error {
 	return nil
 }
 
+// SetLevel implements LevelSetter.
+func (l *Logh) SetLevel(level Level) error {
+	if _, err := (Options{Level: level}).GetLevel(); err != nil {
+		return err
+	}
+	l.logger.Logger.SetLevel(logrus.Level(level))
+	return nil
+}
+
 func (l *Logh) Info(v ...interface{}) {
 	l.logger.Infoln(v...)
 }
@@ -2,6 +2,7 @@ package log
 
 import (
 	"github.com/google/uuid"
+	"github.com/realHoangHai/awesome/pkg/clientip"
 	"github.com/realHoangHai/awesome/pkg/utils/header"
 	"net/http"
 	"time"
@@ -17,11 +18,17 @@ func NewHTTPContextHandler(l Logger) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
 			// allow requests in microservices environment can be traced.
-			logger := l.Fields(
+			fields := []interface{}{
 				CorrelationID, getCorrelationID(r),
 				"path", r.URL.Path,
 				"remote_addr", r.RemoteAddr,
-				"method", r.Method)
+				"method", r.Method,
+			}
+			// resolved behind the trusted proxies, see clientip.Resolver.
+			if ip, ok := clientip.FromContext(ctx); ok {
+				fields = append(fields, "client_ip", ip.String())
+			}
+			logger := l.Fields(fields...)
 			r = r.WithContext(NewContext(ctx, logger))
 			mw := &responseWriter{
 				ResponseWriter: w,
@@ -0,0 +1,199 @@
+// Package proxyproto implements a listener accepting the PROXY protocol v1 and v2 headers
+// sent by TCP load balancers, so that the remote address of the connections is the one of the clients.
+// See https://www.haproxy.org/download/2.8/doc/proxy-protocol.txt.
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultReadHeaderTimeout is the default time allowed to read the header.
+	DefaultReadHeaderTimeout = 5 * time.Second
+
+	// maxV1Length is the maximum length of a v1 header, including the CRLF.
+	maxV1Length    = 107
+	v2HeaderLength = 16
+)
+
+var (
+	// ErrInvalidHeader reports that the PROXY protocol header is malformed.
+	ErrInvalidHeader = errors.New("proxyproto: invalid header")
+
+	v1Prefix    = []byte("PROXY ")
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+type (
+	// Listener accepts the connections of the wrapped listener, reading the PROXY protocol header
+	// of the trusted sources. The header is optional and read on the first Read or RemoteAddr call
+	// of the connections, so that slow clients do not block Accept.
+	Listener struct {
+		net.Listener
+		// Trusted reports whether the headers of the given source are accepted, all sources if nil.
+		// Sources of unix domain sockets are given as nil.
+		Trusted func(ip net.IP) bool
+		// ReadHeaderTimeout is the time allowed to read the header, default is DefaultReadHeaderTimeout.
+		ReadHeaderTimeout time.Duration
+	}
+
+	// Conn is a connection with the remote address given by its PROXY protocol header if any.
+	Conn struct {
+		net.Conn
+		r       *bufio.Reader
+		timeout time.Duration
+		once    sync.Once
+		src     net.Addr
+		err     error
+	}
+)
+
+// NewListener returns a Listener wrapping the given listener, accepting the headers of the trusted sources.
+func NewListener(l net.Listener, trusted func(ip net.IP) bool) *Listener {
+	return &Listener{Listener: l, Trusted: trusted}
+}
+
+// Accept implements net.Listener.
+func (l *Listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if l.Trusted != nil {
+		var ip net.IP
+		if addr, ok := c.RemoteAddr().(*net.TCPAddr); ok {
+			ip = addr.IP
+		}
+		if !l.Trusted(ip) {
+			return c, nil
+		}
+	}
+	timeout := l.ReadHeaderTimeout
+	if timeout <= 0 {
+		timeout = DefaultReadHeaderTimeout
+	}
+	return &Conn{Conn: c, r: bufio.NewReader(c), timeout: timeout}, nil
+}
+
+// Read implements net.Conn.
+func (c *Conn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(b)
+}
+
+// RemoteAddr implements net.Conn, it returns the source address of the header if any.
+func (c *Conn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.src != nil {
+		return c.src
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *Conn) readHeader() {
+	_ = c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+	defer func() {
+		_ = c.Conn.SetReadDeadline(time.Time{})
+	}()
+	b, err := c.r.Peek(1)
+	if err != nil {
+		// nothing sent, the error is returned by the next read if any.
+		return
+	}
+	switch b[0] {
+	case v1Prefix[0]:
+		if b, _ := c.r.Peek(len(v1Prefix)); bytes.Equal(b, v1Prefix) {
+			c.src, c.err = readV1(c.r)
+		}
+	case v2Signature[0]:
+		if b, _ := c.r.Peek(len(v2Signature)); bytes.Equal(b, v2Signature) {
+			c.src, c.err = readV2(c.r)
+		}
+	}
+}
+
+// readV1 reads a v1 header, i.e... PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n.
+func readV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) >= maxV1Length {
+			return nil, ErrInvalidHeader
+		}
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+		}
+		line = append(line, c)
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) < 2 {
+		return nil, ErrInvalidHeader
+	}
+	switch fields[1] {
+	case "UNKNOWN":
+		return nil, nil
+	case "TCP4", "TCP6":
+	default:
+		return nil, ErrInvalidHeader
+	}
+	if len(fields) != 6 {
+		return nil, ErrInvalidHeader
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if ip == nil || err != nil || (fields[1] == "TCP4") != (ip.To4() != nil) {
+		return nil, ErrInvalidHeader
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readV2 reads a binary v2 header.
+func readV2(r *bufio.Reader) (net.Addr, error) {
+	hdr := make([]byte, v2HeaderLength)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+	if hdr[12]>>4 != 2 {
+		return nil, ErrInvalidHeader
+	}
+	body := make([]byte, binary.BigEndian.Uint16(hdr[14:16]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+	switch hdr[12] & 0x0f {
+	case 0x0:
+		// LOCAL, i.e... health checks of the proxy.
+		return nil, nil
+	case 0x1:
+	default:
+		return nil, ErrInvalidHeader
+	}
+	// the family of the addresses, the transport protocol is not relevant.
+	switch hdr[13] >> 4 {
+	case 0x1:
+		if len(body) < 12 {
+			return nil, ErrInvalidHeader
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:4]), Port: int(binary.BigEndian.Uint16(body[8:10]))}, nil
+	case 0x2:
+		if len(body) < 36 {
+			return nil, ErrInvalidHeader
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:16]), Port: int(binary.BigEndian.Uint16(body[32:34]))}, nil
+	}
+	// unspecified or unix addresses.
+	return nil, nil
+}
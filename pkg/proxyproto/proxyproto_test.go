@@ -0,0 +1,105 @@
+package proxyproto_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/realHoangHai/awesome/pkg/proxyproto"
+	"io"
+	"net"
+	"testing"
+)
+
+func appendUint16(b []byte, v uint16) []byte {
+	out := make([]byte, 2)
+	binary.BigEndian.PutUint16(out, v)
+	return append(b, out...)
+}
+
+func v2Header(cmd byte, family byte, src net.IP, port uint16) []byte {
+	b := []byte("\r\n\r\n\x00\r\nQUIT\n")
+	b = append(b, 0x20|cmd, family<<4|0x1)
+	var addrs []byte
+	switch family {
+	case 0x1:
+		addrs = append(append(addrs, src.To4()...), net.IPv4(10, 0, 0, 1).To4()...)
+	case 0x2:
+		addrs = append(append(addrs, src.To16()...), net.IPv6loopback...)
+	}
+	if addrs != nil {
+		addrs = appendUint16(addrs, port)
+		addrs = appendUint16(addrs, 443)
+	}
+	// TLVs are skipped.
+	addrs = append(addrs, 0x04, 0x00, 0x01, 0xff)
+	b = appendUint16(b, uint16(len(addrs)))
+	return append(b, addrs...)
+}
+
+func TestListener(t *testing.T) {
+	cases := []struct {
+		name     string
+		header   []byte
+		trusted  bool
+		wantAddr string
+		wantData string
+		wantErr  bool
+	}{
+		{name: "v1 tcp4", header: []byte("PROXY TCP4 192.0.2.1 10.0.0.1 56324 443\r\n"), trusted: true, wantAddr: "192.0.2.1:56324", wantData: "hello"},
+		{name: "v1 tcp6", header: []byte("PROXY TCP6 2001:db8::1 ::1 4711 443\r\n"), trusted: true, wantAddr: "[2001:db8::1]:4711", wantData: "hello"},
+		{name: "v1 unknown", header: []byte("PROXY UNKNOWN\r\n"), trusted: true, wantData: "hello"},
+		{name: "v2 ipv4", header: v2Header(0x1, 0x1, net.IPv4(192, 0, 2, 2), 1234), trusted: true, wantAddr: "192.0.2.2:1234", wantData: "hello"},
+		{name: "v2 ipv6", header: v2Header(0x1, 0x2, net.ParseIP("2001:db8::2"), 1234), trusted: true, wantAddr: "[2001:db8::2]:1234", wantData: "hello"},
+		{name: "v2 local", header: v2Header(0x0, 0x0, nil, 0), trusted: true, wantData: "hello"},
+		{name: "no header", trusted: true, wantData: "hello"},
+		{name: "untrusted source", header: []byte("PROXY TCP4 192.0.2.1 10.0.0.1 56324 443\r\n"), wantData: "PROXY TCP4 192.0.2.1 10.0.0.1 56324 443\r\nhello"},
+		{name: "invalid header", header: []byte("PROXY TCP4 192.0.2.1\r\n"), trusted: true, wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer l.Close()
+			trusted := c.trusted
+			lis := proxyproto.NewListener(l, func(ip net.IP) bool {
+				return trusted && ip.IsLoopback()
+			})
+			go func() {
+				conn, err := net.Dial("tcp", l.Addr().String())
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				_, _ = conn.Write(append(c.header, "hello"...))
+			}()
+			conn, err := lis.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			addr := conn.RemoteAddr().String()
+			data, err := io.ReadAll(conn)
+			if c.wantErr {
+				if !errors.Is(err, proxyproto.ErrInvalidHeader) {
+					t.Errorf("got err=%v, want err=%v", err, proxyproto.ErrInvalidHeader)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.wantAddr != "" && addr != c.wantAddr {
+				t.Errorf("got remote_addr=%s, want remote_addr=%s", addr, c.wantAddr)
+			}
+			if c.wantAddr == "" {
+				if host, _, _ := net.SplitHostPort(addr); host != "127.0.0.1" {
+					t.Errorf("got remote_addr=%s, want the address of the connection", addr)
+				}
+			}
+			if string(data) != c.wantData {
+				t.Errorf("got data=%q, want data=%q", data, c.wantData)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package status
+
+import (
+	"github.com/realHoangHai/awesome/pkg/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"time"
+)
+
+type (
+	// Builder builds a status with the error details of google.rpc, i.e...
+	//
+	//	status.Build(codes.InvalidArgument, "invalid user").
+	//		FieldViolation("email", "invalid email").
+	//		Err()
+	//
+	// The violations of the same kind are collected in a single detail.
+	Builder struct {
+		code         Code
+		message      string
+		badRequest   *errdetails.BadRequest
+		quota        *errdetails.QuotaFailure
+		precondition *errdetails.PreconditionFailure
+		help         *errdetails.Help
+		details      []proto.Message
+	}
+)
+
+// Build returns a new Builder of a status of the given code and message.
+func Build(code Code, format string, args ...interface{}) *Builder {
+	return &Builder{code: code, message: New(code, format, args...).Message()}
+}
+
+// FieldViolation adds a field violation of errdetails.BadRequest, i.e... of an invalid argument.
+func (b *Builder) FieldViolation(field, description string) *Builder {
+	if b.badRequest == nil {
+		b.badRequest = &errdetails.BadRequest{}
+		b.details = append(b.details, b.badRequest)
+	}
+	b.badRequest.FieldViolations = append(b.badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: description,
+	})
+	return b
+}
+
+// ErrorInfo adds the errdetails.ErrorInfo of the reason of the error in the domain, i.e... the errors of pkg/errors.
+func (b *Builder) ErrorInfo(reason, domain string, metadata map[string]string) *Builder {
+	b.details = append(b.details, &errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   domain,
+		Metadata: metadata,
+	})
+	return b
+}
+
+// RetryInfo adds the errdetails.RetryInfo telling the clients to retry after the given delay.
+func (b *Builder) RetryInfo(delay time.Duration) *Builder {
+	b.details = append(b.details, &errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	return b
+}
+
+// QuotaViolation adds a violation of errdetails.QuotaFailure, i.e... of a resource exhausted.
+func (b *Builder) QuotaViolation(subject, description string) *Builder {
+	if b.quota == nil {
+		b.quota = &errdetails.QuotaFailure{}
+		b.details = append(b.details, b.quota)
+	}
+	b.quota.Violations = append(b.quota.Violations, &errdetails.QuotaFailure_Violation{
+		Subject:     subject,
+		Description: description,
+	})
+	return b
+}
+
+// PreconditionViolation adds a violation of errdetails.PreconditionFailure, i.e... of a failed precondition.
+func (b *Builder) PreconditionViolation(typ, subject, description string) *Builder {
+	if b.precondition == nil {
+		b.precondition = &errdetails.PreconditionFailure{}
+		b.details = append(b.details, b.precondition)
+	}
+	b.precondition.Violations = append(b.precondition.Violations, &errdetails.PreconditionFailure_Violation{
+		Type:        typ,
+		Subject:     subject,
+		Description: description,
+	})
+	return b
+}
+
+// ResourceInfo adds the errdetails.ResourceInfo of the resource being accessed, i.e... not found.
+func (b *Builder) ResourceInfo(resourceType, resourceName, owner, description string) *Builder {
+	b.details = append(b.details, &errdetails.ResourceInfo{
+		ResourceType: resourceType,
+		ResourceName: resourceName,
+		Owner:        owner,
+		Description:  description,
+	})
+	return b
+}
+
+// HelpLink adds a link of errdetails.Help, i.e... to the documentation of the error.
+func (b *Builder) HelpLink(description, url string) *Builder {
+	if b.help == nil {
+		b.help = &errdetails.Help{}
+		b.details = append(b.details, b.help)
+	}
+	b.help.Links = append(b.help.Links, &errdetails.Help_Link{
+		Description: description,
+		Url:         url,
+	})
+	return b
+}
+
+// LocalizedMessage adds the errdetails.LocalizedMessage of the error in the given locale, i.e... en-US.
+func (b *Builder) LocalizedMessage(locale, message string) *Builder {
+	b.details = append(b.details, &errdetails.LocalizedMessage{
+		Locale:  locale,
+		Message: message,
+	})
+	return b
+}
+
+// Status returns the status with the details, the details failing to be marshalled are logged and dropped.
+func (b *Builder) Status() *Status {
+	s := &spb.Status{Code: int32(b.code), Message: b.message}
+	for _, d := range b.details {
+		a, err := anypb.New(d)
+		if err != nil {
+			log.Errorf("errors: marshal detail %T, err: %v", d, err)
+			continue
+		}
+		s.Details = append(s.Details, a)
+	}
+	return status.FromProto(s)
+}
+
+// Err returns the error of the status with the details.
+func (b *Builder) Err() error {
+	return b.Status().Err()
+}
+
+// FieldViolations returns the field violations of the errdetails.BadRequest of the error.
+func FieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, d := range Convert(err).Details() {
+		if v, ok := d.(*errdetails.BadRequest); ok {
+			violations = append(violations, v.GetFieldViolations()...)
+		}
+	}
+	return violations
+}
+
+// ErrorInfo returns the errdetails.ErrorInfo of the error if any.
+func ErrorInfo(err error) (*errdetails.ErrorInfo, bool) {
+	v, ok := detail(err, func(d interface{}) bool {
+		_, ok := d.(*errdetails.ErrorInfo)
+		return ok
+	})
+	if !ok {
+		return nil, false
+	}
+	return v.(*errdetails.ErrorInfo), true
+}
+
+// RetryDelay returns the delay of the errdetails.RetryInfo of the error if any.
+func RetryDelay(err error) (time.Duration, bool) {
+	v, ok := detail(err, func(d interface{}) bool {
+		_, ok := d.(*errdetails.RetryInfo)
+		return ok
+	})
+	if !ok {
+		return 0, false
+	}
+	return v.(*errdetails.RetryInfo).GetRetryDelay().AsDuration(), true
+}
+
+// QuotaViolations returns the violations of the errdetails.QuotaFailure of the error.
+func QuotaViolations(err error) []*errdetails.QuotaFailure_Violation {
+	var violations []*errdetails.QuotaFailure_Violation
+	for _, d := range Convert(err).Details() {
+		if v, ok := d.(*errdetails.QuotaFailure); ok {
+			violations = append(violations, v.GetViolations()...)
+		}
+	}
+	return violations
+}
+
+// PreconditionViolations returns the violations of the errdetails.PreconditionFailure of the error.
+func PreconditionViolations(err error) []*errdetails.PreconditionFailure_Violation {
+	var violations []*errdetails.PreconditionFailure_Violation
+	for _, d := range Convert(err).Details() {
+		if v, ok := d.(*errdetails.PreconditionFailure); ok {
+			violations = append(violations, v.GetViolations()...)
+		}
+	}
+	return violations
+}
+
+// ResourceInfo returns the errdetails.ResourceInfo of the error if any.
+func ResourceInfo(err error) (*errdetails.ResourceInfo, bool) {
+	v, ok := detail(err, func(d interface{}) bool {
+		_, ok := d.(*errdetails.ResourceInfo)
+		return ok
+	})
+	if !ok {
+		return nil, false
+	}
+	return v.(*errdetails.ResourceInfo), true
+}
+
+// HelpLinks returns the links of the errdetails.Help of the error.
+func HelpLinks(err error) []*errdetails.Help_Link {
+	var links []*errdetails.Help_Link
+	for _, d := range Convert(err).Details() {
+		if v, ok := d.(*errdetails.Help); ok {
+			links = append(links, v.GetLinks()...)
+		}
+	}
+	return links
+}
+
+// LocalizedMessage returns the errdetails.LocalizedMessage of the error in the given locale,
+// the first one if the locale is empty.
+func LocalizedMessage(err error, locale string) (*errdetails.LocalizedMessage, bool) {
+	v, ok := detail(err, func(d interface{}) bool {
+		m, ok := d.(*errdetails.LocalizedMessage)
+		return ok && (locale == "" || m.GetLocale() == locale)
+	})
+	if !ok {
+		return nil, false
+	}
+	return v.(*errdetails.LocalizedMessage), true
+}
+
+// detail returns the first detail of the error matching the given function.
+func detail(err error, match func(interface{}) bool) (interface{}, bool) {
+	for _, d := range Convert(err).Details() {
+		if match(d) {
+			return d, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,60 @@
+package status_test
+
+import (
+	"github.com/realHoangHai/awesome/pkg/status"
+	"google.golang.org/grpc/codes"
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	err := status.Build(codes.InvalidArgument, "invalid user").
+		FieldViolation("email", "invalid email").
+		FieldViolation("name", "too long").
+		ErrorInfo("INVALID_USER", "user.service.v1", map[string]string{"id": "1"}).
+		RetryInfo(3*time.Second).
+		QuotaViolation("user:1", "daily limit").
+		PreconditionViolation("TOS", "user:1", "terms not accepted").
+		ResourceInfo("user", "users/1", "", "user of the request").
+		HelpLink("errors", "https://example.com/errors").
+		LocalizedMessage("fr-FR", "utilisateur invalide").
+		Err()
+
+	// round-trip through the JSON of the gateway.
+	s, perr := status.Parse(status.JSON(err))
+	if perr != nil {
+		t.Fatal(perr)
+	}
+	for name, err := range map[string]error{"status": err, "parsed": s.Err()} {
+		if status.Convert(err).Code() != codes.InvalidArgument || status.Convert(err).Message() != "invalid user" {
+			t.Errorf("%s: got status=%v, want invalid argument", name, status.Convert(err))
+		}
+		if v := status.FieldViolations(err); len(v) != 2 || v[0].Field != "email" || v[1].Field != "name" {
+			t.Errorf("%s: got field violations=%v, want email and name", name, v)
+		}
+		if v, ok := status.ErrorInfo(err); !ok || v.Reason != "INVALID_USER" || v.Metadata["id"] != "1" {
+			t.Errorf("%s: got error info=%v, want INVALID_USER", name, v)
+		}
+		if v, ok := status.RetryDelay(err); !ok || v != 3*time.Second {
+			t.Errorf("%s: got retry delay=%v, want 3s", name, v)
+		}
+		if v := status.QuotaViolations(err); len(v) != 1 || v[0].Subject != "user:1" {
+			t.Errorf("%s: got quota violations=%v, want user:1", name, v)
+		}
+		if v := status.PreconditionViolations(err); len(v) != 1 || v[0].Type != "TOS" {
+			t.Errorf("%s: got precondition violations=%v, want TOS", name, v)
+		}
+		if v, ok := status.ResourceInfo(err); !ok || v.ResourceName != "users/1" {
+			t.Errorf("%s: got resource info=%v, want users/1", name, v)
+		}
+		if v := status.HelpLinks(err); len(v) != 1 || v[0].Url != "https://example.com/errors" {
+			t.Errorf("%s: got help links=%v, want the errors link", name, v)
+		}
+		if v, ok := status.LocalizedMessage(err, "fr-FR"); !ok || v.Message != "utilisateur invalide" {
+			t.Errorf("%s: got localized message=%v, want the french one", name, v)
+		}
+		if _, ok := status.LocalizedMessage(err, "de-DE"); ok {
+			t.Errorf("%s: got a german localized message, want none", name)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/realHoangHai/awesome/pkg/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"net/http"
+)
+
+// ProblemContentType is the content type of the problem details, see https://datatracker.ietf.org/doc/html/rfc7807.
+const ProblemContentType = "application/problem+json"
+
+type (
+	// Problem is the problem details of a status, see https://datatracker.ietf.org/doc/html/rfc7807.
+	// The code, the error info and the field violations of the status are extension members.
+	Problem struct {
+		// Type is about:blank, or urn:problem:<domain>:<reason> of the error info of the status.
+		Type     string `json:"type"`
+		Title    string `json:"title"`
+		Status   int    `json:"status"`
+		Detail   string `json:"detail,omitempty"`
+		Instance string `json:"instance,omitempty"`
+
+		Code          Code              `json:"code"`
+		Reason        string            `json:"reason,omitempty"`
+		Domain        string            `json:"domain,omitempty"`
+		Metadata      map[string]string `json:"metadata,omitempty"`
+		InvalidParams []InvalidParam    `json:"invalid_params,omitempty"`
+	}
+
+	// InvalidParam is a field violation of a bad request.
+	InvalidParam struct {
+		Name   string `json:"name"`
+		Reason string `json:"reason"`
+	}
+)
+
+// NewProblem returns the problem details of the given error or status, occurred on the given instance,
+// i.e... URL.Path of the request.
+func NewProblem(v interface{}, instance string) Problem {
+	var s *Status
+	switch t := v.(type) {
+	case *Status:
+		s = t
+	case error:
+		s = Convert(t)
+	default:
+		s = New(codes.Unknown, "")
+	}
+	code := HTTPStatusFromCode(s.Code())
+	p := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(code),
+		Status:   code,
+		Detail:   s.Message(),
+		Instance: instance,
+		Code:     s.Code(),
+	}
+	for _, d := range s.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			p.Type = fmt.Sprintf("urn:problem:%s:%s", d.GetDomain(), d.GetReason())
+			p.Reason, p.Domain, p.Metadata = d.GetReason(), d.GetDomain(), d.GetMetadata()
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				p.InvalidParams = append(p.InvalidParams, InvalidParam{Name: v.GetField(), Reason: v.GetDescription()})
+			}
+		}
+	}
+	return p
+}
+
+// ProblemJSON return JSON encoded problem details of the given error or status, see NewProblem.
+func ProblemJSON(v interface{}, instance string) []byte {
+	b, err := json.Marshal(NewProblem(v, instance))
+	if err != nil {
+		log.Errorf("errors: marshall problem, err: %v", err)
+		return []byte(fmt.Sprintf(`{"type":"about:blank","title":"%s","status":%d}`, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError))
+	}
+	return b
+}
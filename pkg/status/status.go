@@ -2,12 +2,15 @@
 package status
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/realHoangHai/awesome/pkg/log"
 	spb "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"net/http"
 )
 
@@ -47,8 +50,13 @@ func Is(err error, code Code) bool {
 }
 
 // Convert is a convenience function which removes the need to handle the
-// boolean return value from FromError.
+// boolean return value from FromError. The status of an error wrapping a status error,
+// i.e... an errors.Error, is the one of the wrapped error.
 func Convert(err error) *Status {
+	var se interface{ GRPCStatus() *Status }
+	if errors.As(err, &se) {
+		return se.GRPCStatus()
+	}
 	return status.Convert(err)
 }
 
@@ -342,32 +350,48 @@ func HTTPStatusFromCode(code Code) int {
 	return http.StatusInternalServerError
 }
 
-// JSON return JSON encoded of the given error or status.
+// JSON return JSON encoded of the given error or status. The status is encoded like the gateway does,
+// with the details as JSON objects of their @type, so that they round-trip through Parse.
 func JSON(v interface{}) []byte {
+	fallback := []byte(fmt.Sprintf(`{"code":%d,"message":"%s"}`, codes.Internal, codes.Internal.String()))
 	marshal := func(target interface{}) []byte {
 		b, err := json.Marshal(target)
 		if err != nil {
 			log.Errorf("errors: marshall error, err: %v", err)
-			return []byte(fmt.Sprintf(`{"code":%d, "message":"%s"}`, codes.Internal, codes.Internal.String()))
+			return fallback
 		}
 		return b
 	}
+	marshalStatus := func(s *Status) []byte {
+		b, err := protojson.Marshal(s.Proto())
+		if err != nil {
+			log.Errorf("errors: marshall status, err: %v", err)
+			return fallback
+		}
+		// protojson output is unstable on purpose.
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, b); err != nil {
+			return b
+		}
+		return buf.Bytes()
+	}
 	if err, ok := v.(error); ok {
-		return marshal(Convert(err).Proto())
+		return marshalStatus(Convert(err))
 	}
 	if s, ok := v.(*Status); ok {
-		return marshal(s.Proto())
+		return marshalStatus(s)
 	}
 	if s, ok := v.(Status); ok {
-		return marshal(s.Proto())
+		return marshalStatus(&s)
 	}
 	return marshal(v)
 }
 
-// Parse try to parse the given data to a Status.
+// Parse try to parse the given data to a Status, i.e... encoded by JSON.
+// The types of the details must be linked in the binary, i.e... the ones of errdetails.
 func Parse(data []byte) (*Status, error) {
 	s := spb.Status{}
-	if err := json.Unmarshal(data, &s); err != nil {
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, &s); err != nil {
 		return nil, err
 	}
 	return status.FromProto(&s), nil
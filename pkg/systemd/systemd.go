@@ -0,0 +1,135 @@
+// Package systemd provides minimal support for systemd socket activation
+// and service readiness notification (sd_notify).
+// See: https://www.freedesktop.org/software/systemd/man/sd_listen_fds.html
+// and https://www.freedesktop.org/software/systemd/man/sd_notify.html
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// NotifyReady tells the service manager that service startup is finished.
+	NotifyReady = "READY=1"
+	// NotifyStopping tells the service manager that the service is beginning its shutdown.
+	NotifyStopping = "STOPPING=1"
+	// NotifyReloading tells the service manager that the service is reloading its configuration.
+	NotifyReloading = "RELOADING=1"
+
+	// listenFdsStart is the first file descriptor passed by systemd.
+	listenFdsStart = 3
+)
+
+var (
+	// ErrNoListener reports that no listener matches the requested name or index.
+	ErrNoListener = errors.New("systemd: no socket activated listener found")
+
+	once      sync.Once
+	mu        sync.Mutex
+	listeners []activated
+	loadErr   error
+)
+
+type activated struct {
+	name string
+	lis  net.Listener
+	used bool
+}
+
+// Notify sends the given state to the service manager.
+// It returns false without error if the service is not run under systemd (NOTIFY_SOCKET is not set).
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Listeners returns all listeners passed by systemd socket activation.
+// The file descriptors are loaded only once, subsequent calls return the same listeners.
+func Listeners() ([]net.Listener, error) {
+	load()
+	rs := make([]net.Listener, 0, len(listeners))
+	for _, l := range listeners {
+		rs = append(rs, l.lis)
+	}
+	return rs, loadErr
+}
+
+// Listener returns the socket activated listener matching the given key.
+// The key can be the name configured by FileDescriptorName= in the socket unit,
+// or the index of the listener. If the key is empty, the first unused listener is returned.
+// A listener can be acquired only once.
+func Listener(key string) (net.Listener, error) {
+	load()
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range listeners {
+		l := &listeners[i]
+		if l.used {
+			continue
+		}
+		if key == "" || key == l.name || key == strconv.Itoa(i) {
+			l.used = true
+			return l.lis, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrNoListener, key)
+}
+
+func load() {
+	once.Do(func() {
+		listeners, loadErr = loadListeners()
+	})
+}
+
+func loadListeners() ([]activated, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	// the variables must not be inherited by child processes.
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+
+	rs := make([]activated, 0, n)
+	for i := 0; i < n; i++ {
+		name := ""
+		if i < len(names) {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(listenFdsStart+i), name)
+		lis, err := net.FileListener(f)
+		// FileListener duplicates the file descriptor, the original one is no longer needed.
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("systemd: fd %d is not a listener, err: %w", listenFdsStart+i, err)
+		}
+		rs = append(rs, activated{name: name, lis: lis})
+	}
+	return rs, nil
+}
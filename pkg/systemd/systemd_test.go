@@ -0,0 +1,40 @@
+package systemd_test
+
+import (
+	"github.com/realHoangHai/awesome/pkg/systemd"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if ok, err := systemd.Notify(systemd.NotifyReady); ok || err != nil {
+		t.Fatalf("got ok=%v, err=%v, want ok=false, err=nil", ok, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", path)
+	if ok, err := systemd.Notify(systemd.NotifyReady); !ok || err != nil {
+		t.Fatalf("got ok=%v, err=%v, want ok=true, err=nil", ok, err)
+	}
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); got != systemd.NotifyReady {
+		t.Fatalf("got state=%s, want state=%s", got, systemd.NotifyReady)
+	}
+}
+
+func TestListenerNotActivated(t *testing.T) {
+	if _, err := systemd.Listener(""); err == nil {
+		t.Fatal("got listener, want error when not socket activated")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"github.com/realHoangHai/awesome/pkg/status"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 // Write write the status code and body on a http ResponseWriter
@@ -21,6 +23,72 @@ func WriteError(w http.ResponseWriter, code int, err error) {
 	Write(w, "application/json", code, status.JSON(err))
 }
 
+// Error write the error on a http ResponseWriter, with the HTTP status corresponding to the code
+// of its status, see status.HTTPStatusFromCode. The format is negotiated from the Accept header
+// of the request: problem details if application/problem+json is preferred to application/json,
+// see status.NewProblem, the JSON of the status otherwise, i.e... if the request is nil.
+// It is the error renderer shared by the gateway, the interceptors and the HTTP handlers.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	ErrorWithStatus(w, r, status.HTTPStatusFromCode(status.Convert(err).Code()), err)
+}
+
+// ErrorWithStatus is like Error with the given HTTP status.
+func ErrorWithStatus(w http.ResponseWriter, r *http.Request, code int, err error) {
+	if r == nil {
+		WriteError(w, code, err)
+		return
+	}
+	w.Header().Add("Vary", "Accept")
+	if !prefersProblem(r.Header.Values("Accept")) {
+		WriteError(w, code, err)
+		return
+	}
+	p := status.NewProblem(err, r.URL.Path)
+	p.Status, p.Title = code, http.StatusText(code)
+	b, merr := json.Marshal(p)
+	if merr != nil {
+		b = status.ProblemJSON(status.Internal("http: write problem, err: %v", merr), r.URL.Path)
+	}
+	Write(w, status.ProblemContentType, code, b)
+}
+
+// prefersProblem reports whether the problem details are preferred to the JSON of the status.
+// They must be accepted explicitly, i.e... not by */*.
+func prefersProblem(accept []string) bool {
+	problemQ, jsonQ, jsonSpecificity := 0.0, 0.0, -1
+	for _, v := range accept {
+		for _, part := range strings.Split(v, ",") {
+			name, q := part, 1.0
+			if i := strings.IndexByte(part, ';'); i >= 0 {
+				name = part[:i]
+				for _, param := range strings.Split(part[i+1:], ";") {
+					if param = strings.TrimSpace(param); strings.HasPrefix(param, "q=") {
+						if f, err := strconv.ParseFloat(param[2:], 64); err == nil {
+							q = f
+						}
+					}
+				}
+			}
+			// the most specific media range of application/json applies.
+			specificity := -1
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case status.ProblemContentType:
+				problemQ = q
+			case "application/json":
+				specificity = 2
+			case "application/*":
+				specificity = 1
+			case "*/*":
+				specificity = 0
+			}
+			if specificity > jsonSpecificity {
+				jsonQ, jsonSpecificity = q, specificity
+			}
+		}
+	}
+	return problemQ > 0 && problemQ >= jsonQ
+}
+
 // WriteJSON write status and JSON data to http ResponseWriter.
 func WriteJSON(w http.ResponseWriter, code int, data interface{}) {
 	if err, ok := data.(error); ok {
@@ -2,13 +2,16 @@ package response_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/realHoangHai/awesome/pkg/status"
 	"github.com/realHoangHai/awesome/pkg/utils/response"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -104,3 +107,87 @@ func TestWriteError_NormalError(t *testing.T) {
 		t.Errorf("got err: %s, want err contains %s", rc.Body.String(), wantCode)
 	}
 }
+
+func TestError(t *testing.T) {
+	cases := []struct {
+		give       error
+		wantStatus int
+	}{
+		{give: status.NotFound("user not found"), wantStatus: http.StatusNotFound},
+		{give: status.Unauthenticated("invalid token"), wantStatus: http.StatusUnauthorized},
+		{give: status.ResourceExhausted("rate limit exceeded"), wantStatus: http.StatusTooManyRequests},
+		{give: errors.New("internal error"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		rc := httptest.NewRecorder()
+		response.Error(rc, httptest.NewRequest(http.MethodGet, "/", nil), c.give)
+		if state := rc.Result().StatusCode; state != c.wantStatus {
+			t.Errorf("got status: %v, want status: %v", state, c.wantStatus)
+		}
+		got, err := status.Parse(rc.Body.Bytes())
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if got.Message() != status.Convert(c.give).Message() {
+			t.Errorf("got message: %s, want message: %s", got.Message(), status.Convert(c.give).Message())
+		}
+	}
+}
+
+func TestError_Problem(t *testing.T) {
+	cases := []struct {
+		accept string
+		want   string
+	}{
+		{accept: "", want: "application/json"},
+		{accept: "*/*", want: "application/json"},
+		{accept: "application/json", want: "application/json"},
+		{accept: "application/problem+json", want: status.ProblemContentType},
+		{accept: "application/problem+json, application/json", want: status.ProblemContentType},
+		{accept: "application/json, application/problem+json;q=0.5", want: "application/json"},
+		{accept: "application/problem+json;q=0.9, */*;q=0.1", want: status.ProblemContentType},
+	}
+	for _, c := range cases {
+		rc := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.Header.Set("Accept", c.accept)
+		response.Error(rc, req, status.NotFound("user not found"))
+		if ct := rc.Header().Get("Content-Type"); ct != c.want {
+			t.Errorf("accept %q: got content type: %v, want content type: %v", c.accept, ct, c.want)
+		}
+	}
+
+	s, err := status.New(codes.InvalidArgument, "invalid user").WithDetails(
+		&errdetails.ErrorInfo{Reason: "INVALID_USER", Domain: "awesome.dev", Metadata: map[string]string{"id": "1"}},
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "email", Description: "invalid email"}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	req.Header.Set("Accept", status.ProblemContentType)
+	response.Error(rc, req, s.Err())
+	if state := rc.Result().StatusCode; state != http.StatusBadRequest {
+		t.Errorf("got status: %v, want status: %v", state, http.StatusBadRequest)
+	}
+	var got status.Problem
+	if err := json.Unmarshal(rc.Body.Bytes(), &got); err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	want := status.Problem{
+		Type:          "urn:problem:awesome.dev:INVALID_USER",
+		Title:         "Bad Request",
+		Status:        http.StatusBadRequest,
+		Detail:        "invalid user",
+		Instance:      "/users",
+		Code:          codes.InvalidArgument,
+		Reason:        "INVALID_USER",
+		Domain:        "awesome.dev",
+		Metadata:      map[string]string{"id": "1"},
+		InvalidParams: []status.InvalidParam{{Name: "email", Reason: "invalid email"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got problem: %+v, want problem: %+v", got, want)
+	}
+}
@@ -0,0 +1,156 @@
+package validator
+
+import (
+	validate "github.com/go-playground/validator/v10"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// The tags of the domain validators registered by New.
+const (
+	// PhoneTag validates a phone number in the E.164 format, i.e... +84901234567.
+	PhoneTag = "phone"
+	// PostalCodeTag validates a postal code of the country of the param, either an ISO 3166-1 alpha-2 code
+	// or the name of a sibling field holding it, i.e... postal_code=VN or postal_code=Country.
+	PostalCodeTag = "postal_code"
+	// CardNumberTag validates a card number by its Luhn checksum, spaces and dashes allowed.
+	CardNumberTag = "card_number"
+	// CardExpiryTag validates a card expiry date in the MM/YY format, not in the past.
+	CardExpiryTag = "card_expiry"
+	// UsernameTag validates a username of the policy of IsUsername.
+	UsernameTag = "username"
+)
+
+const (
+	// UsernamePattern is the pattern of the usernames of IsUsername, along with 3 to 32 characters.
+	UsernamePattern = `^[a-zA-Z](?:[._-]?[a-zA-Z0-9])*$`
+)
+
+var (
+	e164Pattern       = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+	countryPattern    = regexp.MustCompile(`^[A-Z]{2}$`)
+	cardExpiryPattern = regexp.MustCompile(`^(0[1-9]|1[0-2])/([0-9]{2})$`)
+	usernamePattern   = regexp.MustCompile(UsernamePattern)
+	// postalCodePattern is the pattern of the postal codes of the countries without one in postalCodePatterns.
+	postalCodePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 -]{1,8}[A-Za-z0-9]$`)
+
+	// postalCodePatterns are the patterns of the postal codes by ISO 3166-1 alpha-2 code.
+	postalCodePatterns = map[string]*regexp.Regexp{
+		"AU": regexp.MustCompile(`^[0-9]{4}$`),
+		"BR": regexp.MustCompile(`^[0-9]{5}-?[0-9]{3}$`),
+		"CA": regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY][0-9][ABCEGHJ-NPRSTV-Z] ?[0-9][ABCEGHJ-NPRSTV-Z][0-9]$`),
+		"CN": regexp.MustCompile(`^[0-9]{6}$`),
+		"DE": regexp.MustCompile(`^[0-9]{5}$`),
+		"FR": regexp.MustCompile(`^[0-9]{5}$`),
+		"GB": regexp.MustCompile(`^[A-Z]{1,2}[0-9][A-Z0-9]? ?[0-9][A-Z]{2}$`),
+		"IN": regexp.MustCompile(`^[1-9][0-9]{5}$`),
+		"JP": regexp.MustCompile(`^[0-9]{3}-?[0-9]{4}$`),
+		"KR": regexp.MustCompile(`^[0-9]{5}$`),
+		"NL": regexp.MustCompile(`^[1-9][0-9]{3} ?[A-Z]{2}$`),
+		"SG": regexp.MustCompile(`^[0-9]{6}$`),
+		"TH": regexp.MustCompile(`^[0-9]{5}$`),
+		"US": regexp.MustCompile(`^[0-9]{5}(-[0-9]{4})?$`),
+		"VN": regexp.MustCompile(`^[0-9]{6}$`),
+	}
+)
+
+// registerDomainValidators registers the domain validators to the given validate.
+func registerDomainValidators(v *validate.Validate) {
+	_ = v.RegisterValidation(PhoneTag, func(fl validate.FieldLevel) bool {
+		return IsPhone(fl.Field().String())
+	})
+	_ = v.RegisterValidation(PostalCodeTag, func(fl validate.FieldLevel) bool {
+		return IsPostalCode(postalCodeCountry(fl), fl.Field().String())
+	})
+	_ = v.RegisterValidation(CardNumberTag, func(fl validate.FieldLevel) bool {
+		return IsCardNumber(fl.Field().String())
+	})
+	_ = v.RegisterValidation(CardExpiryTag, func(fl validate.FieldLevel) bool {
+		return IsCardExpiry(fl.Field().String(), time.Now())
+	})
+	_ = v.RegisterValidation(UsernameTag, func(fl validate.FieldLevel) bool {
+		return IsUsername(fl.Field().String())
+	})
+}
+
+// postalCodeCountry returns the country of the param of the postal code, empty if unknown.
+func postalCodeCountry(fl validate.FieldLevel) string {
+	param := fl.Param()
+	if param == "" || countryPattern.MatchString(param) {
+		return param
+	}
+	parent := reflect.Indirect(fl.Parent())
+	if parent.Kind() != reflect.Struct {
+		return ""
+	}
+	f := reflect.Indirect(parent.FieldByName(param))
+	if f.Kind() != reflect.String {
+		return ""
+	}
+	return strings.ToUpper(f.String())
+}
+
+// IsPhone reports whether the given string is a phone number in the E.164 format, i.e... +84901234567.
+func IsPhone(s string) bool {
+	return e164Pattern.MatchString(s)
+}
+
+// IsPostalCode reports whether the given string is a postal code of the country of the given
+// ISO 3166-1 alpha-2 code, of any country if it is unknown.
+func IsPostalCode(country, s string) bool {
+	if p, ok := postalCodePatterns[strings.ToUpper(country)]; ok {
+		return p.MatchString(strings.ToUpper(s))
+	}
+	return postalCodePattern.MatchString(s)
+}
+
+// IsCardNumber reports whether the given string is a card number of 12 to 19 digits with a valid Luhn checksum,
+// the spaces and the dashes between the digits are ignored.
+func IsCardNumber(s string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(s)
+	if len(digits) < 12 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// IsCardExpiry reports whether the given string is a card expiry date in the MM/YY format
+// not in the past at the given time, the cards expire at the end of their month.
+func IsCardExpiry(s string, now time.Time) bool {
+	m := cardExpiryPattern.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	month := int(m[1][0]-'0')*10 + int(m[1][1]-'0')
+	year := 2000 + int(m[2][0]-'0')*10 + int(m[2][1]-'0')
+	// the first day of the month after the expiry.
+	end := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, now.Location())
+	return now.Before(end)
+}
+
+// IsUsername reports whether the given string is a username of 3 to 32 characters of ASCII letters, digits
+// and the separators ., _ and -, starting with a letter, neither ending with a separator nor having two in a row.
+// The usernames of the protos are checked by the same pattern and lengths, see UsernamePattern.
+func IsUsername(s string) bool {
+	if n := utf8.RuneCountInString(s); n < 3 || n > 32 {
+		return false
+	}
+	return usernamePattern.MatchString(s)
+}
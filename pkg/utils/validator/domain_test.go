@@ -0,0 +1,86 @@
+package validator_test
+
+import (
+	v1 "github.com/realHoangHai/awesome/api/user/v1"
+	"github.com/realHoangHai/awesome/pkg/utils/validator"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDomainValidators(t *testing.T) {
+	cases := []struct {
+		name  string
+		value interface{}
+		tag   string
+		valid bool
+	}{
+		{name: "phone", value: "+84901234567", tag: "phone", valid: true},
+		{name: "phone without plus", value: "84901234567", tag: "phone"},
+		{name: "phone too long", value: "+8490123456789012", tag: "phone"},
+		{name: "postal code of VN", value: "700000", tag: "postal_code=VN", valid: true},
+		{name: "invalid postal code of VN", value: "7000", tag: "postal_code=VN"},
+		{name: "postal code of US", value: "94105-1234", tag: "postal_code=US", valid: true},
+		{name: "postal code of GB", value: "sw1a 1aa", tag: "postal_code=GB", valid: true},
+		{name: "postal code of an unknown country", value: "AB-123", tag: "postal_code=ZZ", valid: true},
+		{name: "postal code of any country", value: "!", tag: "postal_code"},
+		{name: "card number", value: "4111 1111 1111 1111", tag: "card_number", valid: true},
+		{name: "card number of invalid checksum", value: "4111111111111112", tag: "card_number"},
+		{name: "card number too short", value: "42", tag: "card_number"},
+		{name: "card expiry", value: "12/99", tag: "card_expiry", valid: true},
+		{name: "expired card", value: "01/20", tag: "card_expiry"},
+		{name: "invalid card expiry", value: "13/30", tag: "card_expiry"},
+		{name: "username", value: "john.doe_1", tag: "username", valid: true},
+		{name: "username starting with a digit", value: "1john", tag: "username"},
+		{name: "username with separators in a row", value: "john..doe", tag: "username"},
+		{name: "username ending with a separator", value: "john_", tag: "username"},
+		{name: "username too short", value: "jo", tag: "username"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validator.Var(c.value, c.tag)
+			if (err == nil) != c.valid {
+				t.Errorf("got err=%v, want valid=%v", err, c.valid)
+			}
+		})
+	}
+}
+
+func TestPostalCodeOfField(t *testing.T) {
+	type address struct {
+		Country  string
+		PostCode string `validate:"postal_code=Country"`
+	}
+	if err := validator.Validate(address{Country: "us", PostCode: "94105"}); err != nil {
+		t.Errorf("got err=%v, want the postal code of US valid", err)
+	}
+	if err := validator.Validate(address{Country: "VN", PostCode: "94105"}); err == nil {
+		t.Error("got err=nil, want the postal code of US invalid in VN")
+	}
+}
+
+func TestIsCardExpiry(t *testing.T) {
+	now := time.Date(2024, time.March, 31, 23, 0, 0, 0, time.UTC)
+	cases := map[string]bool{
+		"03/24": true,
+		"02/24": false,
+		"12/23": false,
+		"01/25": true,
+		"3/24":  false,
+	}
+	for v, want := range cases {
+		if got := validator.IsCardExpiry(v, now); got != want {
+			t.Errorf("IsCardExpiry(%s) got %v, want %v", v, got, want)
+		}
+	}
+}
+
+func TestUsernamePolicy(t *testing.T) {
+	// the usernames of the protos follow the policy of IsUsername.
+	for _, name := range []string{"john.doe_1", "john-doe", "1john", "john..doe", "john_", "_john", "jo", "j.o", strings.Repeat("a", 33)} {
+		err := (&v1.CreateUserReq{Username: name, Password: "password"}).Validate()
+		if got, want := err == nil, validator.IsUsername(name); got != want {
+			t.Errorf("username %q: got err=%v, want valid=%v", name, err, want)
+		}
+	}
+}
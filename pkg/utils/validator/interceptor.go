@@ -0,0 +1,65 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	validate "github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"reflect"
+)
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor rejecting the invalid requests
+// with codes.InvalidArgument and their field violations as errdetails.BadRequest, see ValidateRequest.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := ValidateRequest(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor rejecting the invalid messages received
+// with codes.InvalidArgument and their field violations as errdetails.BadRequest, see ValidateRequest.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: ss})
+	}
+}
+
+// ValidateRequest validates the request using its Validate method, i.e... generated by protoc-gen-go-validate
+// from the proto annotations, or using the validate tags of its struct with Validate otherwise.
+// The violations are returned as FieldViolations.
+func ValidateRequest(req interface{}) error {
+	if v, ok := req.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	v := reflect.ValueOf(req)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	err := Validate(req)
+	var ive *validate.InvalidValidationError
+	if errors.As(err, &ive) {
+		return nil
+	}
+	return Convert(req, err)
+}
+
+// validatingStream validates the messages received by a stream.
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return ValidateRequest(m)
+}
@@ -0,0 +1,156 @@
+package validator_test
+
+import (
+	"context"
+	"fmt"
+	v1 "github.com/realHoangHai/awesome/api/user/v1"
+	"github.com/realHoangHai/awesome/pkg/status"
+	"github.com/realHoangHai/awesome/pkg/utils/validator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"testing"
+)
+
+type (
+	jsonAddress struct {
+		Country  string `json:"country"`
+		PostCode string `json:"post_code,omitempty" validate:"postal_code=Country"`
+		Note     string `json:"-" validate:"max=3"`
+	}
+	jsonRequest struct {
+		Phone     string         `json:"phone" validate:"phone"`
+		Address   jsonAddress    `json:"address"`
+		Addresses []*jsonAddress `json:"addresses" validate:"dive"`
+	}
+)
+
+func TestValidateRequest(t *testing.T) {
+	type address struct {
+		PostCode string `validate:"required"`
+	}
+	type request struct {
+		Email   string `validate:"email"`
+		Address address
+	}
+	cases := []struct {
+		name   string
+		req    interface{}
+		fields []string
+	}{
+		{
+			name: "valid",
+			req:  &v1.CreateUserReq{Username: "john.doe", Password: "secret-password"},
+		},
+		{
+			name:   "required",
+			req:    &v1.CreateUserReq{},
+			fields: []string{"username", "password"},
+		},
+		{
+			name:   "length and pattern",
+			req:    &v1.CreateUserReq{Username: "j!", Password: "secret"},
+			fields: []string{"username", "username", "password"},
+		},
+		{
+			name:   "range",
+			req:    &v1.GetUserReq{Id: -1},
+			fields: []string{"id"},
+		},
+		{
+			name:   "validate tags",
+			req:    &request{Email: "john"},
+			fields: []string{"Email", "Address.PostCode"},
+		},
+		{
+			name:   "json names",
+			req:    &jsonRequest{Phone: "0901234567", Address: jsonAddress{Country: "VN", PostCode: "1000"}},
+			fields: []string{"phone", "address.post_code"},
+		},
+		{
+			name: "not a struct",
+			req:  "request",
+		},
+		{
+			name: "nil",
+			req:  (*request)(nil),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validator.ValidateRequest(c.req)
+			if len(c.fields) == 0 {
+				if err != nil {
+					t.Errorf("got err=%v, want no error", err)
+				}
+				return
+			}
+			if status.Convert(err).Code() != codes.InvalidArgument {
+				t.Errorf("got code=%v, want code=%v", status.Convert(err).Code(), codes.InvalidArgument)
+			}
+			violations := status.FieldViolations(err)
+			if len(violations) != len(c.fields) {
+				t.Fatalf("got violations=%v, want the ones of %v", violations, c.fields)
+			}
+			for i, v := range violations {
+				if v.GetField() != c.fields[i] {
+					t.Errorf("got field=%s, want field=%s", v.GetField(), c.fields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	req := &jsonRequest{Phone: "+84901234567", Address: jsonAddress{PostCode: "700000", Note: "long note"}}
+	err := validator.Convert(req, validator.Validate(req))
+	violations, ok := err.(validator.FieldViolations)
+	if !ok || len(violations) != 1 {
+		t.Fatalf("got err=%v, want a field violation", err)
+	}
+	// the fields ignored by json keep their Go names.
+	if v := violations[0]; v.Field != "Address.Note" || v.Description != "must be at most 3" || v.Tag != "max" || v.Param != "3" {
+		t.Errorf("got violation=%v, want the one of Address.Note", v)
+	}
+	// the indexes of the items are kept.
+	req = &jsonRequest{Phone: "+84901234567", Address: jsonAddress{Country: "VN", PostCode: "700000"}, Addresses: []*jsonAddress{{Country: "VN", PostCode: "700000"}, {Country: "VN", PostCode: "1000"}}}
+	err = validator.Convert(req, validator.Validate(req))
+	if violations, _ := err.(validator.FieldViolations); len(violations) != 1 || violations[0].Field != "addresses[1].post_code" {
+		t.Errorf("got err=%v, want the violation of addresses[1].post_code", err)
+	}
+	if err := validator.Convert(req, context.Canceled); err != context.Canceled {
+		t.Errorf("got err=%v, want the error as is", err)
+	}
+}
+
+func TestNested(t *testing.T) {
+	err := validator.FieldViolations{{Field: "postCode", Description: "is required", Tag: "required"}}
+	got := validator.Nested("addresses[1]", err)
+	if len(got) != 1 || got[0].Field != "addresses[1].postCode" || got[0].Tag != "required" {
+		t.Errorf("got violations=%v, want addresses[1].postCode", got)
+	}
+	// the violations wrapped by the Validate methods are nested too.
+	if got := validator.Nested("address", fmt.Errorf("wrapped: %w", err)); len(got) != 1 || got[0].Field != "address.postCode" {
+		t.Errorf("got violations=%v, want address.postCode", got)
+	}
+	if got := validator.Nested("address", nil); got != nil {
+		t.Errorf("got violations=%v, want none", got)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := validator.UnaryServerInterceptor()
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	}
+	_, err := interceptor(context.Background(), &v1.CreateUserReq{}, &grpc.UnaryServerInfo{FullMethod: "/test"}, handler)
+	if called || status.Convert(err).Code() != codes.InvalidArgument {
+		t.Errorf("got called=%v, err=%v, want the invalid request rejected", called, err)
+	}
+	_, err = interceptor(context.Background(), &v1.CreateUserReq{Username: "john", Password: "secret-password"},
+		&grpc.UnaryServerInfo{FullMethod: "/test"}, handler)
+	if !called || err != nil {
+		t.Errorf("got called=%v, err=%v, want the valid request handled", called, err)
+	}
+}
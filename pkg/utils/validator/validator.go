@@ -12,12 +12,14 @@ type Validator struct {
 	v *validate.Validate
 }
 
-// New return new instance of validator with the given tag.
+// New return new instance of validator with the given tag,
+// along with the domain validators of the tags phone, postal_code, card_number, card_expiry and username.
 func New(tag string) *Validator {
 	v := validate.New()
 	if tag != "" {
 		v.SetTagName(tag)
 	}
+	registerDomainValidators(v)
 	return &Validator{
 		v: v,
 	}
@@ -0,0 +1,190 @@
+package validator
+
+import (
+	"errors"
+	validate "github.com/go-playground/validator/v10"
+	"github.com/realHoangHai/awesome/pkg/status"
+	"google.golang.org/grpc/codes"
+	"net/mail"
+	"reflect"
+	"strings"
+)
+
+type (
+	// FieldViolation is a violation of the constraints of a field, i.e... the ones of the proto annotations.
+	FieldViolation struct {
+		// Field is the path of the field, i.e... address.postCode.
+		Field       string
+		Description string
+		// Tag is the failed constraint named as the tags of the validator, i.e... min, and Param its
+		// parameter, i.e... 3 for min=3. They are the keys and params of the localized messages.
+		Tag   string
+		Param string
+	}
+
+	// FieldViolations are the violations of the constraints of the fields of a message, returned by the generated
+	// Validate methods. They are converted to a status of codes.InvalidArgument with errdetails.BadRequest.
+	FieldViolations []FieldViolation
+)
+
+// Error implements the error interface.
+func (v FieldViolations) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid request: ")
+	for i, fv := range v {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(fv.Field)
+		b.WriteString(": ")
+		b.WriteString(fv.Description)
+	}
+	return b.String()
+}
+
+// GRPCStatus returns the status of codes.InvalidArgument with the violations as errdetails.BadRequest.
+func (v FieldViolations) GRPCStatus() *status.Status {
+	b := status.Build(codes.InvalidArgument, v.Error())
+	for _, fv := range v {
+		b.FieldViolation(fv.Field, fv.Description)
+	}
+	return b.Status()
+}
+
+// Err returns the violations as an error, nil if there is none.
+func (v FieldViolations) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// Nested returns the violations of the given error of the validation of the field, prefixed by the field,
+// i.e... address.postCode for the violation of postCode of the address field.
+func Nested(field string, err error) FieldViolations {
+	if err == nil {
+		return nil
+	}
+	var v FieldViolations
+	if !errors.As(err, &v) {
+		return FieldViolations{{Field: field, Description: err.Error()}}
+	}
+	nested := make(FieldViolations, 0, len(v))
+	for _, fv := range v {
+		fv.Field = field + "." + fv.Field
+		nested = append(nested, fv)
+	}
+	return nested
+}
+
+// IsEmail reports whether the given string is an email address without display name, i.e... john@example.com.
+func IsEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// Convert returns the failures of the validator of the given value as FieldViolations, converted to
+// a status of codes.InvalidArgument with errdetails.BadRequest. The fields are the paths of their json names,
+// i.e... addresses[0].post_code for the field PostCode of the first item of the field Addresses,
+// the Go names if they have none or if one of them is ignored by json.
+// The other errors are returned as is.
+func Convert(v interface{}, err error) error {
+	var ve validate.ValidationErrors
+	if !errors.As(err, &ve) {
+		return err
+	}
+	violations := make(FieldViolations, 0, len(ve))
+	for _, fe := range ve {
+		field := fe.StructNamespace()
+		// the namespace starts with the name of the validated struct.
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		if name, ok := jsonPath(reflect.TypeOf(v), field); ok {
+			field = name
+		}
+		violations = append(violations, FieldViolation{Field: field, Description: description(fe), Tag: fe.Tag(), Param: fe.Param()})
+	}
+	return violations
+}
+
+// jsonPath returns the path of the json names of the fields of the given namespace of the struct type t,
+// keeping the indexes of the items, i.e... addresses[0].post_code for Addresses[0].PostCode.
+// It returns false if a field is not found or is ignored by json, i.e... tagged json:"-".
+func jsonPath(t reflect.Type, namespace string) (string, bool) {
+	names := strings.Split(namespace, ".")
+	for i, name := range names {
+		index := ""
+		if j := strings.IndexByte(name, '['); j >= 0 {
+			name, index = name[:j], name[j:]
+		}
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			return "", false
+		}
+		f, ok := t.FieldByName(name)
+		if !ok {
+			return "", false
+		}
+		switch tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag {
+		case "-":
+			return "", false
+		case "":
+			names[i] = name + index
+		default:
+			names[i] = tag + index
+		}
+		// the items of the slices, arrays and maps of the indexes.
+		t = f.Type
+		for n := strings.Count(index, "["); n > 0; n-- {
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				t = t.Elem()
+			default:
+				return "", false
+			}
+		}
+	}
+	return strings.Join(names, "."), true
+}
+
+// description returns the description of the failure of a tag, i.e... must be at least 3 for min=3.
+func description(fe validate.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min", "gte":
+		return "must be at least " + fe.Param()
+	case "max", "lte":
+		return "must be at most " + fe.Param()
+	case "len":
+		return "must be of length " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "lt":
+		return "must be less than " + fe.Param()
+	case "oneof":
+		return "must be one of [" + strings.Join(strings.Fields(fe.Param()), ", ") + "]"
+	case PhoneTag:
+		return "must be a phone number in the E.164 format, i.e... +84901234567"
+	case PostalCodeTag:
+		return "must be a valid postal code"
+	case CardNumberTag:
+		return "must be a valid card number"
+	case CardExpiryTag:
+		return "must be a card expiry date in the MM/YY format, not in the past"
+	case UsernameTag:
+		return "must be 3 to 32 letters, digits or single ., _, - starting with a letter and not ending with a separator"
+	}
+	if fe.Param() != "" {
+		return "failed on the '" + fe.Tag() + "=" + fe.Param() + "' validation"
+	}
+	return "failed on the '" + fe.Tag() + "' validation"
+}